@@ -53,7 +53,7 @@ func (m *MetricsMiddleware) LogAPICall() gin.HandlerFunc {
 
 func (m *MetricsMiddleware) logToDatabase(method, endpoint string, statusCode int, responseTime int64, apiKey, userAgent string, isError bool) {
 	_, err := m.DB.Exec(`
-		INSERT INTO api_usage_logs 
+		INSERT INTO api_usage_logs
 		(service_name, endpoint, method, status_code, response_time_ms, api_key, user_agent, is_error, created_at)
 		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`, m.ServiceName, endpoint, method, statusCode, responseTime, apiKey, userAgent, isError, time.Now())
@@ -74,6 +74,7 @@ func main() {
 		{
 			vectors.GET("/stats", h.GetVectorStats)
 			vectors.POST("/search", h.SearchVectors)
+			vectors.GET("/:id/similar", h.GetSimilarByID)
 			vectors.POST("", h.StoreVector)
 			vectors.POST("/batch", h.BatchStoreVectors)
 		}
@@ -0,0 +1,493 @@
+// Package index is an in-memory Hierarchical Navigable Small World (HNSW)
+// approximate nearest-neighbor graph, used by storage.VectorIndex for
+// corpora too large for storage.SearchSimilarVectors' brute-force O(N)
+// scan to stay fast. Index is an interface so a managed vector database can be
+// dropped in later without touching callers.
+package index
+
+import (
+	"context"
+	"encoding/gob"
+	"errors"
+	"io"
+	"math"
+	"math/rand"
+	"sort"
+	"sync"
+	"sync/atomic"
+)
+
+// Result is one hit from a Search call.
+type Result struct {
+	ID         string
+	Similarity float64
+	Distance   float64
+}
+
+// Index is implemented by the in-memory HNSW graph (HNSW) and can be
+// implemented by a client wrapping an external vector database.
+type Index interface {
+	Add(id string, vec []float64) error
+	Search(ctx context.Context, query []float64, k, ef int) ([]Result, error)
+	Len() int
+	Save(w io.Writer) error
+	Load(r io.Reader) error
+}
+
+// Config tunes graph construction and query recall.
+type Config struct {
+	M              int // max bidirectional links per node per layer (layer 0 uses 2*M)
+	EfConstruction int // beam width used while inserting
+	ExactBelow     int // corpora smaller than this fall back to brute-force exact search
+}
+
+// DefaultConfig matches the parameters most HNSW papers/implementations use.
+func DefaultConfig() Config {
+	return Config{M: 16, EfConstruction: 200, ExactBelow: 1000}
+}
+
+// node is one graph vertex. neighbors is swapped atomically on every write so
+// concurrent Search calls never lock — they just read whatever slice was
+// current when they dereferenced it (RCU-style).
+type node struct {
+	id        string
+	vector    []float64
+	norm      float64
+	level     int
+	neighbors []atomic.Pointer[[]string] // one slot per layer, 0..level
+	mu        sync.Mutex                 // serializes writers touching this node's neighbor lists
+}
+
+// HNSW is a concurrency-safe, in-memory HNSW index.
+type HNSW struct {
+	cfg Config
+
+	mu         sync.RWMutex // guards nodes map growth and entryPoint/maxLevel
+	nodes      map[string]*node
+	entryPoint string
+	maxLevel   int
+	mL         float64
+	rng        *rand.Rand
+	rngMu      sync.Mutex
+}
+
+// New builds an empty HNSW index.
+func New(cfg Config) *HNSW {
+	if cfg.M <= 0 {
+		cfg = DefaultConfig()
+	}
+	return &HNSW{
+		cfg:   cfg,
+		nodes: make(map[string]*node),
+		mL:    1.0 / math.Log(float64(cfg.M)),
+		rng:   rand.New(rand.NewSource(1)),
+	}
+}
+
+func (h *HNSW) mMax(layer int) int {
+	if layer == 0 {
+		return 2 * h.cfg.M
+	}
+	return h.cfg.M
+}
+
+func cosineDistance(a, b []float64, normA, normB float64) float64 {
+	if normA == 0 || normB == 0 {
+		return 1
+	}
+	var dot float64
+	for i := range a {
+		dot += a[i] * b[i]
+	}
+	return 1 - dot/(normA*normB)
+}
+
+func norm(v []float64) float64 {
+	var sum float64
+	for _, x := range v {
+		sum += x * x
+	}
+	return math.Sqrt(sum)
+}
+
+func (h *HNSW) randomLevel() int {
+	h.rngMu.Lock()
+	u := h.rng.Float64()
+	h.rngMu.Unlock()
+	if u <= 0 {
+		u = 1e-12
+	}
+	return int(math.Floor(-math.Log(u) * h.mL))
+}
+
+func (h *HNSW) getNeighbors(n *node, layer int) []string {
+	if layer >= len(n.neighbors) {
+		return nil
+	}
+	p := n.neighbors[layer].Load()
+	if p == nil {
+		return nil
+	}
+	return *p
+}
+
+func (h *HNSW) setNeighbors(n *node, layer int, ids []string) {
+	cp := make([]string, len(ids))
+	copy(cp, ids)
+	n.neighbors[layer].Store(&cp)
+}
+
+// Add inserts id/vec into the graph. Safe for concurrent use with Search and
+// other Adds.
+func (h *HNSW) Add(id string, vec []float64) error {
+	if len(vec) == 0 {
+		return errors.New("index: empty vector")
+	}
+
+	level := h.randomLevel()
+	n := &node{
+		id:        id,
+		vector:    append([]float64(nil), vec...),
+		norm:      norm(vec),
+		level:     level,
+		neighbors: make([]atomic.Pointer[[]string], level+1),
+	}
+
+	h.mu.Lock()
+	if len(h.nodes) == 0 {
+		h.nodes[id] = n
+		h.entryPoint = id
+		h.maxLevel = level
+		h.mu.Unlock()
+		return nil
+	}
+	entry := h.entryPoint
+	maxLevel := h.maxLevel
+	h.nodes[id] = n
+	if level > maxLevel {
+		h.maxLevel = level
+		h.entryPoint = id
+	}
+	h.mu.Unlock()
+
+	cur := entry
+
+	// Greedily descend from the top layer to level+1 using 1-NN at each layer.
+	for layer := maxLevel; layer > level; layer-- {
+		cur = h.greedyDescend(cur, n.vector, n.norm, layer)
+	}
+
+	// At level..0, beam search for efConstruction candidates, then connect.
+	for layer := min(level, maxLevel); layer >= 0; layer-- {
+		candidates := h.searchLayer(cur, n.vector, n.norm, h.cfg.EfConstruction, layer)
+		selected := h.selectNeighborsHeuristic(n, candidates, h.mMax(layer))
+
+		h.connect(n, layer, selected)
+		if len(selected) > 0 {
+			cur = selected[0].id
+		}
+	}
+
+	return nil
+}
+
+type candidate struct {
+	id       string
+	distance float64
+}
+
+func (h *HNSW) greedyDescend(from string, vec []float64, vnorm float64, layer int) string {
+	best := from
+	bestDist := h.distanceTo(from, vec, vnorm)
+
+	for {
+		improved := false
+		n := h.load(best)
+		if n == nil {
+			return best
+		}
+		for _, nb := range h.getNeighbors(n, layer) {
+			d := h.distanceTo(nb, vec, vnorm)
+			if d < bestDist {
+				bestDist = d
+				best = nb
+				improved = true
+			}
+		}
+		if !improved {
+			return best
+		}
+	}
+}
+
+// searchLayer runs a beam search of the given width at layer, starting from
+// entry, and returns candidates sorted by ascending distance.
+func (h *HNSW) searchLayer(entry string, vec []float64, vnorm float64, ef int, layer int) []candidate {
+	visited := map[string]bool{entry: true}
+	entryDist := h.distanceTo(entry, vec, vnorm)
+
+	candidates := []candidate{{entry, entryDist}}
+	results := []candidate{{entry, entryDist}}
+
+	for len(candidates) > 0 {
+		sort.Slice(candidates, func(i, j int) bool { return candidates[i].distance < candidates[j].distance })
+		c := candidates[0]
+		candidates = candidates[1:]
+
+		sort.Slice(results, func(i, j int) bool { return results[i].distance < results[j].distance })
+		if len(results) >= ef && c.distance > results[len(results)-1].distance {
+			break
+		}
+
+		n := h.load(c.id)
+		if n == nil {
+			continue
+		}
+		for _, nbID := range h.getNeighbors(n, layer) {
+			if visited[nbID] {
+				continue
+			}
+			visited[nbID] = true
+			d := h.distanceTo(nbID, vec, vnorm)
+
+			sort.Slice(results, func(i, j int) bool { return results[i].distance < results[j].distance })
+			if len(results) < ef || d < results[len(results)-1].distance {
+				candidates = append(candidates, candidate{nbID, d})
+				results = append(results, candidate{nbID, d})
+				if len(results) > ef {
+					results = results[:ef]
+				}
+			}
+		}
+	}
+
+	sort.Slice(results, func(i, j int) bool { return results[i].distance < results[j].distance })
+	return results
+}
+
+// selectNeighborsHeuristic keeps a candidate only if it is closer to the new
+// node than it is to any neighbor already selected, which diversifies the
+// resulting connections instead of clustering them all in one direction.
+func (h *HNSW) selectNeighborsHeuristic(n *node, candidates []candidate, mMax int) []candidate {
+	selected := make([]candidate, 0, mMax)
+
+	for _, c := range candidates {
+		if len(selected) >= mMax {
+			break
+		}
+		cNode := h.load(c.id)
+		if cNode == nil {
+			continue
+		}
+
+		good := true
+		for _, s := range selected {
+			sNode := h.load(s.id)
+			if sNode == nil {
+				continue
+			}
+			if cosineDistance(cNode.vector, sNode.vector, cNode.norm, sNode.norm) < c.distance {
+				good = false
+				break
+			}
+		}
+		if good {
+			selected = append(selected, c)
+		}
+	}
+
+	return selected
+}
+
+// connect adds bidirectional edges between n and each selected candidate at
+// layer, pruning the far end back down to its mMax if needed. Each node's
+// neighbor slice is only ever mutated while holding that node's own lock.
+func (h *HNSW) connect(n *node, layer int, selected []candidate) {
+	ids := make([]string, len(selected))
+	for i, c := range selected {
+		ids[i] = c.id
+	}
+
+	n.mu.Lock()
+	h.setNeighbors(n, layer, ids)
+	n.mu.Unlock()
+
+	for _, c := range selected {
+		other := h.load(c.id)
+		if other == nil || layer >= len(other.neighbors) {
+			continue
+		}
+
+		other.mu.Lock()
+		existing := h.getNeighbors(other, layer)
+		updated := append(append([]string(nil), existing...), n.id)
+
+		if len(updated) > h.mMax(layer) {
+			cands := make([]candidate, 0, len(updated))
+			for _, id := range updated {
+				o := h.load(id)
+				if o == nil {
+					continue
+				}
+				cands = append(cands, candidate{id, cosineDistance(other.vector, o.vector, other.norm, o.norm)})
+			}
+			sort.Slice(cands, func(i, j int) bool { return cands[i].distance < cands[j].distance })
+			pruned := h.selectNeighborsHeuristic(other, cands, h.mMax(layer))
+			updated = updated[:0]
+			for _, c := range pruned {
+				updated = append(updated, c.id)
+			}
+		}
+		h.setNeighbors(other, layer, updated)
+		other.mu.Unlock()
+	}
+}
+
+func (h *HNSW) load(id string) *node {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.nodes[id]
+}
+
+func (h *HNSW) distanceTo(id string, vec []float64, vnorm float64) float64 {
+	n := h.load(id)
+	if n == nil {
+		return math.MaxFloat64
+	}
+	return cosineDistance(n.vector, vec, n.norm, vnorm)
+}
+
+// Len returns the number of indexed vectors.
+func (h *HNSW) Len() int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.nodes)
+}
+
+// Search returns the top-k nearest neighbors of query. Corpora smaller than
+// Config.ExactBelow are scanned exactly instead of traversing the graph,
+// since the graph only pays off once it's large enough to skip most nodes.
+func (h *HNSW) Search(ctx context.Context, query []float64, k, ef int) ([]Result, error) {
+	if ef <= 0 {
+		ef = h.cfg.EfConstruction
+	}
+
+	h.mu.RLock()
+	n := len(h.nodes)
+	entry := h.entryPoint
+	maxLevel := h.maxLevel
+	h.mu.RUnlock()
+
+	if n == 0 {
+		return nil, nil
+	}
+
+	qnorm := norm(query)
+
+	if n < h.cfg.ExactBelow {
+		return h.exactSearch(query, qnorm, k), nil
+	}
+
+	cur := entry
+	for layer := maxLevel; layer > 0; layer-- {
+		cur = h.greedyDescend(cur, query, qnorm, layer)
+	}
+
+	candidates := h.searchLayer(cur, query, qnorm, ef, 0)
+	if len(candidates) > k {
+		candidates = candidates[:k]
+	}
+
+	results := make([]Result, len(candidates))
+	for i, c := range candidates {
+		results[i] = Result{ID: c.id, Distance: c.distance, Similarity: 1 - c.distance}
+	}
+	return results, nil
+}
+
+func (h *HNSW) exactSearch(query []float64, qnorm float64, k int) []Result {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	results := make([]Result, 0, len(h.nodes))
+	for _, n := range h.nodes {
+		d := cosineDistance(n.vector, query, n.norm, qnorm)
+		results = append(results, Result{ID: n.id, Distance: d, Similarity: 1 - d})
+	}
+	sort.Slice(results, func(i, j int) bool { return results[i].Similarity > results[j].Similarity })
+	if len(results) > k {
+		results = results[:k]
+	}
+	return results
+}
+
+// persistedNode/persistedGraph are the gob-serializable views of HNSW used
+// by Save/Load (levels, neighbor lists per layer, and the entry point).
+type persistedNode struct {
+	ID        string
+	Vector    []float64
+	Level     int
+	Neighbors [][]string
+}
+
+type persistedGraph struct {
+	Nodes      []persistedNode
+	EntryPoint string
+	MaxLevel   int
+}
+
+// Save serializes the full graph (levels, neighbor lists, entry point).
+func (h *HNSW) Save(w io.Writer) error {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	g := persistedGraph{EntryPoint: h.entryPoint, MaxLevel: h.maxLevel}
+	for _, n := range h.nodes {
+		neighbors := make([][]string, len(n.neighbors))
+		for layer := range n.neighbors {
+			neighbors[layer] = h.getNeighbors(n, layer)
+		}
+		g.Nodes = append(g.Nodes, persistedNode{ID: n.id, Vector: n.vector, Level: n.level, Neighbors: neighbors})
+	}
+
+	return gob.NewEncoder(w).Encode(g)
+}
+
+// Load replaces the graph's contents with a previously Saved one.
+func (h *HNSW) Load(r io.Reader) error {
+	var g persistedGraph
+	if err := gob.NewDecoder(r).Decode(&g); err != nil {
+		return err
+	}
+
+	nodes := make(map[string]*node, len(g.Nodes))
+	for _, pn := range g.Nodes {
+		n := &node{
+			id:        pn.ID,
+			vector:    pn.Vector,
+			norm:      norm(pn.Vector),
+			level:     pn.Level,
+			neighbors: make([]atomic.Pointer[[]string], len(pn.Neighbors)),
+		}
+		for layer, ids := range pn.Neighbors {
+			cp := append([]string(nil), ids...)
+			n.neighbors[layer].Store(&cp)
+		}
+		nodes[pn.ID] = n
+	}
+
+	h.mu.Lock()
+	h.nodes = nodes
+	h.entryPoint = g.EntryPoint
+	h.maxLevel = g.MaxLevel
+	h.mu.Unlock()
+
+	return nil
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
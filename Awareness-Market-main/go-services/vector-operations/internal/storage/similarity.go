@@ -1,8 +1,15 @@
 package storage
 
 import (
+	"container/heap"
+	"context"
+	"io"
 	"math"
+	"sync"
+	"time"
+
 	"vector-operations/internal/models"
+	"vector-operations/internal/storage/index"
 )
 
 // CosineSimilarity calculates cosine similarity between two vectors
@@ -40,40 +47,245 @@ func EuclideanDistance(a, b []float64) float64 {
 	return math.Sqrt(sum)
 }
 
-// SearchSimilarVectors finds the top-k most similar vectors
-func SearchSimilarVectors(query []float64, vectors []models.Vector, topK int, threshold float64) []models.VectorSearchResult {
-	results := make([]models.VectorSearchResult, 0)
+// DotProduct calculates the raw (unnormalized) dot product of two vectors.
+func DotProduct(a, b []float64) float64 {
+	if len(a) != len(b) {
+		return 0
+	}
+	var sum float64
+	for i := range a {
+		sum += a[i] * b[i]
+	}
+	return sum
+}
+
+// AngularDistance converts cosine similarity to a proper distance metric
+// (0 for identical direction, 1 for orthogonal, 2 for opposite), useful
+// when a caller wants to rank by distance rather than similarity.
+func AngularDistance(a, b []float64) float64 {
+	return 1 - CosineSimilarity(a, b)
+}
+
+// Metric selects the scoring function SearchSimilarVectors ranks
+// candidates by.
+type Metric string
+
+const (
+	MetricCosine     Metric = "cosine"
+	MetricEuclidean  Metric = "euclidean"
+	MetricDotProduct Metric = "dotproduct"
+	MetricAngular    Metric = "angular"
+)
+
+// score returns a value where higher is always "more similar", so
+// SearchSimilarVectors can use one min-heap regardless of metric:
+// distance-like metrics (euclidean, angular) are negated.
+func (m Metric) score(query, candidate []float64) float64 {
+	switch m {
+	case MetricEuclidean:
+		return -EuclideanDistance(query, candidate)
+	case MetricDotProduct:
+		return DotProduct(query, candidate)
+	case MetricAngular:
+		return -AngularDistance(query, candidate)
+	default:
+		return CosineSimilarity(query, candidate)
+	}
+}
+
+// candidatesCheckInterval is how often (in vectors scanned) the brute-force
+// scan checks ctx for cancellation/deadline expiry.
+const candidatesCheckInterval = 1024
+
+// SearchOptions configures SearchSimilarVectors beyond the query/topK
+// pair every caller needs. The zero value is a sensible default: no
+// per-call timeout (the caller's ctx still applies), no candidate cap,
+// cosine similarity.
+type SearchOptions struct {
+	// Timeout bounds the scan independently of ctx, if set. Whichever of
+	// ctx or Timeout elapses first wins.
+	Timeout time.Duration
+	// MaxCandidates hard-caps how many vectors are scanned even without a
+	// deadline, so an unbounded corpus can't block a request indefinitely.
+	MaxCandidates int
+	// Metric selects the distance/similarity function. Defaults to cosine.
+	Metric Metric
+}
+
+// resultHeap is a min-heap over scored candidates, ordered so the lowest
+// score is always at the root — the one evicted first once the heap grows
+// past topK.
+type resultHeap struct {
+	results []models.VectorSearchResult
+	scores  []float64
+}
+
+func (h resultHeap) Len() int           { return len(h.results) }
+func (h resultHeap) Less(i, j int) bool { return h.scores[i] < h.scores[j] }
+func (h resultHeap) Swap(i, j int) {
+	h.results[i], h.results[j] = h.results[j], h.results[i]
+	h.scores[i], h.scores[j] = h.scores[j], h.scores[i]
+}
+func (h *resultHeap) Push(x interface{}) {
+	entry := x.(scoredResult)
+	h.results = append(h.results, entry.result)
+	h.scores = append(h.scores, entry.score)
+}
+func (h *resultHeap) Pop() interface{} {
+	n := len(h.results)
+	result, score := h.results[n-1], h.scores[n-1]
+	h.results = h.results[:n-1]
+	h.scores = h.scores[:n-1]
+	return scoredResult{result, score}
+}
+
+type scoredResult struct {
+	result models.VectorSearchResult
+	score  float64
+}
+
+// SearchSimilarVectors finds the top-k most similar vectors to query among
+// vectors, scanning candidates in order and maintaining a bounded min-heap
+// of size topK instead of sorting the full result set (O(N log k) instead
+// of O(N^2) from the old bubble sort).
+//
+// It checks ctx every candidatesCheckInterval candidates and, on
+// cancellation or deadline, returns the best results found so far
+// alongside ctx.Err() so the caller can choose to serve a partial result
+// (e.g. with an X-Partial-Results response header) instead of failing the
+// whole request. opts.MaxCandidates enforces the same cutoff even when
+// ctx never expires.
+func SearchSimilarVectors(ctx context.Context, query []float64, vectors []models.Vector, topK int, threshold float64, opts SearchOptions) ([]models.VectorSearchResult, error) {
+	if opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.Timeout)
+		defer cancel()
+	}
+	metric := opts.Metric
+	if metric == "" {
+		metric = MetricCosine
+	}
 
+	h := &resultHeap{}
+	heap.Init(h)
+
+	scanned := 0
 	for _, vec := range vectors {
+		if opts.MaxCandidates > 0 && scanned >= opts.MaxCandidates {
+			break
+		}
+		scanned++
+
+		if scanned%candidatesCheckInterval == 0 {
+			select {
+			case <-ctx.Done():
+				return sortedResults(h), ctx.Err()
+			default:
+			}
+		}
+
 		if len(vec.Embedding) != len(query) {
 			continue
 		}
 
-		similarity := CosineSimilarity(query, vec.Embedding)
-		distance := EuclideanDistance(query, vec.Embedding)
+		score := metric.score(query, vec.Embedding)
+		if metric == MetricCosine && score < threshold {
+			continue
+		}
 
-		if similarity >= threshold {
-			results = append(results, models.VectorSearchResult{
-				Vector:     vec,
-				Similarity: similarity,
-				Distance:   distance,
-			})
+		result := models.VectorSearchResult{
+			Vector:     vec,
+			Similarity: CosineSimilarity(query, vec.Embedding),
+			Distance:   EuclideanDistance(query, vec.Embedding),
 		}
-	}
 
-	// Sort by similarity (descending)
-	for i := 0; i < len(results)-1; i++ {
-		for j := i + 1; j < len(results); j++ {
-			if results[j].Similarity > results[i].Similarity {
-				results[i], results[j] = results[j], results[i]
-			}
+		if h.Len() < topK {
+			heap.Push(h, scoredResult{result, score})
+		} else if h.Len() > 0 && score > h.scores[0] {
+			heap.Pop(h)
+			heap.Push(h, scoredResult{result, score})
 		}
 	}
 
-	// Return top-k results
-	if len(results) > topK {
-		results = results[:topK]
+	return sortedResults(h), nil
+}
+
+// sortedResults drains h into a slice ordered best-first (descending
+// score), since the heap itself only guarantees the root is the minimum.
+func sortedResults(h *resultHeap) []models.VectorSearchResult {
+	ordered := make([]models.VectorSearchResult, h.Len())
+	for i := len(ordered) - 1; i >= 0; i-- {
+		entry := heap.Pop(h).(scoredResult)
+		ordered[i] = entry.result
 	}
+	return ordered
+}
+
+// VectorIndex wraps an ANN index with the full models.Vector records needed
+// to hydrate index.Result (which only carries an ID) back into
+// models.VectorSearchResult. Callers that hold many thousands of vectors
+// should build one VectorIndex once and call Search repeatedly instead of
+// calling the package-level SearchSimilarVectors, which is O(N) per call.
+type VectorIndex struct {
+	idx index.Index
 
-	return results
+	mu   sync.RWMutex
+	vecs map[string]models.Vector
 }
+
+// NewVectorIndex builds a VectorIndex backed by the in-memory HNSW graph.
+func NewVectorIndex() *VectorIndex {
+	return &VectorIndex{
+		idx:  index.New(index.DefaultConfig()),
+		vecs: make(map[string]models.Vector),
+	}
+}
+
+// Add inserts vec into the index.
+func (vi *VectorIndex) Add(vec models.Vector) error {
+	vi.mu.Lock()
+	vi.vecs[vec.ID] = vec
+	vi.mu.Unlock()
+
+	return vi.idx.Add(vec.ID, vec.Embedding)
+}
+
+// Len returns the number of indexed vectors.
+func (vi *VectorIndex) Len() int {
+	return vi.idx.Len()
+}
+
+// Search finds the top-k most similar vectors using the ANN graph (or an
+// exact scan for small corpora — see index.Config.ExactBelow).
+func (vi *VectorIndex) Search(ctx context.Context, query []float64, topK int, threshold float64) ([]models.VectorSearchResult, error) {
+	hits, err := vi.idx.Search(ctx, query, topK, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	vi.mu.RLock()
+	defer vi.mu.RUnlock()
+
+	results := make([]models.VectorSearchResult, 0, len(hits))
+	for _, hit := range hits {
+		if hit.Similarity < threshold {
+			continue
+		}
+		vec, ok := vi.vecs[hit.ID]
+		if !ok {
+			continue
+		}
+		results = append(results, models.VectorSearchResult{
+			Vector:     vec,
+			Similarity: hit.Similarity,
+			Distance:   hit.Distance,
+		})
+	}
+	return results, nil
+}
+
+// Save/Load persist the underlying graph; the hydration map is rebuilt by
+// the caller re-adding vectors from its own source of truth (e.g. the DB) on
+// startup, same as vector-operations/internal/handlers.Handler does.
+func (vi *VectorIndex) Save(w io.Writer) error { return vi.idx.Save(w) }
+func (vi *VectorIndex) Load(r io.Reader) error { return vi.idx.Load(r) }
@@ -2,51 +2,111 @@ package storage
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"os"
+	"strconv"
 	"time"
 
-	"github.com/aws/aws-sdk-go/aws"
-	"github.com/aws/aws-sdk-go/aws/credentials"
-	"github.com/aws/aws-sdk-go/aws/session"
-	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials/stscreds"
+	"github.com/aws/aws-sdk-go-v2/feature/s3/manager"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
 )
 
-var s3Client *s3.S3
-var bucketName string
+var (
+	s3Client   *s3.Client
+	presigner  *s3.PresignClient
+	bucketName string
+	kmsKeyID   string
+)
+
+// defaultWebIdentityTokenFile is the path the EKS Pod Identity Webhook
+// mounts the projected service account token at when AWS_ROLE_ARN is set
+// without an explicit AWS_WEB_IDENTITY_TOKEN_FILE.
+const defaultWebIdentityTokenFile = "/var/run/secrets/eks.amazonaws.com/serviceaccount/token"
 
-// InitS3 initializes the S3 client
+// InitS3 initializes the S3 client using the standard AWS credential chain
+// (env vars -> shared config/profile -> web identity token file -> EC2/ECS
+// IMDS), so the service works unchanged whether it's run with static
+// credentials locally, an EC2 instance profile, or IRSA on EKS. Only
+// AWS_REGION and S3_BUCKET_NAME are required; everything else is optional.
 func InitS3() error {
-	accessKey := os.Getenv("AWS_ACCESS_KEY_ID")
-	secretKey := os.Getenv("AWS_SECRET_ACCESS_KEY")
 	region := os.Getenv("AWS_REGION")
 	bucketName = os.Getenv("S3_BUCKET_NAME")
-
-	if accessKey == "" || secretKey == "" || region == "" || bucketName == "" {
+	if region == "" || bucketName == "" {
 		return fmt.Errorf("missing S3 configuration environment variables")
 	}
+	kmsKeyID = os.Getenv("S3_KMS_KEY_ID")
 
-	sess, err := session.NewSession(&aws.Config{
-		Region:      aws.String(region),
-		Credentials: credentials.NewStaticCredentials(accessKey, secretKey, ""),
-	})
+	ctx := context.Background()
+	opts := []func(*config.LoadOptions) error{config.WithRegion(region)}
+
+	// AWS_ROLE_ARN + AWS_WEB_IDENTITY_TOKEN_FILE are already honored by
+	// LoadDefaultConfig's web-identity provider; this only covers IRSA
+	// sidecars that set AWS_ROLE_ARN without the token file env var.
+	if roleARN := os.Getenv("AWS_ROLE_ARN"); roleARN != "" && os.Getenv("AWS_WEB_IDENTITY_TOKEN_FILE") == "" {
+		base, err := config.LoadDefaultConfig(ctx, config.WithRegion(region))
+		if err != nil {
+			return fmt.Errorf("failed to load base AWS config: %w", err)
+		}
+		opts = append(opts, config.WithCredentialsProvider(
+			stscreds.NewWebIdentityRoleProvider(sts.NewFromConfig(base), roleARN, stscreds.IdentityTokenFile(defaultWebIdentityTokenFile)),
+		))
+	}
+
+	cfg, err := config.LoadDefaultConfig(ctx, opts...)
 	if err != nil {
-		return fmt.Errorf("failed to create AWS session: %w", err)
+		return fmt.Errorf("failed to load AWS config: %w", err)
 	}
 
-	s3Client = s3.New(sess)
+	s3Client = s3.NewFromConfig(cfg, func(o *s3.Options) {
+		if endpoint := os.Getenv("S3_ENDPOINT"); endpoint != "" {
+			o.BaseEndpoint = aws.String(endpoint)
+			o.UsePathStyle = true // MinIO/R2 don't support virtual-hosted-style addressing
+		}
+	})
+	presigner = s3.NewPresignClient(s3Client)
+
 	return nil
 }
 
-// UploadFile uploads a file to S3 and returns the file key
+// multipartSizeBytes is the chunk size the v2 uploader switches to
+// multipart upload at, overridable via S3_MULTIPART_SIZE_MB for backends
+// with different optimal part sizes.
+func multipartSizeBytes() int64 {
+	mb := int64(64)
+	if v, err := strconv.ParseInt(os.Getenv("S3_MULTIPART_SIZE_MB"), 10, 64); err == nil && v > 0 {
+		mb = v
+	}
+	return mb * 1024 * 1024
+}
+
+// UploadFile uploads a file to S3 and returns the file key. Server-side
+// encryption defaults to SSE-S3; setting S3_KMS_KEY_ID switches to SSE-KMS
+// with that key.
 func UploadFile(key string, data []byte, contentType string) (string, error) {
-	_, err := s3Client.PutObject(&s3.PutObjectInput{
+	uploader := manager.NewUploader(s3Client, func(u *manager.Uploader) {
+		u.PartSize = multipartSizeBytes()
+	})
+
+	input := &s3.PutObjectInput{
 		Bucket:      aws.String(bucketName),
 		Key:         aws.String(key),
 		Body:        bytes.NewReader(data),
 		ContentType: aws.String(contentType),
-	})
-	if err != nil {
+	}
+	if kmsKeyID != "" {
+		input.ServerSideEncryption = types.ServerSideEncryptionAwsKms
+		input.SSEKMSKeyId = aws.String(kmsKeyID)
+	} else {
+		input.ServerSideEncryption = types.ServerSideEncryptionAes256
+	}
+
+	if _, err := uploader.Upload(context.Background(), input); err != nil {
 		return "", fmt.Errorf("failed to upload file: %w", err)
 	}
 
@@ -55,22 +115,20 @@ func UploadFile(key string, data []byte, contentType string) (string, error) {
 
 // GenerateDownloadURL generates a presigned URL for downloading a file
 func GenerateDownloadURL(key string, expirationMinutes int) (string, error) {
-	req, _ := s3Client.GetObjectRequest(&s3.GetObjectInput{
+	req, err := presigner.PresignGetObject(context.Background(), &s3.GetObjectInput{
 		Bucket: aws.String(bucketName),
 		Key:    aws.String(key),
-	})
-
-	url, err := req.Presign(time.Duration(expirationMinutes) * time.Minute)
+	}, s3.WithPresignExpires(time.Duration(expirationMinutes)*time.Minute))
 	if err != nil {
 		return "", fmt.Errorf("failed to generate presigned URL: %w", err)
 	}
 
-	return url, nil
+	return req.URL, nil
 }
 
 // DeleteFile deletes a file from S3
 func DeleteFile(key string) error {
-	_, err := s3Client.DeleteObject(&s3.DeleteObjectInput{
+	_, err := s3Client.DeleteObject(context.Background(), &s3.DeleteObjectInput{
 		Bucket: aws.String(bucketName),
 		Key:    aws.String(key),
 	})
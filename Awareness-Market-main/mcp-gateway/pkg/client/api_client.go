@@ -16,6 +16,18 @@ type AwarenessAPIClient struct {
 	httpClient *http.Client
 }
 
+// StatusError is returned when the API responds with an unexpected HTTP
+// status, so callers (e.g. GetWithOptions' retry logic) can distinguish a
+// 5xx worth retrying from a 4xx that won't succeed on a second attempt.
+type StatusError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("API error: status=%d, body=%s", e.StatusCode, e.Body)
+}
+
 // NewAwarenessAPIClient creates a new API client
 func NewAwarenessAPIClient(baseURL string) *AwarenessAPIClient {
 	return &AwarenessAPIClient{
@@ -43,7 +55,7 @@ func (c *AwarenessAPIClient) Get(ctx context.Context, path string, result interf
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("API error: status=%d, body=%s", resp.StatusCode, string(body))
+		return &StatusError{StatusCode: resp.StatusCode, Body: string(body)}
 	}
 
 	if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
@@ -75,7 +87,7 @@ func (c *AwarenessAPIClient) Post(ctx context.Context, path string, body interfa
 
 	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
 		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("API error: status=%d, body=%s", resp.StatusCode, string(body))
+		return &StatusError{StatusCode: resp.StatusCode, Body: string(body)}
 	}
 
 	if result != nil {
@@ -0,0 +1,163 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"net"
+	"sync"
+	"time"
+)
+
+// RetryPolicy controls how GetWithOptions retries a failed call.
+type RetryPolicy struct {
+	MaxAttempts int           // total attempts including the first; 1 disables retries
+	BaseDelay   time.Duration // delay before the first retry
+	MaxDelay    time.Duration // cap for the exponential backoff
+}
+
+// DefaultRetryPolicy retries transient upstream failures a couple of times
+// with jittered exponential backoff.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{MaxAttempts: 3, BaseDelay: 100 * time.Millisecond, MaxDelay: 2 * time.Second}
+}
+
+func (p RetryPolicy) delay(attempt int) time.Duration {
+	d := p.BaseDelay << attempt
+	if d > p.MaxDelay || d <= 0 {
+		d = p.MaxDelay
+	}
+	// Full jitter: spreads out retries from concurrent callers instead of
+	// having them all wake up and hammer the upstream at once.
+	return time.Duration(rand.Int63n(int64(d) + 1))
+}
+
+// RequestOptions configures a single GetWithOptions call.
+type RequestOptions struct {
+	Timeout time.Duration // per-call deadline; 0 means use the client's default
+	Retry   RetryPolicy
+	Breaker *CircuitBreaker // optional; shared across calls to the same endpoint prefix
+}
+
+// DefaultRequestOptions is a sensible default for callers that don't need to
+// tune anything: a 5s per-call timeout and the default retry policy.
+func DefaultRequestOptions() RequestOptions {
+	return RequestOptions{Timeout: 5 * time.Second, Retry: DefaultRetryPolicy()}
+}
+
+// CircuitBreaker trips after a run of consecutive failures and rejects calls
+// for a cooldown period rather than letting them queue up behind a dead
+// upstream. Safe for concurrent use; share one instance per endpoint prefix.
+type CircuitBreaker struct {
+	failureThreshold int
+	cooldown         time.Duration
+
+	mu       sync.Mutex
+	failures int
+	openedAt time.Time
+	open     bool
+}
+
+// NewCircuitBreaker trips after failureThreshold consecutive failures and
+// stays open for cooldown before allowing a trial request through.
+func NewCircuitBreaker(failureThreshold int, cooldown time.Duration) *CircuitBreaker {
+	return &CircuitBreaker{failureThreshold: failureThreshold, cooldown: cooldown}
+}
+
+var errCircuitOpen = errors.New("circuit breaker open")
+
+func (b *CircuitBreaker) allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if !b.open {
+		return nil
+	}
+	if time.Since(b.openedAt) >= b.cooldown {
+		// Half-open: let one trial request through; recordResult decides
+		// whether to close the breaker or keep it open.
+		b.openedAt = time.Now()
+		return nil
+	}
+	return errCircuitOpen
+}
+
+func (b *CircuitBreaker) recordResult(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if err == nil {
+		b.failures = 0
+		b.open = false
+		return
+	}
+
+	b.failures++
+	if b.failures >= b.failureThreshold {
+		b.open = true
+		b.openedAt = time.Now()
+	}
+}
+
+// isRetryable reports whether err looks like a transient network or 5xx
+// failure worth retrying, as opposed to a 4xx client error or decode failure.
+func isRetryable(err error) bool {
+	if err == nil {
+		return false
+	}
+	var apiErr *StatusError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode >= 500
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+// GetWithOptions performs a GET with a per-call deadline, retry policy, and
+// optional circuit breaker, instead of only inheriting ctx's deadline.
+func (c *AwarenessAPIClient) GetWithOptions(ctx context.Context, path string, result interface{}, opts RequestOptions) error {
+	if opts.Retry.MaxAttempts <= 0 {
+		opts.Retry = DefaultRetryPolicy()
+	}
+
+	var lastErr error
+	for attempt := 0; attempt < opts.Retry.MaxAttempts; attempt++ {
+		if opts.Breaker != nil {
+			if err := opts.Breaker.allow(); err != nil {
+				return err
+			}
+		}
+
+		callCtx := ctx
+		var cancel context.CancelFunc
+		if opts.Timeout > 0 {
+			callCtx, cancel = context.WithTimeout(ctx, opts.Timeout)
+		}
+
+		err := c.Get(callCtx, path, result)
+		if cancel != nil {
+			cancel()
+		}
+
+		if opts.Breaker != nil {
+			opts.Breaker.recordResult(err)
+		}
+
+		if err == nil {
+			return nil
+		}
+		lastErr = err
+
+		if !isRetryable(err) || attempt == opts.Retry.MaxAttempts-1 {
+			break
+		}
+
+		select {
+		case <-time.After(opts.Retry.delay(attempt)):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	return lastErr
+}
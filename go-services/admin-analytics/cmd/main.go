@@ -0,0 +1,102 @@
+package main
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/awareness-market/admin-analytics/internal/config"
+	"github.com/awareness-market/admin-analytics/internal/handlers"
+	"github.com/awareness-market/admin-analytics/internal/middleware"
+	"github.com/gin-gonic/gin"
+	swaggerFiles "github.com/swaggo/files"
+	ginSwagger "github.com/swaggo/gin-swagger"
+
+	_ "github.com/awareness-market/admin-analytics/docs"
+)
+
+// healthPollInterval controls how often the ServiceHealth registry backing
+// GET /api/v1/health/services and the service_up metric is refreshed.
+const healthPollInterval = 30 * time.Second
+
+// @title Admin Analytics API
+// @version 1.0
+// @description Admin analytics and monitoring service for Awareness Market
+// @host localhost:8082
+// @BasePath /
+func main() {
+	// Load configuration
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatal("Failed to load configuration:", err)
+	}
+	defer cfg.DB.Close()
+
+	// Initialize Gin router
+	router := gin.Default()
+
+	// CORS middleware
+	router.Use(func(c *gin.Context) {
+		c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
+		c.Writer.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
+		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+		if c.Request.Method == "OPTIONS" {
+			c.AbortWithStatus(204)
+			return
+		}
+		c.Next()
+	})
+
+	// Initialize handlers
+	h := handlers.NewHandler(cfg.DB)
+
+	go h.Health.Run(context.Background(), healthPollInterval)
+
+	// Routes
+	v1 := router.Group("/api/v1")
+	v1.Use(middleware.RateLimit(cfg.DB))
+	{
+		// Analytics endpoints
+		analytics := v1.Group("/analytics")
+		{
+			analytics.GET("/stats", h.GetAPIUsageStats)
+			analytics.GET("/timeline", h.GetUsageTimeline)
+			analytics.GET("/top-users", h.GetTopUsers)
+			analytics.GET("/query", h.QueryInstant)
+			analytics.GET("/query_range", h.QueryRange)
+			analytics.GET("/rate-limits", h.GetNearQuotaRateLimits)
+		}
+
+		// API Keys management
+		v1.GET("/api-keys", h.GetAllAPIKeys)
+		v1.GET("/api-keys/:id/rate-limit", h.GetRateLimitConfig)
+		v1.PUT("/api-keys/:id/rate-limit", h.PutRateLimitConfig)
+		v1.DELETE("/api-keys/:id/rate-limit", h.DeleteRateLimitConfig)
+
+		// Health endpoints
+		health := v1.Group("/health")
+		{
+			health.GET("/services", h.GetServiceHealth)
+			health.GET("/services/:name/history", h.GetServiceHealthHistory)
+		}
+	}
+
+	// Prometheus-compatible scrape endpoint: api_requests_total,
+	// api_request_duration_seconds, api_key_requests_total, service_up.
+	router.GET("/metrics", h.Metrics)
+
+	// Swagger documentation
+	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
+
+	// Health check
+	router.GET("/health", func(c *gin.Context) {
+		c.JSON(200, gin.H{"status": "healthy", "service": "admin-analytics"})
+	})
+
+	log.Printf("🚀 Admin Analytics Service starting on port %s", cfg.Port)
+	log.Printf("📚 Swagger UI available at http://localhost:%s/swagger/index.html", cfg.Port)
+
+	if err := router.Run(":" + cfg.Port); err != nil {
+		log.Fatal("Failed to start server:", err)
+	}
+}
@@ -0,0 +1,554 @@
+package handlers
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/awareness-market/admin-analytics/internal/metrics"
+	"github.com/awareness-market/admin-analytics/internal/middleware"
+	"github.com/awareness-market/admin-analytics/internal/models"
+	"github.com/awareness-market/admin-analytics/internal/promql"
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+type Handler struct {
+	DB     *sql.DB
+	Health *metrics.HealthRegistry
+	Query  *promql.Engine
+}
+
+// NewHandler also registers this service's Prometheus collector against the
+// default registry, so GET /metrics (wired to Handler.Metrics) reflects it
+// without any separate registration step in cmd/main.go.
+func NewHandler(db *sql.DB) *Handler {
+	health := metrics.NewHealthRegistry()
+	prometheus.MustRegister(metrics.NewCollector(db, health))
+	return &Handler{
+		DB:     db,
+		Health: health,
+		Query:  promql.NewEngine(db, healthSnapshotter{health}),
+	}
+}
+
+// healthSnapshotter adapts *metrics.HealthRegistry to promql.HealthSnapshotter
+// so the promql package doesn't need to import metrics.
+type healthSnapshotter struct{ *metrics.HealthRegistry }
+
+func (h healthSnapshotter) Snapshot() []promql.HealthService {
+	services := h.HealthRegistry.Snapshot()
+	out := make([]promql.HealthService, len(services))
+	for i, s := range services {
+		out[i] = promql.HealthService{ServiceName: s.ServiceName, Status: s.Status}
+	}
+	return out
+}
+
+// GetAPIUsageStats godoc
+// @Summary Get API usage statistics
+// @Description Get aggregated API usage statistics for admin dashboard
+// @Tags Analytics
+// @Produce json
+// @Success 200 {object} models.APIUsageStats
+// @Failure 500 {object} map[string]string
+// @Router /api/v1/analytics/stats [get]
+func (h *Handler) GetAPIUsageStats(c *gin.Context) {
+	var stats models.APIUsageStats
+
+	// Return mock data if DB is not available
+	if h.DB == nil {
+		stats = models.APIUsageStats{
+			TotalRequests:   15234,
+			TotalUsers:      127,
+			AvgResponseTime: 145.6,
+			ErrorRate:       2.3,
+			RequestsToday:   892,
+		}
+		c.JSON(http.StatusOK, stats)
+		return
+	}
+
+	// Total requests
+	err := h.DB.QueryRow(`
+		SELECT COUNT(*) FROM api_usage_logs
+	`).Scan(&stats.TotalRequests)
+	if err != nil && err != sql.ErrNoRows {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Total distinct API keys
+	err = h.DB.QueryRow(`
+		SELECT COUNT(DISTINCT api_key) FROM api_usage_logs WHERE api_key IS NOT NULL AND api_key != ''
+	`).Scan(&stats.TotalUsers)
+	if err != nil && err != sql.ErrNoRows {
+		stats.TotalUsers = 0
+	}
+
+	// Average response time
+	err = h.DB.QueryRow(`
+		SELECT AVG(response_time_ms) FROM api_usage_logs WHERE response_time_ms IS NOT NULL
+	`).Scan(&stats.AvgResponseTime)
+	if err != nil && err != sql.ErrNoRows {
+		stats.AvgResponseTime = 0
+	}
+
+	// Error rate
+	var totalReqs, errorReqs int64
+	h.DB.QueryRow(`SELECT COUNT(*) FROM api_usage_logs`).Scan(&totalReqs)
+	h.DB.QueryRow(`SELECT COUNT(*) FROM api_usage_logs WHERE status_code >= 400`).Scan(&errorReqs)
+	if totalReqs > 0 {
+		stats.ErrorRate = float64(errorReqs) / float64(totalReqs) * 100
+	}
+
+	// Requests today
+	err = h.DB.QueryRow(`
+		SELECT COUNT(*) FROM api_usage_logs
+		WHERE DATE(created_at) = CURDATE()
+	`).Scan(&stats.RequestsToday)
+	if err != nil && err != sql.ErrNoRows {
+		stats.RequestsToday = 0
+	}
+
+	c.JSON(http.StatusOK, stats)
+}
+
+// GetUsageTimeline godoc
+// @Summary Get usage timeline
+// @Description Get daily API usage timeline for the past N days, as a thin wrapper over sum(api_requests_total) evaluated once per day
+// @Tags Analytics
+// @Produce json
+// @Param days query int false "Number of days" default(30)
+// @Success 200 {array} models.UsageTimelinePoint
+// @Failure 500 {object} map[string]string
+// @Router /api/v1/analytics/timeline [get]
+func (h *Handler) GetUsageTimeline(c *gin.Context) {
+	days, err := strconv.Atoi(c.DefaultQuery("days", "30"))
+	if err != nil || days < 1 {
+		days = 30
+	}
+
+	end := time.Now()
+	start := end.AddDate(0, 0, -days)
+
+	series, err := h.Query.Range("sum(api_requests_total)", start, end, 24*time.Hour)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	var requests []promql.Sample
+	if len(series) > 0 {
+		requests = series[0].Points
+	}
+
+	timeline := make([]models.UsageTimelinePoint, len(requests))
+	for i, p := range requests {
+		timeline[i] = models.UsageTimelinePoint{
+			Date:     p.Timestamp.Format("2006-01-02"),
+			Requests: int64(p.Value),
+			Errors:   h.errorsOnDate(p.Timestamp),
+		}
+	}
+
+	c.JSON(http.StatusOK, timeline)
+}
+
+// errorsOnDate counts status>=400 rows for the day ending at ts. The
+// query-engine subset only supports equality label matchers, so an
+// inequality like "status>=400" can't be expressed as a promql expression
+// yet; this keeps that one comparison as a direct query.
+func (h *Handler) errorsOnDate(ts time.Time) int64 {
+	if h.DB == nil {
+		return 0
+	}
+	var errors int64
+	h.DB.QueryRow(`
+		SELECT COUNT(*) FROM api_usage_logs
+		WHERE created_at > ? AND created_at <= ? AND status_code >= 400
+	`, ts.Add(-24*time.Hour), ts).Scan(&errors)
+	return errors
+}
+
+// GetTopUsers godoc
+// @Summary Get top API keys by usage
+// @Description Get the API keys with the highest request volume over the last 30 days, via topk(limit, api_key_requests_total[720h]). Usage logs identify callers by API key, not user_id, so this ranks keys rather than users.
+// @Tags Analytics
+// @Produce json
+// @Param limit query int false "Number of keys to return" default(10)
+// @Success 200 {array} models.TopAPIKey
+// @Failure 500 {object} map[string]string
+// @Router /api/v1/analytics/top-users [get]
+func (h *Handler) GetTopUsers(c *gin.Context) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if err != nil || limit < 1 {
+		limit = 10
+	}
+
+	expr := "topk(" + strconv.Itoa(limit) + `, api_key_requests_total[720h])`
+	series, err := h.Query.Instant(expr, time.Now())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	keys := make([]models.TopAPIKey, 0, len(series))
+	for _, s := range series {
+		var value float64
+		if len(s.Points) > 0 {
+			value = s.Points[len(s.Points)-1].Value
+		}
+		keys = append(keys, models.TopAPIKey{KeyPrefix: s.Labels["key_prefix"], RequestCount: value})
+	}
+
+	c.JSON(http.StatusOK, keys)
+}
+
+// GetAllAPIKeys godoc
+// @Summary Get all API keys
+// @Description Get list of all API keys with usage information (admin only)
+// @Tags API Keys
+// @Produce json
+// @Success 200 {array} models.APIKeyInfo
+// @Failure 500 {object} map[string]string
+// @Router /api/v1/api-keys [get]
+func (h *Handler) GetAllAPIKeys(c *gin.Context) {
+	rows, err := h.DB.Query(`
+		SELECT
+			k.id,
+			k.user_id,
+			u.name as user_name,
+			k.name,
+			k.key_prefix,
+			k.last_used_at,
+			k.created_at,
+			k.expires_at,
+			k.is_active,
+			COALESCE(COUNT(l.id), 0) as request_count
+		FROM api_keys k
+		INNER JOIN users u ON k.user_id = u.id
+		LEFT JOIN api_usage_logs l ON k.id = l.api_key_id
+		GROUP BY k.id, k.user_id, u.name, k.name, k.key_prefix, k.last_used_at, k.created_at, k.expires_at, k.is_active
+		ORDER BY k.created_at DESC
+	`)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	defer rows.Close()
+
+	var keys []models.APIKeyInfo
+	for rows.Next() {
+		var key models.APIKeyInfo
+		if err := rows.Scan(
+			&key.ID, &key.UserID, &key.UserName, &key.Name, &key.KeyPrefix,
+			&key.LastUsedAt, &key.CreatedAt, &key.ExpiresAt, &key.IsActive, &key.RequestCount,
+		); err != nil {
+			continue
+		}
+		keys = append(keys, key)
+	}
+
+	c.JSON(http.StatusOK, keys)
+}
+
+// GetServiceHealth godoc
+// @Summary Get microservices health status
+// @Description Get health status of all microservices, as last observed by the background ServiceHealth polling loop
+// @Tags Health
+// @Produce json
+// @Success 200 {array} models.ServiceHealth
+// @Router /api/v1/health/services [get]
+func (h *Handler) GetServiceHealth(c *gin.Context) {
+	polled := h.Health.Snapshot()
+	services := make([]models.ServiceHealth, len(polled))
+	for i, s := range polled {
+		services[i] = models.ServiceHealth{
+			ServiceName:         s.ServiceName,
+			Port:                s.Port,
+			Status:              s.Status,
+			Uptime:              s.Uptime,
+			LastCheck:           s.LastCheck.Format(time.RFC3339),
+			LatencyMs:           s.LatencyMs,
+			ConsecutiveFailures: s.ConsecutiveFailures,
+			CircuitState:        s.CircuitState,
+		}
+	}
+
+	c.JSON(http.StatusOK, services)
+}
+
+// GetServiceHealthHistory godoc
+// @Summary Get a microservice's recent probe history
+// @Description Get the rolling window of recent health-probe results for one service, as tracked by its circuit breaker
+// @Tags Health
+// @Produce json
+// @Param name path string true "Service name, as returned by GET /api/v1/health/services (e.g. \"Memory Exchange\")"
+// @Success 200 {array} models.ServiceProbeResult
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/health/services/{name}/history [get]
+func (h *Handler) GetServiceHealthHistory(c *gin.Context) {
+	name := c.Param("name")
+	history, ok := h.Health.History(name)
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "unknown service: " + name})
+		return
+	}
+
+	results := make([]models.ServiceProbeResult, len(history))
+	for i, p := range history {
+		results[i] = models.ServiceProbeResult{
+			Timestamp: p.Timestamp,
+			Success:   p.Success,
+			LatencyMs: p.LatencyMs,
+			Error:     p.Error,
+		}
+	}
+
+	c.JSON(http.StatusOK, results)
+}
+
+// GetRateLimitConfig godoc
+// @Summary Get an API key's rate limit configuration
+// @Description Get the requests-per-hour/requests-per-day limits enforced for one API key, if any has been configured
+// @Tags API Keys
+// @Produce json
+// @Param id path int true "API key ID"
+// @Success 200 {object} models.RateLimitConfig
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/api-keys/{id}/rate-limit [get]
+func (h *Handler) GetRateLimitConfig(c *gin.Context) {
+	apiKeyID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "id must be an integer"})
+		return
+	}
+
+	var cfg models.RateLimitConfig
+	err = h.DB.QueryRow(`
+		SELECT id, api_key_id, requests_per_hour, requests_per_day, created_at, updated_at
+		FROM rate_limit_configs
+		WHERE api_key_id = ?
+	`, apiKeyID).Scan(&cfg.ID, &cfg.APIKeyID, &cfg.RequestsPerHour, &cfg.RequestsPerDay, &cfg.CreatedAt, &cfg.UpdatedAt)
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "no rate limit configured for this API key"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, cfg)
+}
+
+// rateLimitConfigInput is the request body for PUT
+// /api/v1/api-keys/:id/rate-limit.
+type rateLimitConfigInput struct {
+	RequestsPerHour int `json:"requestsPerHour"`
+	RequestsPerDay  int `json:"requestsPerDay"`
+}
+
+// PutRateLimitConfig godoc
+// @Summary Create or replace an API key's rate limit configuration
+// @Tags API Keys
+// @Accept json
+// @Produce json
+// @Param id path int true "API key ID"
+// @Param body body rateLimitConfigInput true "Limits to apply"
+// @Success 200 {object} models.RateLimitConfig
+// @Failure 400 {object} map[string]string
+// @Router /api/v1/api-keys/{id}/rate-limit [put]
+func (h *Handler) PutRateLimitConfig(c *gin.Context) {
+	apiKeyID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "id must be an integer"})
+		return
+	}
+
+	var input rateLimitConfigInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	_, err = h.DB.Exec(`
+		INSERT INTO rate_limit_configs (api_key_id, requests_per_hour, requests_per_day, created_at, updated_at)
+		VALUES (?, ?, ?, NOW(), NOW())
+		ON DUPLICATE KEY UPDATE requests_per_hour = VALUES(requests_per_hour), requests_per_day = VALUES(requests_per_day), updated_at = NOW()
+	`, apiKeyID, input.RequestsPerHour, input.RequestsPerDay)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	middleware.InvalidateRateLimitConfig(apiKeyID)
+
+	h.GetRateLimitConfig(c)
+}
+
+// DeleteRateLimitConfig godoc
+// @Summary Remove an API key's rate limit configuration, making it unlimited again
+// @Tags API Keys
+// @Param id path int true "API key ID"
+// @Success 204
+// @Router /api/v1/api-keys/{id}/rate-limit [delete]
+func (h *Handler) DeleteRateLimitConfig(c *gin.Context) {
+	apiKeyID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "id must be an integer"})
+		return
+	}
+
+	if _, err := h.DB.Exec(`DELETE FROM rate_limit_configs WHERE api_key_id = ?`, apiKeyID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	middleware.InvalidateRateLimitConfig(apiKeyID)
+
+	c.Status(http.StatusNoContent)
+}
+
+// GetNearQuotaRateLimits godoc
+// @Summary Get API keys approaching their rate limit quota
+// @Description Get every API key (tracked by this process since its last restart) whose current hour or day usage is at or above the given fraction of its configured limit, so operators can spot abuse before it triggers a 429
+// @Tags Analytics
+// @Produce json
+// @Param threshold query number false "Usage fraction (0-1) to report at or above" default(0.8)
+// @Success 200 {array} middleware.NearQuotaKey
+// @Failure 500 {object} map[string]string
+// @Router /api/v1/analytics/rate-limits [get]
+func (h *Handler) GetNearQuotaRateLimits(c *gin.Context) {
+	threshold := 0.8
+	if v, err := strconv.ParseFloat(c.DefaultQuery("threshold", "0.8"), 64); err == nil && v > 0 && v <= 1 {
+		threshold = v
+	}
+
+	keys, err := middleware.NearQuotaKeys(h.DB, threshold)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, keys)
+}
+
+// QueryInstant godoc
+// @Summary Evaluate an analytics expression at a single point in time
+// @Description Evaluate a small PromQL-like expression (sum, rate, avg_over_time, topk over api_requests_total, api_request_duration_seconds, api_key_requests_total, and service_up) and return its current value
+// @Tags Analytics
+// @Produce json
+// @Param query query string true "expression, e.g. sum(api_requests_total{status=\"500\"})"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Router /api/v1/analytics/query [get]
+func (h *Handler) QueryInstant(c *gin.Context) {
+	expr := c.Query("query")
+	if expr == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "query is required"})
+		return
+	}
+
+	at := time.Now()
+	if ts := c.Query("time"); ts != "" {
+		unix, err := strconv.ParseInt(ts, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "time must be a unix timestamp"})
+			return
+		}
+		at = time.Unix(unix, 0)
+	}
+
+	series, err := h.Query.Instant(expr, at)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status": "success",
+		"data":   gin.H{"resultType": "vector", "result": instantResult(series)},
+	})
+}
+
+// QueryRange godoc
+// @Summary Evaluate an analytics expression over a time range
+// @Description Evaluate a small PromQL-like expression at every step between start and end, returning a matrix suitable for a dashboard timeline
+// @Tags Analytics
+// @Produce json
+// @Param query query string true "expression, e.g. rate(api_requests_total[5m])"
+// @Param start query int true "range start, unix timestamp"
+// @Param end query int true "range end, unix timestamp"
+// @Param step query string true "step duration, e.g. 1h"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Router /api/v1/analytics/query_range [get]
+func (h *Handler) QueryRange(c *gin.Context) {
+	expr := c.Query("query")
+	if expr == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "query is required"})
+		return
+	}
+
+	startUnix, err1 := strconv.ParseInt(c.Query("start"), 10, 64)
+	endUnix, err2 := strconv.ParseInt(c.Query("end"), 10, 64)
+	if err1 != nil || err2 != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "start and end must be unix timestamps"})
+		return
+	}
+	step, err := promql.ParseStep(c.Query("step"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	series, err := h.Query.Range(expr, time.Unix(startUnix, 0), time.Unix(endUnix, 0), step)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status": "success",
+		"data":   gin.H{"resultType": "matrix", "result": rangeResult(series)},
+	})
+}
+
+// Metrics exposes every registered Prometheus collector, including the
+// api_requests_total/api_request_duration_seconds/api_key_requests_total/
+// service_up metrics whose collector NewHandler registers, in the standard
+// exposition format.
+func (h *Handler) Metrics(c *gin.Context) {
+	promhttp.Handler().ServeHTTP(c.Writer, c.Request)
+}
+
+func instantResult(series []promql.Series) []gin.H {
+	result := make([]gin.H, len(series))
+	for i, s := range series {
+		var value float64
+		var ts time.Time
+		if len(s.Points) > 0 {
+			value = s.Points[len(s.Points)-1].Value
+			ts = s.Points[len(s.Points)-1].Timestamp
+		}
+		result[i] = gin.H{
+			"metric": s.Labels,
+			"value":  []interface{}{ts.Unix(), strconv.FormatFloat(value, 'f', -1, 64)},
+		}
+	}
+	return result
+}
+
+func rangeResult(series []promql.Series) []gin.H {
+	result := make([]gin.H, len(series))
+	for i, s := range series {
+		values := make([][]interface{}, len(s.Points))
+		for j, p := range s.Points {
+			values[j] = []interface{}{p.Timestamp.Unix(), strconv.FormatFloat(p.Value, 'f', -1, 64)}
+		}
+		result[i] = gin.H{"metric": s.Labels, "values": values}
+	}
+	return result
+}
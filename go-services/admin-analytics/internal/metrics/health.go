@@ -0,0 +1,379 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// knownServices lists every service the HealthRegistry probes: where to
+// reach it and how long a healthy response is expected to take.
+var knownServices = []struct {
+	name            string
+	port            int
+	healthPath      string
+	expectedLatency time.Duration
+}{
+	{"Memory Exchange", 8080, "/health", 200 * time.Millisecond},
+	{"W-Matrix Marketplace", 8081, "/health", 200 * time.Millisecond},
+	{"Admin Analytics", 8082, "/health", 200 * time.Millisecond},
+}
+
+// probeFailureThreshold/probeCooldown tune the per-service circuit breaker
+// the same way the client-side breakers in mcp-gateway/pkg/client and the
+// inbound circuitBreakerPlugin in memory-exchange do: closed -> open after
+// this many consecutive failed probes, half-open (one trial probe allowed
+// through) after probeCooldown.
+const (
+	probeFailureThreshold = 3
+	probeCooldown         = 30 * time.Second
+	probeTimeout          = 2 * time.Second
+)
+
+// maxHistoryPerService bounds the rolling probe-result window History keeps
+// per service.
+const maxHistoryPerService = 100
+
+// Service is one entry in a HealthRegistry snapshot.
+type Service struct {
+	ServiceName string
+	Port        int
+	// Status is derived from the last probe and the circuit breaker:
+	// "running" (last probe succeeded), "error" (last probe failed, fewer
+	// than probeFailureThreshold failures in a row), or "stopped" (circuit
+	// open or half-open).
+	Status              string
+	Uptime              string
+	LastCheck           time.Time
+	LatencyMs           int64
+	ConsecutiveFailures int
+	CircuitState        string // "closed", "open", "half-open"
+}
+
+// ProbeResult is one probe's outcome, kept in a service's rolling History.
+type ProbeResult struct {
+	Timestamp time.Time
+	Success   bool
+	LatencyMs int64
+	Error     string
+}
+
+// StateChangeEvent is emitted on every subscriber channel whenever a
+// service's CircuitState changes, so other handlers (e.g. a future
+// discovery aggregator living in this process) can react immediately
+// instead of waiting for their own next poll of Snapshot().
+type StateChangeEvent struct {
+	ServiceName string
+	From        string
+	To          string
+	At          time.Time
+}
+
+// serviceState is the per-service breaker plus rolling history the
+// registry tracks between probes. Guarded by HealthRegistry.mu.
+type serviceState struct {
+	name            string
+	port            int
+	healthPath      string
+	expectedLatency time.Duration
+
+	status              string
+	circuitState        string
+	consecutiveFailures int
+	latencyMs           int64
+	lastCheck           time.Time
+	openedAt            time.Time
+	firstSuccess        time.Time // zero until the first successful probe; backs real Uptime
+
+	history []ProbeResult
+}
+
+// HealthRegistry actively probes every known service's health endpoint on
+// an interval, tracks a per-service circuit breaker (closed -> open after
+// probeFailureThreshold consecutive failures, half-open after
+// probeCooldown), and keeps a rolling probe history. The /metrics scrape
+// and the /api/v1/health/services handler read a consistent,
+// already-computed Snapshot instead of each re-probing.
+type HealthRegistry struct {
+	mu     sync.RWMutex
+	states map[string]*serviceState
+
+	httpClient *http.Client
+
+	subMu sync.Mutex
+	subs  []chan StateChangeEvent
+}
+
+// NewHealthRegistry seeds the registry with every known service in the
+// "closed" circuit state and "running" status, matching this service's
+// pre-polling-loop behavior until the first probe completes.
+func NewHealthRegistry() *HealthRegistry {
+	r := &HealthRegistry{
+		states:     make(map[string]*serviceState, len(knownServices)),
+		httpClient: &http.Client{Timeout: probeTimeout},
+	}
+	for _, s := range knownServices {
+		r.states[s.name] = &serviceState{
+			name:            s.name,
+			port:            s.port,
+			healthPath:      s.healthPath,
+			expectedLatency: s.expectedLatency,
+			status:          "running",
+			circuitState:    "closed",
+		}
+	}
+	return r
+}
+
+// Snapshot returns the registry's current view. Safe for concurrent use.
+func (r *HealthRegistry) Snapshot() []Service {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	out := make([]Service, 0, len(r.states))
+	for _, s := range r.states {
+		out = append(out, Service{
+			ServiceName:         s.name,
+			Port:                s.port,
+			Status:              s.status,
+			Uptime:              s.uptime(),
+			LastCheck:           s.lastCheck,
+			LatencyMs:           s.latencyMs,
+			ConsecutiveFailures: s.consecutiveFailures,
+			CircuitState:        s.circuitState,
+		})
+	}
+	return out
+}
+
+// History returns serviceName's rolling probe-result window, oldest first,
+// or (nil, false) if serviceName isn't registered.
+func (r *HealthRegistry) History(serviceName string) ([]ProbeResult, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	s, ok := r.states[serviceName]
+	if !ok {
+		return nil, false
+	}
+	out := make([]ProbeResult, len(s.history))
+	copy(out, s.history)
+	return out, true
+}
+
+// Subscribe returns a channel that receives a StateChangeEvent every time
+// any service's circuit state changes. The channel is buffered; a slow
+// consumer drops events rather than blocking the polling loop. Call
+// Unsubscribe with the same channel when done.
+func (r *HealthRegistry) Subscribe() <-chan StateChangeEvent {
+	ch := make(chan StateChangeEvent, 16)
+	r.subMu.Lock()
+	r.subs = append(r.subs, ch)
+	r.subMu.Unlock()
+	return ch
+}
+
+// Unsubscribe stops delivering events to ch and closes it.
+func (r *HealthRegistry) Unsubscribe(ch <-chan StateChangeEvent) {
+	r.subMu.Lock()
+	defer r.subMu.Unlock()
+	for i, c := range r.subs {
+		if c == ch {
+			close(c)
+			r.subs = append(r.subs[:i], r.subs[i+1:]...)
+			return
+		}
+	}
+}
+
+func (r *HealthRegistry) publish(event StateChangeEvent) {
+	r.subMu.Lock()
+	defer r.subMu.Unlock()
+	for _, ch := range r.subs {
+		select {
+		case ch <- event:
+		default:
+		}
+	}
+}
+
+// Run probes every known service once immediately, then again every
+// interval until ctx is canceled. Call it once at startup in its own
+// goroutine.
+func (r *HealthRegistry) Run(ctx context.Context, interval time.Duration) {
+	r.pollOnce(ctx)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			r.pollOnce(ctx)
+		}
+	}
+}
+
+// pollOnce probes every known service concurrently and folds each result
+// into its circuit breaker and history.
+func (r *HealthRegistry) pollOnce(ctx context.Context) {
+	r.mu.RLock()
+	names := make([]string, 0, len(r.states))
+	for name := range r.states {
+		names = append(names, name)
+	}
+	r.mu.RUnlock()
+
+	var wg sync.WaitGroup
+	for _, name := range names {
+		name := name
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			r.probe(ctx, name)
+		}()
+	}
+	wg.Wait()
+}
+
+// probe performs one HTTP GET against serviceName's health endpoint and
+// updates its breaker, status, and history. Probe failures (non-2xx,
+// timeout, connection refused) count the same toward the breaker as each
+// other - the first failure worth distinguishing is "was the circuit
+// already open", which allow() below handles.
+func (r *HealthRegistry) probe(ctx context.Context, serviceName string) {
+	r.mu.Lock()
+	s, ok := r.states[serviceName]
+	if !ok {
+		r.mu.Unlock()
+		return
+	}
+	allowed, halfOpen := s.allow()
+	if halfOpen {
+		s.circuitState = "half-open"
+	}
+	r.mu.Unlock()
+	if halfOpen {
+		r.publish(StateChangeEvent{ServiceName: serviceName, From: "open", To: "half-open", At: time.Now()})
+	}
+
+	if !allowed {
+		r.recordResult(serviceName, false, 0, "circuit open")
+		return
+	}
+
+	start := time.Now()
+	err := doProbe(ctx, s.port, s.healthPath)
+	latency := time.Since(start)
+
+	r.recordResult(serviceName, err == nil, latency.Milliseconds(), errString(err))
+	_ = halfOpen // half-open trial probes are recorded the same as any other; recordResult decides whether the circuit re-closes
+}
+
+func errString(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
+
+// doProbe issues the actual HTTP GET. Split out from probe so it's the only
+// part that needs a live service to exercise.
+func doProbe(ctx context.Context, port int, healthPath string) error {
+	url := fmt.Sprintf("http://localhost:%d%s", port, healthPath)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+
+	client := &http.Client{Timeout: probeTimeout}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// recordResult folds one probe's outcome into serviceName's breaker,
+// status, and history, publishing a StateChangeEvent if the circuit state
+// changed.
+func (r *HealthRegistry) recordResult(serviceName string, success bool, latencyMs int64, errMsg string) {
+	r.mu.Lock()
+	s, ok := r.states[serviceName]
+	if !ok {
+		r.mu.Unlock()
+		return
+	}
+
+	now := time.Now()
+	previousState := s.circuitState
+	s.lastCheck = now
+	s.latencyMs = latencyMs
+
+	if success {
+		s.consecutiveFailures = 0
+		s.circuitState = "closed"
+		s.status = "running"
+		if s.firstSuccess.IsZero() {
+			s.firstSuccess = now
+		}
+	} else {
+		s.consecutiveFailures++
+		if s.consecutiveFailures >= probeFailureThreshold {
+			s.circuitState = "open"
+			s.openedAt = now
+			s.status = "stopped"
+		} else if previousState == "half-open" {
+			// A half-open trial probe failed; reopen for another cooldown.
+			s.circuitState = "open"
+			s.openedAt = now
+			s.status = "stopped"
+		} else {
+			s.status = "error"
+		}
+	}
+
+	s.history = append(s.history, ProbeResult{Timestamp: now, Success: success, LatencyMs: latencyMs, Error: errMsg})
+	if len(s.history) > maxHistoryPerService {
+		s.history = s.history[len(s.history)-maxHistoryPerService:]
+	}
+	newState := s.circuitState
+	r.mu.Unlock()
+
+	if newState != previousState {
+		r.publish(StateChangeEvent{ServiceName: serviceName, From: previousState, To: newState, At: now})
+	}
+}
+
+// allow reports whether a probe should run now, and whether it would be a
+// half-open trial probe (circuit currently open but cooldown elapsed).
+// Callers must hold HealthRegistry.mu.
+func (s *serviceState) allow() (allowed bool, halfOpen bool) {
+	if s.circuitState != "open" {
+		return true, false
+	}
+	if time.Since(s.openedAt) >= probeCooldown {
+		return true, true
+	}
+	return false, false
+}
+
+// uptime reports how long it has been since this service's first
+// successful probe, rounded to the second; later failed probes do not reset
+// it. Empty until the first success.
+func (s *serviceState) uptime() string {
+	if s.firstSuccess.IsZero() {
+		return ""
+	}
+	return time.Since(s.firstSuccess).Round(time.Second).String()
+}
@@ -0,0 +1,161 @@
+// Package metrics exposes admin-analytics' data as Prometheus-compatible
+// counters and gauges, computed on each scrape from api_usage_logs and the
+// service-health registry rather than accumulated in-process, since this
+// service only observes other services' activity after the fact instead of
+// sitting in their request path.
+package metrics
+
+import (
+	"database/sql"
+	"log"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	apiRequestsTotal = prometheus.NewDesc(
+		"api_requests_total",
+		"API requests recorded in api_usage_logs, by status code, endpoint, and API key.",
+		[]string{"status", "endpoint", "user"}, nil,
+	)
+	apiRequestDurationSeconds = prometheus.NewDesc(
+		"api_request_duration_seconds",
+		"Average API response time recorded in api_usage_logs, in seconds, by endpoint.",
+		[]string{"endpoint"}, nil,
+	)
+	apiKeyRequestsTotal = prometheus.NewDesc(
+		"api_key_requests_total",
+		"API requests recorded in api_usage_logs, by API key prefix.",
+		[]string{"key_prefix"}, nil,
+	)
+	serviceUp = prometheus.NewDesc(
+		"service_up",
+		"Whether the ServiceHealth polling loop last observed a service as running (1) or not (0).",
+		[]string{"service"}, nil,
+	)
+)
+
+// Collector implements prometheus.Collector, re-deriving every metric from
+// the database and the health registry on each scrape instead of keeping
+// promauto counters in sync with every request as it happens.
+type Collector struct {
+	DB     *sql.DB
+	Health *HealthRegistry
+}
+
+// NewCollector builds a Collector. db may be nil (mock-data mode, matching
+// config.Load's behavior when MySQL is unreachable), in which case request
+// metrics are simply omitted from the scrape.
+func NewCollector(db *sql.DB, health *HealthRegistry) *Collector {
+	return &Collector{DB: db, Health: health}
+}
+
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- apiRequestsTotal
+	ch <- apiRequestDurationSeconds
+	ch <- apiKeyRequestsTotal
+	ch <- serviceUp
+}
+
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	c.collectServiceUp(ch)
+
+	if c.DB == nil {
+		return
+	}
+	c.collectAPIRequests(ch)
+	c.collectRequestDuration(ch)
+	c.collectAPIKeyRequests(ch)
+}
+
+func (c *Collector) collectServiceUp(ch chan<- prometheus.Metric) {
+	for _, s := range c.Health.Snapshot() {
+		value := 0.0
+		if s.Status == "running" {
+			value = 1.0
+		}
+		ch <- prometheus.MustNewConstMetric(serviceUp, prometheus.GaugeValue, value, s.ServiceName)
+	}
+}
+
+func (c *Collector) collectAPIRequests(ch chan<- prometheus.Metric) {
+	rows, err := c.DB.Query(`
+		SELECT status_code, endpoint, COALESCE(api_key, ''), COUNT(*)
+		FROM api_usage_logs
+		GROUP BY status_code, endpoint, api_key
+	`)
+	if err != nil {
+		log.Printf("admin-analytics: metrics: query api_requests_total: %v", err)
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var status, endpoint, apiKey string
+		var count float64
+		if err := rows.Scan(&status, &endpoint, &apiKey, &count); err != nil {
+			continue
+		}
+		ch <- prometheus.MustNewConstMetric(apiRequestsTotal, prometheus.CounterValue, count, status, endpoint, apiKey)
+	}
+}
+
+func (c *Collector) collectRequestDuration(ch chan<- prometheus.Metric) {
+	rows, err := c.DB.Query(`
+		SELECT endpoint, AVG(response_time_ms), COUNT(*)
+		FROM api_usage_logs
+		WHERE response_time_ms IS NOT NULL
+		GROUP BY endpoint
+	`)
+	if err != nil {
+		log.Printf("admin-analytics: metrics: query api_request_duration_seconds: %v", err)
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var endpoint string
+		var avgMs float64
+		var count uint64
+		if err := rows.Scan(&endpoint, &avgMs, &count); err != nil {
+			continue
+		}
+		// Reported as a single-bucket histogram rather than a true latency
+		// distribution, since api_usage_logs only retains the average.
+		ch <- prometheus.MustNewConstHistogram(apiRequestDurationSeconds, count, avgMs/1000*float64(count),
+			map[float64]uint64{avgMs / 1000: count}, endpoint)
+	}
+}
+
+func (c *Collector) collectAPIKeyRequests(ch chan<- prometheus.Metric) {
+	rows, err := c.DB.Query(`
+		SELECT COALESCE(api_key, ''), COUNT(*)
+		FROM api_usage_logs
+		WHERE api_key IS NOT NULL AND api_key != ''
+		GROUP BY api_key
+	`)
+	if err != nil {
+		log.Printf("admin-analytics: metrics: query api_key_requests_total: %v", err)
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var apiKey string
+		var count float64
+		if err := rows.Scan(&apiKey, &count); err != nil {
+			continue
+		}
+		ch <- prometheus.MustNewConstMetric(apiKeyRequestsTotal, prometheus.CounterValue, count, KeyPrefix(apiKey))
+	}
+}
+
+// KeyPrefix truncates an API key down to the short prefix dashboards group
+// by, so the full secret never needs to leave api_usage_logs.
+func KeyPrefix(apiKey string) string {
+	const prefixLen = 8
+	if len(apiKey) <= prefixLen {
+		return apiKey
+	}
+	return apiKey[:prefixLen]
+}
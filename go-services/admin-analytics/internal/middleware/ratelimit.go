@@ -0,0 +1,331 @@
+// Package middleware holds Gin middleware shared across admin-analytics'
+// routes.
+package middleware
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// apiKeyHeader is the header callers present their API key on, matching the
+// convention other services in this marketplace use for caller identity.
+const apiKeyHeader = "X-API-Key"
+
+// configCacheTTL bounds how long a resolved RateLimitConfig is trusted
+// before being reloaded from the database, so an operator's CRUD change
+// under /api/v1/api-keys/:id/rate-limit takes effect quickly without every
+// request paying a query.
+const configCacheTTL = 30 * time.Second
+
+// rateLimitConfig mirrors models.RateLimitConfig's two window limits. Kept
+// local (rather than importing models) so this package doesn't need to
+// depend on the handlers/models split.
+type rateLimitConfig struct {
+	requestsPerHour int
+	requestsPerDay  int
+}
+
+type configCacheEntry struct {
+	cfg       *rateLimitConfig // nil means "no RateLimitConfig row - unlimited"
+	expiresAt time.Time
+}
+
+// configCache is an in-process TTL cache of api_key_id -> rate limit config,
+// avoiding a database round trip on every request.
+type configCache struct {
+	mu      sync.Mutex
+	entries map[int64]configCacheEntry
+}
+
+func newConfigCache() *configCache {
+	return &configCache{entries: make(map[int64]configCacheEntry)}
+}
+
+func (c *configCache) get(db *sql.DB, apiKeyID int64) (*rateLimitConfig, error) {
+	c.mu.Lock()
+	entry, ok := c.entries[apiKeyID]
+	c.mu.Unlock()
+	if ok && time.Now().Before(entry.expiresAt) {
+		return entry.cfg, nil
+	}
+
+	cfg, err := loadRateLimitConfig(db, apiKeyID)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	c.entries[apiKeyID] = configCacheEntry{cfg: cfg, expiresAt: time.Now().Add(configCacheTTL)}
+	c.mu.Unlock()
+	return cfg, nil
+}
+
+func (c *configCache) invalidate(apiKeyID int64) {
+	c.mu.Lock()
+	delete(c.entries, apiKeyID)
+	c.mu.Unlock()
+}
+
+var cache = newConfigCache()
+
+// InvalidateRateLimitConfig drops apiKeyID's cached config so the next
+// request picks up a change made through the rate-limit CRUD endpoints
+// within one request instead of waiting out configCacheTTL.
+func InvalidateRateLimitConfig(apiKeyID int64) {
+	cache.invalidate(apiKeyID)
+}
+
+func loadRateLimitConfig(db *sql.DB, apiKeyID int64) (*rateLimitConfig, error) {
+	var cfg rateLimitConfig
+	err := db.QueryRow(`
+		SELECT requests_per_hour, requests_per_day
+		FROM rate_limit_configs
+		WHERE api_key_id = ?
+		LIMIT 1
+	`, apiKeyID).Scan(&cfg.requestsPerHour, &cfg.requestsPerDay)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+// window tracks how many requests an API key has made in the current
+// bucket (an hour or a day), resetting whenever the bucket rolls over. It's
+// in-process only (not shared across replicas) - the same tradeoff
+// memory-exchange's monthlyUsage makes, good enough to stop a single
+// runaway caller without adding a round trip to the database per request.
+type window struct {
+	count  int
+	bucket string
+}
+
+// counters holds the live request counts this process has observed per API
+// key, one window per rate-limit granularity.
+type counters struct {
+	mu    sync.Mutex
+	hour  map[int64]*window
+	day   map[int64]*window
+}
+
+func newCounters() *counters {
+	return &counters{hour: make(map[int64]*window), day: make(map[int64]*window)}
+}
+
+// hourBucket/dayBucket identify the current window an API key's count
+// belongs to; incrementing against a stale bucket resets the count first.
+func hourBucket(t time.Time) string { return t.Format("2006010215") }
+func dayBucket(t time.Time) string  { return t.Format("20060102") }
+
+// incrementAndCheck increments apiKeyID's count in both windows and reports
+// whether either limit (0 meaning unlimited) was exceeded, along with the
+// window that was tightest so the caller can report it in response headers.
+func (c *counters) incrementAndCheck(apiKeyID int64, limitPerHour, limitPerDay int, now time.Time) (allowed bool, limit, remaining int, retryAfter time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	hb := hourBucket(now)
+	hw, ok := c.hour[apiKeyID]
+	if !ok || hw.bucket != hb {
+		hw = &window{bucket: hb}
+		c.hour[apiKeyID] = hw
+	}
+
+	dayB := dayBucket(now)
+	dw, ok := c.day[apiKeyID]
+	if !ok || dw.bucket != dayB {
+		dw = &window{bucket: dayB}
+		c.day[apiKeyID] = dw
+	}
+
+	if limitPerHour > 0 && hw.count >= limitPerHour {
+		nextHour := now.Truncate(time.Hour).Add(time.Hour)
+		return false, limitPerHour, 0, nextHour.Sub(now)
+	}
+	if limitPerDay > 0 && dw.count >= limitPerDay {
+		nextDay := now.Truncate(24 * time.Hour).Add(24 * time.Hour)
+		return false, limitPerDay, 0, nextDay.Sub(now)
+	}
+
+	hw.count++
+	dw.count++
+
+	// Report whichever window is closer to being exhausted, since that's
+	// the one the caller needs to watch.
+	limit, remaining = limitPerHour, limitPerHour-hw.count
+	if limitPerDay > 0 && (limitPerHour <= 0 || limitPerDay-dw.count < remaining) {
+		limit, remaining = limitPerDay, limitPerDay-dw.count
+	}
+	if remaining < 0 {
+		remaining = 0
+	}
+	return true, limit, remaining, 0
+}
+
+// usage reports apiKeyID's current hour/day counts without mutating them,
+// used by the near-quota analytics endpoint.
+func (c *counters) usage(apiKeyID int64, now time.Time) (hourCount, dayCount int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if hw, ok := c.hour[apiKeyID]; ok && hw.bucket == hourBucket(now) {
+		hourCount = hw.count
+	}
+	if dw, ok := c.day[apiKeyID]; ok && dw.bucket == dayBucket(now) {
+		dayCount = dw.count
+	}
+	return hourCount, dayCount
+}
+
+// trackedKeys lists every API key ID this process has counted a request for
+// in the current hour or day window.
+func (c *counters) trackedKeys(now time.Time) []int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	seen := make(map[int64]bool)
+	for id, w := range c.hour {
+		if w.bucket == hourBucket(now) {
+			seen[id] = true
+		}
+	}
+	for id, w := range c.day {
+		if w.bucket == dayBucket(now) {
+			seen[id] = true
+		}
+	}
+	ids := make([]int64, 0, len(seen))
+	for id := range seen {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+var requestCounters = newCounters()
+
+// hashAPIKey matches the SHA2(?, 256) the api_keys.key_hash column expects,
+// the same scheme memory-exchange's auth middleware uses.
+func hashAPIKey(apiKey string) string {
+	sum := sha256.Sum256([]byte(apiKey))
+	return hex.EncodeToString(sum[:])
+}
+
+// resolveAPIKeyID looks up the api_keys row for apiKey, returning
+// (0, nil) if it isn't found so callers can choose to let the request
+// through unauthenticated rather than treating a missing key as an error.
+func resolveAPIKeyID(db *sql.DB, apiKey string) (int64, error) {
+	var id int64
+	err := db.QueryRow(`SELECT id FROM api_keys WHERE key_hash = SHA2(?, 256) AND is_active = 1 LIMIT 1`, apiKey).Scan(&id)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
+// RateLimit builds Gin middleware enforcing each caller's RateLimitConfig
+// (requests_per_hour/requests_per_day). Requests without an X-API-Key
+// header, or whose key has no RateLimitConfig row, pass through
+// unrestricted - this only tightens callers an operator has explicitly
+// configured a limit for. Pass a nil db to disable enforcement entirely
+// (e.g. when running against mock data).
+func RateLimit(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if db == nil {
+			c.Next()
+			return
+		}
+
+		apiKey := c.GetHeader(apiKeyHeader)
+		if apiKey == "" {
+			c.Next()
+			return
+		}
+
+		apiKeyID, err := resolveAPIKeyID(db, apiKey)
+		if err != nil || apiKeyID == 0 {
+			c.Next()
+			return
+		}
+
+		cfg, err := cache.get(db, apiKeyID)
+		if err != nil || cfg == nil {
+			c.Next()
+			return
+		}
+
+		now := time.Now()
+		allowed, limit, remaining, retryAfter := requestCounters.incrementAndCheck(apiKeyID, cfg.requestsPerHour, cfg.requestsPerDay, now)
+
+		c.Header("X-RateLimit-Limit", strconv.Itoa(limit))
+		c.Header("X-RateLimit-Remaining", strconv.Itoa(remaining))
+		if !allowed {
+			c.Header("Retry-After", strconv.Itoa(int(retryAfter.Seconds())))
+			c.JSON(http.StatusTooManyRequests, gin.H{"error": "rate limit exceeded"})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// NearQuotaKey is one API key whose current usage is approaching its
+// configured limit, as reported by GET /api/v1/analytics/rate-limits.
+type NearQuotaKey struct {
+	APIKeyID        int64   `json:"apiKeyId"`
+	RequestsPerHour int     `json:"requestsPerHour"`
+	RequestsPerDay  int     `json:"requestsPerDay"`
+	HourUsage       int     `json:"hourUsage"`
+	DayUsage        int     `json:"dayUsage"`
+	HourFraction    float64 `json:"hourFraction"`
+	DayFraction     float64 `json:"dayFraction"`
+}
+
+// NearQuotaKeys returns every API key this process has tracked usage for
+// whose hour or day window usage is at or above threshold (e.g. 0.8 for
+// "80% of quota"), ordered by nothing in particular - callers sort however
+// suits their dashboard. Limited to keys with a configured, non-zero limit
+// on the window being checked.
+func NearQuotaKeys(db *sql.DB, threshold float64) ([]NearQuotaKey, error) {
+	now := time.Now()
+	var result []NearQuotaKey
+	for _, apiKeyID := range requestCounters.trackedKeys(now) {
+		cfg, err := cache.get(db, apiKeyID)
+		if err != nil || cfg == nil {
+			continue
+		}
+		hourUsage, dayUsage := requestCounters.usage(apiKeyID, now)
+
+		var hourFraction, dayFraction float64
+		if cfg.requestsPerHour > 0 {
+			hourFraction = float64(hourUsage) / float64(cfg.requestsPerHour)
+		}
+		if cfg.requestsPerDay > 0 {
+			dayFraction = float64(dayUsage) / float64(cfg.requestsPerDay)
+		}
+		if hourFraction < threshold && dayFraction < threshold {
+			continue
+		}
+
+		result = append(result, NearQuotaKey{
+			APIKeyID:        apiKeyID,
+			RequestsPerHour: cfg.requestsPerHour,
+			RequestsPerDay:  cfg.requestsPerDay,
+			HourUsage:       hourUsage,
+			DayUsage:        dayUsage,
+			HourFraction:    hourFraction,
+			DayFraction:     dayFraction,
+		})
+	}
+	return result, nil
+}
@@ -0,0 +1,180 @@
+package promql
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// metricDef describes, for one metric in metricDefs, the api_usage_logs
+// column each of its labels filters/groups on, and whether the metric aggregates as a count
+// (api_requests_total, api_key_requests_total) or an average
+// (api_request_duration_seconds).
+type metricDef struct {
+	labelColumns map[string]string // label name -> SQL column (or expression)
+	avgColumn    string            // non-empty: AVG(avgColumn)/1000 instead of COUNT(*)
+}
+
+var metricDefs = map[string]metricDef{
+	"api_requests_total": {
+		labelColumns: map[string]string{
+			"status":   "status_code",
+			"endpoint": "endpoint",
+			"user":     "api_key",
+		},
+	},
+	"api_request_duration_seconds": {
+		labelColumns: map[string]string{
+			"endpoint": "endpoint",
+		},
+		avgColumn: "response_time_ms",
+	},
+	"api_key_requests_total": {
+		labelColumns: map[string]string{
+			"key_prefix": "LEFT(api_key, 8)",
+		},
+	},
+}
+
+// loadSeries resolves metric+matchers into one Series per distinct label
+// combination, with one Point per timestamp computed over [ts-window, ts].
+func (e *Engine) loadSeries(metric string, matchers map[string]string, timestamps []time.Time, window time.Duration) ([]Series, error) {
+	if metric == "service_up" {
+		return e.loadServiceUp(matchers, timestamps), nil
+	}
+
+	def, ok := metricDefs[metric]
+	if !ok {
+		return nil, fmt.Errorf("unknown metric %q", metric)
+	}
+	if e.DB == nil {
+		return nil, nil
+	}
+
+	for label := range matchers {
+		if _, ok := def.labelColumns[label]; !ok {
+			return nil, fmt.Errorf("metric %q has no label %q", metric, label)
+		}
+	}
+
+	byLabels := map[string]*Series{}
+	order := sortedLabelNames(def.labelColumns)
+
+	for i, ts := range timestamps {
+		rows, err := e.queryBucket(def, matchers, order, ts, window)
+		if err != nil {
+			return nil, err
+		}
+		for key, value := range rows {
+			s, ok := byLabels[key]
+			if !ok {
+				s = &Series{Labels: labelsFromKey(order, key), Points: make([]Sample, len(timestamps))}
+				for j, t := range timestamps {
+					s.Points[j] = Sample{Timestamp: t}
+				}
+				byLabels[key] = s
+			}
+			s.Points[i].Value = value
+		}
+	}
+
+	out := make([]Series, 0, len(byLabels))
+	for _, s := range byLabels {
+		out = append(out, *s)
+	}
+	return out, nil
+}
+
+// queryBucket runs one aggregate query over [ts-window, ts] and returns a
+// map from a stable label-value key to the aggregated value.
+func (e *Engine) queryBucket(def metricDef, matchers map[string]string, order []string, ts time.Time, window time.Duration) (map[string]float64, error) {
+	selectCols := make([]string, len(order))
+	for i, label := range order {
+		selectCols[i] = def.labelColumns[label]
+	}
+
+	aggregate := "COUNT(*)"
+	if def.avgColumn != "" {
+		aggregate = fmt.Sprintf("AVG(%s)", def.avgColumn)
+	}
+
+	query := fmt.Sprintf(
+		"SELECT %s, %s FROM api_usage_logs WHERE created_at > ? AND created_at <= ?",
+		strings.Join(selectCols, ", "), aggregate,
+	)
+	args := []interface{}{ts.Add(-window), ts}
+
+	for label, value := range matchers {
+		query += fmt.Sprintf(" AND %s = ?", def.labelColumns[label])
+		args = append(args, value)
+	}
+	if len(order) > 0 {
+		query += " GROUP BY " + strings.Join(selectCols, ", ")
+	}
+
+	rows, err := e.DB.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query %s: %w", def.avgColumn, err)
+	}
+	defer rows.Close()
+
+	result := map[string]float64{}
+	for rows.Next() {
+		vals := make([]string, len(order))
+		scanDest := make([]interface{}, len(order)+1)
+		for i := range vals {
+			scanDest[i] = &vals[i]
+		}
+		var value float64
+		scanDest[len(order)] = &value
+		if err := rows.Scan(scanDest...); err != nil {
+			continue
+		}
+		if def.avgColumn != "" {
+			value = value / 1000 // ms -> seconds
+		}
+		result[strings.Join(vals, "\x1f")] = value
+	}
+	return result, nil
+}
+
+func (e *Engine) loadServiceUp(matchers map[string]string, timestamps []time.Time) []Series {
+	want, filtered := matchers["service"]
+	var out []Series
+	for _, svc := range e.Health.Snapshot() {
+		if filtered && svc.ServiceName != want {
+			continue
+		}
+		value := 0.0
+		if svc.Status == "running" {
+			value = 1.0
+		}
+		points := make([]Sample, len(timestamps))
+		for i, t := range timestamps {
+			points[i] = Sample{Timestamp: t, Value: value}
+		}
+		out = append(out, Series{Labels: map[string]string{"service": svc.ServiceName}, Points: points})
+	}
+	return out
+}
+
+func sortedLabelNames(labelColumns map[string]string) []string {
+	names := make([]string, 0, len(labelColumns))
+	for name := range labelColumns {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func labelsFromKey(order []string, key string) map[string]string {
+	values := strings.Split(key, "\x1f")
+	labels := make(map[string]string, len(order))
+	for i, name := range order {
+		if i < len(values) {
+			labels[name] = values[i]
+		}
+	}
+	return labels
+}
@@ -0,0 +1,181 @@
+package promql
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// defaultWindow is the lookback used for a bare selector (no "[duration]"
+// suffix) in an instant query; in a range query a bare selector uses the
+// step as its window instead.
+const defaultWindow = 5 * time.Minute
+
+// HealthService is the subset of a polled service's status this package
+// needs to evaluate the service_up metric.
+type HealthService struct {
+	ServiceName string
+	Status      string
+}
+
+// HealthSnapshotter is satisfied by *metrics.HealthRegistry; kept as a
+// narrow interface here so promql doesn't import metrics.
+type HealthSnapshotter interface {
+	Snapshot() []HealthService
+}
+
+// Engine evaluates Parse'd expressions against api_usage_logs and a health
+// snapshot.
+type Engine struct {
+	DB     *sql.DB
+	Health HealthSnapshotter
+}
+
+func NewEngine(db *sql.DB, health HealthSnapshotter) *Engine {
+	return &Engine{DB: db, Health: health}
+}
+
+// Sample is one (timestamp, value) point of a Series.
+type Sample struct {
+	Timestamp time.Time
+	Value     float64
+}
+
+// Series is one label-tagged time series produced by a query.
+type Series struct {
+	Labels map[string]string
+	Points []Sample
+}
+
+// Instant evaluates expr at a single point in time.
+func (e *Engine) Instant(expr string, at time.Time) ([]Series, error) {
+	n, err := Parse(expr)
+	if err != nil {
+		return nil, err
+	}
+	return e.eval(n, []time.Time{at}, 0)
+}
+
+// Range evaluates expr at every step between start and end, inclusive.
+func (e *Engine) Range(expr string, start, end time.Time, step time.Duration) ([]Series, error) {
+	if step <= 0 {
+		return nil, fmt.Errorf("step must be positive")
+	}
+	n, err := Parse(expr)
+	if err != nil {
+		return nil, err
+	}
+
+	var timestamps []time.Time
+	for t := start; !t.After(end); t = t.Add(step) {
+		timestamps = append(timestamps, t)
+	}
+	if len(timestamps) == 0 {
+		return nil, fmt.Errorf("empty time range")
+	}
+	return e.eval(n, timestamps, step)
+}
+
+// eval walks n, producing one Series per distinct label set with one Point
+// per timestamp. step is the range query's spacing (0 for an instant
+// query), used as the bucket width a bare selector aggregates over.
+func (e *Engine) eval(n node, timestamps []time.Time, step time.Duration) ([]Series, error) {
+	switch v := n.(type) {
+	case selectorNode:
+		window := v.window
+		if window == 0 {
+			window = step
+		}
+		if window == 0 {
+			window = defaultWindow
+		}
+		return e.loadSeries(v.metric, v.matchers, timestamps, window)
+
+	case callNode:
+		switch v.fn {
+		case "sum":
+			series, err := e.eval(v.args[0], timestamps, step)
+			if err != nil {
+				return nil, err
+			}
+			return []Series{sumSeries(series, timestamps)}, nil
+
+		case "rate":
+			sel, ok := v.args[0].(selectorNode)
+			if !ok || sel.window == 0 {
+				return nil, fmt.Errorf("rate() requires a range selector, e.g. rate(metric[5m])")
+			}
+			series, err := e.eval(sel, timestamps, step)
+			if err != nil {
+				return nil, err
+			}
+			windowSeconds := sel.window.Seconds()
+			for i := range series {
+				for j := range series[i].Points {
+					series[i].Points[j].Value /= windowSeconds
+				}
+			}
+			return series, nil
+
+		case "avg_over_time":
+			sel, ok := v.args[0].(selectorNode)
+			if !ok || sel.window == 0 {
+				return nil, fmt.Errorf("avg_over_time() requires a range selector, e.g. avg_over_time(metric[5m])")
+			}
+			// The underlying aggregate (see loadSeries) is already an
+			// average for duration-style metrics and a count otherwise;
+			// avg_over_time widens the window those averages are taken
+			// over rather than re-averaging samples we don't retain.
+			return e.eval(sel, timestamps, step)
+
+		case "topk":
+			series, err := e.eval(v.args[0], timestamps, step)
+			if err != nil {
+				return nil, err
+			}
+			return topK(series, v.k), nil
+
+		default:
+			return nil, fmt.Errorf("unsupported function %q", v.fn)
+		}
+
+	default:
+		return nil, fmt.Errorf("unrecognized expression node %T", n)
+	}
+}
+
+func sumSeries(series []Series, timestamps []time.Time) Series {
+	totals := make([]float64, len(timestamps))
+	for _, s := range series {
+		for i, p := range s.Points {
+			if i < len(totals) {
+				totals[i] += p.Value
+			}
+		}
+	}
+	points := make([]Sample, len(timestamps))
+	for i, t := range timestamps {
+		points[i] = Sample{Timestamp: t, Value: totals[i]}
+	}
+	return Series{Points: points}
+}
+
+func topK(series []Series, k int) []Series {
+	sorted := make([]Series, len(series))
+	copy(sorted, series)
+	sort.Slice(sorted, func(i, j int) bool {
+		return lastValue(sorted[i]) > lastValue(sorted[j])
+	})
+	if k < len(sorted) {
+		sorted = sorted[:k]
+	}
+	return sorted
+}
+
+func lastValue(s Series) float64 {
+	if len(s.Points) == 0 {
+		return 0
+	}
+	return s.Points[len(s.Points)-1].Value
+}
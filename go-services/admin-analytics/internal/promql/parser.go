@@ -0,0 +1,264 @@
+// Package promql evaluates a small PromQL-like subset directly against
+// api_usage_logs and the service-health registry, so /api/v1/analytics/query
+// and /api/v1/analytics/query_range can answer arbitrary dashboard timelines
+// without a new handler per query.
+//
+// Supported grammar:
+//
+//	expr      := call | selector
+//	call      := ident "(" arg ("," arg)* ")"
+//	selector  := ident ["{" matcher ("," matcher)* "}"] ["[" duration "]"]
+//	matcher   := ident "=" '"' value '"'
+//	duration  := number ("s" | "m" | "h" | "d")
+//
+// Recognized functions: sum(vector), topk(k, vector), rate(vector[duration]),
+// avg_over_time(vector[duration]).
+package promql
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// node is either a selectorNode or a callNode.
+type node interface{}
+
+type selectorNode struct {
+	metric   string
+	matchers map[string]string
+	window   time.Duration // zero if the selector had no "[duration]" suffix
+}
+
+type callNode struct {
+	fn   string
+	k    int // topk's first argument; unused otherwise
+	args []node
+}
+
+// ParseStep parses a query_range "step" parameter, using the same duration
+// syntax as a selector's "[duration]" range (e.g. "30s", "5m", "1h", "1d").
+func ParseStep(s string) (time.Duration, error) {
+	return parseDuration(s)
+}
+
+// Parse compiles expr into an evaluable node.
+func Parse(expr string) (node, error) {
+	n, rest, err := parseExpr(strings.TrimSpace(expr))
+	if err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(rest) != "" {
+		return nil, fmt.Errorf("unexpected trailing input: %q", rest)
+	}
+	return n, nil
+}
+
+func parseExpr(s string) (node, string, error) {
+	s = strings.TrimSpace(s)
+	name, rest := readIdent(s)
+	if name == "" {
+		return nil, "", fmt.Errorf("expected identifier at %q", s)
+	}
+	rest = strings.TrimSpace(rest)
+
+	if strings.HasPrefix(rest, "(") {
+		inner, after, err := readBalanced(rest, '(', ')')
+		if err != nil {
+			return nil, "", err
+		}
+		args, err := splitTopLevel(inner)
+		if err != nil {
+			return nil, "", err
+		}
+		return parseCall(name, args, after)
+	}
+
+	return parseSelectorTail(name, rest)
+}
+
+func parseCall(fn string, rawArgs []string, rest string) (node, string, error) {
+	switch fn {
+	case "sum", "rate", "avg_over_time":
+		if len(rawArgs) != 1 {
+			return nil, "", fmt.Errorf("%s() takes exactly one argument, got %d", fn, len(rawArgs))
+		}
+		arg, err := parseArg(rawArgs[0])
+		if err != nil {
+			return nil, "", err
+		}
+		return callNode{fn: fn, args: []node{arg}}, rest, nil
+
+	case "topk":
+		if len(rawArgs) != 2 {
+			return nil, "", fmt.Errorf("topk() takes exactly two arguments, got %d", len(rawArgs))
+		}
+		k, err := strconv.Atoi(strings.TrimSpace(rawArgs[0]))
+		if err != nil {
+			return nil, "", fmt.Errorf("topk() first argument must be an integer: %w", err)
+		}
+		arg, err := parseArg(rawArgs[1])
+		if err != nil {
+			return nil, "", err
+		}
+		return callNode{fn: fn, k: k, args: []node{arg}}, rest, nil
+
+	default:
+		return nil, "", fmt.Errorf("unsupported function %q", fn)
+	}
+}
+
+func parseArg(s string) (node, error) {
+	n, rest, err := parseExpr(s)
+	if err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(rest) != "" {
+		return nil, fmt.Errorf("unexpected trailing input in argument: %q", rest)
+	}
+	return n, nil
+}
+
+func parseSelectorTail(metric, rest string) (node, string, error) {
+	sel := selectorNode{metric: metric, matchers: map[string]string{}}
+	rest = strings.TrimSpace(rest)
+
+	if strings.HasPrefix(rest, "{") {
+		inner, after, err := readBalanced(rest, '{', '}')
+		if err != nil {
+			return nil, "", err
+		}
+		matchers, err := splitTopLevel(inner)
+		if err != nil {
+			return nil, "", err
+		}
+		for _, m := range matchers {
+			k, v, err := parseMatcher(m)
+			if err != nil {
+				return nil, "", err
+			}
+			sel.matchers[k] = v
+		}
+		rest = strings.TrimSpace(after)
+	}
+
+	if strings.HasPrefix(rest, "[") {
+		inner, after, err := readBalanced(rest, '[', ']')
+		if err != nil {
+			return nil, "", err
+		}
+		dur, err := parseDuration(inner)
+		if err != nil {
+			return nil, "", err
+		}
+		sel.window = dur
+		rest = after
+	}
+
+	return sel, rest, nil
+}
+
+func parseMatcher(s string) (string, string, error) {
+	eq := strings.Index(s, "=")
+	if eq < 0 {
+		return "", "", fmt.Errorf("malformed label matcher %q", s)
+	}
+	key := strings.TrimSpace(s[:eq])
+	val := strings.TrimSpace(s[eq+1:])
+	if len(val) < 2 || val[0] != '"' || val[len(val)-1] != '"' {
+		return "", "", fmt.Errorf("label matcher value must be quoted: %q", s)
+	}
+	return key, val[1 : len(val)-1], nil
+}
+
+// parseDuration parses PromQL-style durations. time.ParseDuration already
+// handles "s"/"m"/"h"; "d" is added since Prometheus ranges are commonly
+// expressed in days and ParseDuration doesn't know that unit.
+func parseDuration(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	if strings.HasSuffix(s, "d") {
+		n, err := strconv.Atoi(strings.TrimSuffix(s, "d"))
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration %q: %w", s, err)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid duration %q: %w", s, err)
+	}
+	return d, nil
+}
+
+func readIdent(s string) (name, rest string) {
+	i := 0
+	for i < len(s) && (isAlnum(s[i]) || s[i] == '_') {
+		i++
+	}
+	return s[:i], s[i:]
+}
+
+func isAlnum(b byte) bool {
+	return (b >= 'a' && b <= 'z') || (b >= 'A' && b <= 'Z') || (b >= '0' && b <= '9')
+}
+
+// readBalanced expects s to start with open, and returns the content between
+// it and its matching close (honoring nested brackets and quoted strings),
+// plus whatever follows the close.
+func readBalanced(s string, open, close byte) (inner, rest string, err error) {
+	if len(s) == 0 || s[0] != open {
+		return "", "", fmt.Errorf("expected %q at %q", open, s)
+	}
+	depth := 0
+	inQuote := false
+	for i := 0; i < len(s); i++ {
+		switch {
+		case s[i] == '"':
+			inQuote = !inQuote
+		case inQuote:
+			// skip characters inside a quoted string
+		case s[i] == open:
+			depth++
+		case s[i] == close:
+			depth--
+			if depth == 0 {
+				return s[1:i], s[i+1:], nil
+			}
+		}
+	}
+	return "", "", fmt.Errorf("unbalanced %q in %q", open, s)
+}
+
+// splitTopLevel splits a comma-separated argument list, ignoring commas
+// nested inside brackets or quoted strings.
+func splitTopLevel(s string) ([]string, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil, nil
+	}
+
+	var parts []string
+	depth := 0
+	inQuote := false
+	start := 0
+	for i := 0; i < len(s); i++ {
+		switch {
+		case s[i] == '"':
+			inQuote = !inQuote
+		case inQuote:
+		case s[i] == '(' || s[i] == '{' || s[i] == '[':
+			depth++
+		case s[i] == ')' || s[i] == '}' || s[i] == ']':
+			depth--
+		case s[i] == ',' && depth == 0:
+			parts = append(parts, strings.TrimSpace(s[start:i]))
+			start = i + 1
+		}
+	}
+	if inQuote || depth != 0 {
+		return nil, fmt.Errorf("unbalanced expression: %q", s)
+	}
+	parts = append(parts, strings.TrimSpace(s[start:]))
+	return parts, nil
+}
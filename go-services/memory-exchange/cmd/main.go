@@ -1,16 +1,27 @@
 package main
 
 import (
+	"context"
+	"database/sql"
 	"log"
+	"net"
 	"os"
+	"time"
 
+	grpcapi "github.com/awareness/memory-exchange/internal/api/grpc"
+	"github.com/awareness/memory-exchange/internal/api/grpc/pb"
 	"github.com/awareness/memory-exchange/internal/database"
 	"github.com/awareness/memory-exchange/internal/handlers"
 	"github.com/awareness/memory-exchange/internal/middleware"
+	"github.com/awareness/memory-exchange/internal/plugins"
+	"github.com/awareness/memory-exchange/internal/relayapi"
+	"github.com/awareness/memory-exchange/internal/search"
+	"github.com/awareness/memory-exchange/internal/service"
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
+	"google.golang.org/grpc"
 	_ "github.com/awareness/memory-exchange/docs" // Import generated docs
 )
 
@@ -46,6 +57,17 @@ func main() {
 	}
 	defer database.CloseDB()
 
+	// Select the search engine for GET /api/v1/search (SEARCH_ENGINE=es|mysql,
+	// default mysql); falls back to MySQLEngine on any ES setup failure.
+	if err := search.Init(context.Background()); err != nil {
+		log.Printf("Warning: %v", err)
+	}
+
+	// Shared business logic behind both the REST handlers below and the
+	// optional Marketplace gRPC service.
+	svc := service.New(database.DB)
+	handlers.Init(svc)
+
 	// Set Gin mode
 	if os.Getenv("NODE_ENV") == "production" {
 		gin.SetMode(gin.ReleaseMode)
@@ -75,29 +97,105 @@ func main() {
 		})
 	})
 
-	// API v1 routes (require authentication)
+	// Published alongside /health (no auth required) so downstream
+	// services can fetch the receipt-verification key without first
+	// holding an API key of their own.
+	router.GET("/.well-known/marketplace-pubkey", handlers.WellKnownPublicKey)
+
+	// Unauthenticated, database-free counterpart to
+	// /api/v1/memory/receipts/:tx_id/verify: a buyer or downstream service
+	// can verify a receipt it was simply handed, without holding an API key
+	// or this service still having the original transaction on record.
+	router.POST("/.well-known/verify-receipt", handlers.VerifyExternalReceipt)
+
+	// Plugin-chain framework: each v1 route's auth/rate-limit/logging
+	// behavior is a declarative binding in routePlugins rather than an
+	// imperative .Use(...) call, and can be changed at runtime via the
+	// /admin/plugins endpoints below without a restart.
+	pluginManager := plugins.NewManager(database.DB)
+	if err := seedDefaultRoutePlugins(database.DB, routePlugins); err != nil {
+		log.Printf("Warning: failed to seed default route plugins: %v", err)
+	}
+	if err := pluginManager.Reload(); err != nil {
+		log.Printf("Warning: failed to load route plugin chains: %v", err)
+	}
+
+	// API v1 routes
 	v1 := router.Group("/api/v1")
-	v1.Use(middleware.APIKeyAuth())
 	{
 		// Memory Exchange endpoints
 		memory := v1.Group("/memory")
 		{
-			memory.POST("/publish", handlers.PublishMemory)
-			memory.POST("/purchase", handlers.PurchaseMemory)
-			memory.GET("/browse", handlers.BrowseMemories)
-			memory.GET("/my-history", handlers.GetMyHistory)
+			memory.POST("/publish", pluginManager.Route(routeMemoryPublish), handlers.PublishMemory)
+			memory.POST("/reserve", pluginManager.Route(routeMemoryReserve), handlers.ReserveMemory)
+			memory.POST("/confirm", pluginManager.Route(routeMemoryConfirm), handlers.ConfirmMemory)
+			memory.POST("/refund", pluginManager.Route(routeMemoryRefund), handlers.RefundMemory)
+			memory.GET("/browse", pluginManager.Route(routeMemoryBrowse), handlers.BrowseMemories)
+			memory.GET("/my-history", pluginManager.Route(routeMemoryHistory), handlers.GetMyHistory)
+			memory.GET("/receipts/:tx_id/verify", pluginManager.Route(routeMemoryReceiptVerify), handlers.VerifyReceipt)
 		}
 
+		// Catalog search endpoint
+		v1.GET("/search", pluginManager.Route(routeSearch), handlers.SearchCatalog)
+
 		// Reasoning Chain endpoints
 		reasoning := v1.Group("/reasoning-chain")
 		{
-			reasoning.POST("/publish", handlers.PublishReasoningChain)
-			reasoning.POST("/use", handlers.UseReasoningChain)
-			reasoning.GET("/browse", handlers.BrowseReasoningChains)
+			reasoning.POST("/publish", pluginManager.Route(routeReasoningPublish), handlers.PublishReasoningChain)
+			reasoning.POST("/use", pluginManager.Route(routeReasoningUse), handlers.UseReasoningChain)
+			reasoning.POST("/purchase", pluginManager.Route(routeReasoningPurchase), handlers.PurchaseReasoningChain)
+			reasoning.GET("/browse", pluginManager.Route(routeReasoningBrowse), handlers.BrowseReasoningChains)
+			reasoning.GET("/my-entitlements", pluginManager.Route(routeReasoningMyEntitlements), handlers.GetMyEntitlements)
 		}
 
 		// Stats endpoint
-		v1.GET("/stats", handlers.GetStats)
+		v1.GET("/stats", pluginManager.Route(routeStats), handlers.GetStats)
+
+		// Relay endpoints for store-and-forward delivery to offline/NAT'd buyers
+		relay := v1.Group("/relay")
+		{
+			relay.POST("/register", pluginManager.Route(routeRelayRegister), relayapi.RegisterRelay)
+			relay.POST("/forward", pluginManager.Route(routeRelayForward), relayapi.ForwardEnvelope)
+			relay.GET("/pickup", pluginManager.Route(routeRelayPickup), relayapi.PickupEnvelopes)
+			relay.POST("/ack", pluginManager.Route(routeRelayAck), relayapi.AckEnvelope)
+		}
+
+		// Plugin administration: bind/inspect/remove plugins per route,
+		// with the config validated against the plugin's schema before
+		// it is persisted and hot-reloaded into the compiled chain.
+		admin := v1.Group("/admin/plugins")
+		admin.Use(middleware.APIKeyAuth())
+		{
+			admin.GET("/*route", pluginManager.ListRoutePlugins)
+			admin.POST("/*route", pluginManager.UpsertRoutePlugin)
+			admin.PUT("/*route", pluginManager.UpsertRoutePlugin)
+			admin.DELETE("/*route", pluginManager.DeleteRoutePlugin)
+		}
+	}
+
+	// Periodically promote/demote relays based on observed delivery success
+	go relayapi.RunReconciliation(context.Background(), 10*time.Minute)
+
+	// Periodically refund reservations a buyer never confirmed or refunded,
+	// so their hold on the listing and the buyer's own funds don't linger.
+	go svc.RunEscrowReaper(context.Background(), time.Minute)
+
+	// Optional Marketplace gRPC service for typed clients, backed by the
+	// same service.Service as the REST handlers above. Disabled unless
+	// GRPC_PORT is set.
+	if grpcPort := os.Getenv("GRPC_PORT"); grpcPort != "" {
+		lis, err := net.Listen("tcp", ":"+grpcPort)
+		if err != nil {
+			log.Fatalf("Failed to listen on gRPC port %s: %v", grpcPort, err)
+		}
+		grpcSrv := grpc.NewServer()
+		pb.RegisterMarketplaceServer(grpcSrv, grpcapi.NewServer(svc))
+		go func() {
+			log.Printf("Memory Exchange gRPC service starting on port %s", grpcPort)
+			if err := grpcSrv.Serve(lis); err != nil {
+				log.Fatalf("Failed to start gRPC server: %v", err)
+			}
+		}()
 	}
 
 	// Start server
@@ -112,3 +210,88 @@ func main() {
 		log.Fatal("Failed to start server:", err)
 	}
 }
+
+// Route keys passed to pluginManager.Route and used as the route_plugins
+// primary key. Format is "METHOD:/path" so a key can be embedded in the
+// /admin/plugins/*route wildcard without colliding with gin's own
+// path-separator parsing.
+const (
+	routeMemoryPublish           = "POST:/api/v1/memory/publish"
+	routeMemoryReserve           = "POST:/api/v1/memory/reserve"
+	routeMemoryConfirm           = "POST:/api/v1/memory/confirm"
+	routeMemoryRefund            = "POST:/api/v1/memory/refund"
+	routeMemoryBrowse            = "GET:/api/v1/memory/browse"
+	routeMemoryHistory           = "GET:/api/v1/memory/my-history"
+	routeMemoryReceiptVerify     = "GET:/api/v1/memory/receipts/:tx_id/verify"
+	routeReasoningPublish        = "POST:/api/v1/reasoning-chain/publish"
+	routeReasoningUse            = "POST:/api/v1/reasoning-chain/use"
+	routeReasoningPurchase       = "POST:/api/v1/reasoning-chain/purchase"
+	routeReasoningBrowse         = "GET:/api/v1/reasoning-chain/browse"
+	routeReasoningMyEntitlements = "GET:/api/v1/reasoning-chain/my-entitlements"
+	routeSearch                  = "GET:/api/v1/search"
+	routeStats                   = "GET:/api/v1/stats"
+	routeRelayRegister           = "POST:/api/v1/relay/register"
+	routeRelayForward            = "POST:/api/v1/relay/forward"
+	routeRelayPickup             = "GET:/api/v1/relay/pickup"
+	routeRelayAck                = "POST:/api/v1/relay/ack"
+)
+
+// defaultRoutePlugin is one row of the declarative route->plugin bindings
+// this service ships with out of the box, equivalent to what used to be
+// the hard-coded v1.Use(middleware.APIKeyAuth()) call.
+type defaultRoutePlugin struct {
+	Route      string
+	PluginName string
+	Priority   int
+	Config     string // raw JSON
+}
+
+var routePlugins = []defaultRoutePlugin{
+	{routeMemoryPublish, "api-key-auth", 10, "{}"},
+	{routeMemoryPublish, "response-logger", 100, `{"service_name":"memory-exchange"}`},
+	{routeMemoryReserve, "api-key-auth", 10, "{}"},
+	{routeMemoryReserve, "response-logger", 100, `{"service_name":"memory-exchange"}`},
+	{routeMemoryConfirm, "api-key-auth", 10, "{}"},
+	{routeMemoryConfirm, "response-logger", 100, `{"service_name":"memory-exchange"}`},
+	{routeMemoryRefund, "api-key-auth", 10, "{}"},
+	{routeMemoryRefund, "response-logger", 100, `{"service_name":"memory-exchange"}`},
+	{routeMemoryBrowse, "api-key-auth", 10, "{}"},
+	{routeMemoryBrowse, "response-logger", 100, `{"service_name":"memory-exchange"}`},
+	{routeMemoryHistory, "api-key-auth", 10, "{}"},
+	{routeMemoryHistory, "response-logger", 100, `{"service_name":"memory-exchange"}`},
+	{routeMemoryReceiptVerify, "api-key-auth", 10, "{}"},
+	{routeMemoryReceiptVerify, "response-logger", 100, `{"service_name":"memory-exchange"}`},
+	{routeReasoningPublish, "api-key-auth", 10, "{}"},
+	{routeReasoningPublish, "response-logger", 100, `{"service_name":"memory-exchange"}`},
+	{routeReasoningUse, "api-key-auth", 10, "{}"},
+	{routeReasoningUse, "response-logger", 100, `{"service_name":"memory-exchange"}`},
+	{routeReasoningPurchase, "api-key-auth", 10, "{}"},
+	{routeReasoningPurchase, "response-logger", 100, `{"service_name":"memory-exchange"}`},
+	{routeReasoningBrowse, "api-key-auth", 10, "{}"},
+	{routeReasoningBrowse, "response-logger", 100, `{"service_name":"memory-exchange"}`},
+	{routeReasoningMyEntitlements, "api-key-auth", 10, "{}"},
+	{routeReasoningMyEntitlements, "response-logger", 100, `{"service_name":"memory-exchange"}`},
+	{routeSearch, "api-key-auth", 10, "{}"},
+	{routeSearch, "response-logger", 100, `{"service_name":"memory-exchange"}`},
+	{routeStats, "api-key-auth", 10, "{}"},
+	{routeRelayRegister, "api-key-auth", 10, "{}"},
+	{routeRelayForward, "api-key-auth", 10, "{}"},
+	{routeRelayPickup, "api-key-auth", 10, "{}"},
+	{routeRelayAck, "api-key-auth", 10, "{}"},
+}
+
+// seedDefaultRoutePlugins inserts defaults the first time a route is seen,
+// so the service works out of the box, but never overwrites a binding an
+// operator has already customized via the /admin/plugins endpoints.
+func seedDefaultRoutePlugins(db *sql.DB, defaults []defaultRoutePlugin) error {
+	for _, d := range defaults {
+		_, err := db.Exec(`
+			INSERT IGNORE INTO route_plugins (route, plugin_name, priority, config, enabled)
+			VALUES (?, ?, ?, ?, TRUE)
+		`, d.Route, d.PluginName, d.Priority, d.Config)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
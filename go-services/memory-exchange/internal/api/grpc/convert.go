@@ -0,0 +1,108 @@
+package grpc
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/awareness/memory-exchange/internal/api/grpc/pb"
+	"github.com/awareness/memory-exchange/internal/models"
+	"github.com/awareness/memory-exchange/internal/service"
+)
+
+func toProtoMemory(m models.MemoryExchange) *pb.Memory {
+	return &pb.Memory{
+		Id:            int64(m.ID),
+		SellerId:      int64(m.SellerID),
+		BuyerId:       int64(m.BuyerID),
+		MemoryType:    m.MemoryType,
+		Price:         m.Price,
+		Status:        m.Status,
+		CreatedAtUnix: m.CreatedAt.Unix(),
+	}
+}
+
+func toProtoReasoningChain(c models.ReasoningChain) *pb.ReasoningChain {
+	out := &pb.ReasoningChain{
+		Id:          int64(c.ID),
+		CreatorId:   int64(c.CreatorID),
+		Category:    c.Category,
+		PricePerUse: c.PricePerUse,
+		Status:      c.Status,
+	}
+	if c.KVCacheSnapshot != nil {
+		out.KvCacheSnapshot = *c.KVCacheSnapshot
+	}
+	return out
+}
+
+// toProtoChainEntitlement converts a models.ChainEntitlement to its wire
+// form. A nil UsesRemaining/ExpiresAt (not applicable to this entitlement's
+// kind) is sent as the zero value, matching models.ChainEntitlement's own
+// omitempty JSON behavior.
+func toProtoChainEntitlement(e models.ChainEntitlement) *pb.ChainEntitlement {
+	out := &pb.ChainEntitlement{
+		Id:          e.ID,
+		ChainId:     int64(e.ChainID),
+		Kind:        e.Kind,
+		GrantedTxId: e.GrantedTxID,
+	}
+	if e.UsesRemaining != nil {
+		out.UsesRemaining = int32(*e.UsesRemaining)
+	}
+	if e.ExpiresAt != nil {
+		out.ExpiresAtUnix = e.ExpiresAt.Unix()
+	}
+	return out
+}
+
+// toProtoReceipt converts a service.IssuedReceipt to its wire form, or nil
+// if issuing the receipt failed (the caller's purchase/access still
+// succeeded; see service.issueReceiptBestEffort).
+func toProtoReceipt(issued *service.IssuedReceipt) *pb.Receipt {
+	if issued == nil {
+		return nil
+	}
+	r := issued.Receipt
+	return &pb.Receipt{
+		TxId:          r.TxID,
+		BuyerId:       int64(r.BuyerID),
+		SellerId:      int64(r.SellerID),
+		ItemType:      r.ItemType,
+		ItemId:        int64(r.ItemID),
+		Price:         r.Price,
+		TimestampUnix: r.Timestamp.Unix(),
+		Nonce:         r.Nonce,
+		Hash:          issued.Hash,
+		Signature:     issued.Signature,
+	}
+}
+
+func fromPublishMemoryRequest(req *pb.PublishMemoryRequest) (service.PublishMemoryInput, error) {
+	var kvCacheData map[string]interface{}
+	if req.KvCacheDataJson != "" {
+		if err := json.Unmarshal([]byte(req.KvCacheDataJson), &kvCacheData); err != nil {
+			return service.PublishMemoryInput{}, fmt.Errorf("invalid kv_cache_data_json: %w", err)
+		}
+	}
+	return service.PublishMemoryInput{
+		SellerID:    int(req.SellerId),
+		MemoryType:  req.MemoryType,
+		KVCacheData: kvCacheData,
+		Price:       req.Price,
+	}, nil
+}
+
+func fromPublishReasoningChainRequest(req *pb.PublishReasoningChainRequest) (service.PublishReasoningChainInput, error) {
+	var snapshot map[string]interface{}
+	if req.KvCacheSnapshotJson != "" {
+		if err := json.Unmarshal([]byte(req.KvCacheSnapshotJson), &snapshot); err != nil {
+			return service.PublishReasoningChainInput{}, fmt.Errorf("invalid kv_cache_snapshot_json: %w", err)
+		}
+	}
+	return service.PublishReasoningChainInput{
+		CreatorID:       int(req.CreatorId),
+		Category:        req.Category,
+		KVCacheSnapshot: snapshot,
+		PricePerUse:     req.PricePerUse,
+	}, nil
+}
@@ -0,0 +1,213 @@
+// Package grpc exposes the memory-exchange service.Service methods as the
+// Marketplace gRPC service, for clients that want a typed RPC surface
+// instead of the JSON REST API in internal/handlers.
+//
+// The message/service types this file depends on (pb.PublishMemoryRequest,
+// pb.MarketplaceServer, ...) are generated from proto/marketplace.proto via:
+//
+//	protoc --go_out=. --go-grpc_out=. \
+//	  --go_opt=module=github.com/awareness/memory-exchange \
+//	  --go-grpc_opt=module=github.com/awareness/memory-exchange \
+//	  proto/marketplace.proto
+//
+// and are not hand-edited.
+package grpc
+
+import (
+	"context"
+
+	"github.com/awareness/memory-exchange/internal/api/grpc/pb"
+	"github.com/awareness/memory-exchange/internal/service"
+)
+
+// Server implements pb.MarketplaceServer on top of a service.Service.
+type Server struct {
+	pb.UnimplementedMarketplaceServer
+	svc *service.Service
+}
+
+// NewServer creates a Marketplace gRPC server backed by svc.
+func NewServer(svc *service.Service) *Server {
+	return &Server{svc: svc}
+}
+
+func (s *Server) PublishMemory(ctx context.Context, req *pb.PublishMemoryRequest) (*pb.PublishMemoryResponse, error) {
+	in, err := fromPublishMemoryRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	result, err := s.svc.PublishMemory(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.PublishMemoryResponse{MemoryId: result.MemoryID}, nil
+}
+
+func (s *Server) ReserveMemory(ctx context.Context, req *pb.ReserveMemoryRequest) (*pb.ReserveMemoryResponse, error) {
+	result, err := s.svc.ReserveMemory(ctx, service.ReserveMemoryInput{
+		BuyerID:  int(req.BuyerId),
+		MemoryID: int(req.MemoryId),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &pb.ReserveMemoryResponse{
+		ReservationId: result.ReservationID,
+		Memory:        toProtoMemory(result.Memory),
+		ExpiresAtUnix: result.ExpiresAt.Unix(),
+	}, nil
+}
+
+func (s *Server) ConfirmMemory(ctx context.Context, req *pb.ConfirmMemoryRequest) (*pb.ConfirmMemoryResponse, error) {
+	result, err := s.svc.ConfirmMemory(ctx, service.ConfirmMemoryInput{
+		BuyerID:       int(req.BuyerId),
+		ReservationID: req.ReservationId,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &pb.ConfirmMemoryResponse{
+		ReservationId: result.ReservationID,
+		Memory:        toProtoMemory(result.Memory),
+		Receipt:       toProtoReceipt(result.Receipt),
+	}, nil
+}
+
+func (s *Server) RefundMemory(ctx context.Context, req *pb.RefundMemoryRequest) (*pb.RefundMemoryResponse, error) {
+	result, err := s.svc.RefundMemory(ctx, service.RefundMemoryInput{
+		BuyerID:       int(req.BuyerId),
+		ReservationID: req.ReservationId,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &pb.RefundMemoryResponse{
+		ReservationId: result.ReservationID,
+	}, nil
+}
+
+// BrowseMemories streams the same memories BrowseMemories would return as
+// a single page over REST, one Memory message at a time.
+func (s *Server) BrowseMemories(req *pb.BrowseMemoriesRequest, stream pb.Marketplace_BrowseMemoriesServer) error {
+	result, err := s.svc.BrowseMemories(stream.Context(), service.BrowseMemoriesInput{
+		MemoryType: req.MemoryType,
+		MinPrice:   req.MinPrice,
+		MaxPrice:   req.MaxPrice,
+		Limit:      int(req.Limit),
+		Offset:     int(req.Offset),
+		SimilarTo:  req.SimilarTo,
+	})
+	if err != nil {
+		return err
+	}
+	for _, m := range result.Memories {
+		if err := stream.Send(toProtoMemory(m)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Server) GetMyHistory(ctx context.Context, req *pb.GetMyHistoryRequest) (*pb.GetMyHistoryResponse, error) {
+	result, err := s.svc.GetMyHistory(ctx, service.GetMyHistoryInput{
+		UserID: int(req.UserId),
+		Limit:  int(req.Limit),
+		Offset: int(req.Offset),
+	})
+	if err != nil {
+		return nil, err
+	}
+	transactions := make([]*pb.Memory, len(result.Transactions))
+	for i, m := range result.Transactions {
+		transactions[i] = toProtoMemory(m)
+	}
+	return &pb.GetMyHistoryResponse{Transactions: transactions}, nil
+}
+
+func (s *Server) PublishReasoningChain(ctx context.Context, req *pb.PublishReasoningChainRequest) (*pb.PublishReasoningChainResponse, error) {
+	in, err := fromPublishReasoningChainRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	result, err := s.svc.PublishReasoningChain(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.PublishReasoningChainResponse{ChainId: result.ChainID}, nil
+}
+
+func (s *Server) UseReasoningChain(ctx context.Context, req *pb.UseReasoningChainRequest) (*pb.UseReasoningChainResponse, error) {
+	result, err := s.svc.UseReasoningChain(ctx, service.UseReasoningChainInput{
+		UserID:  int(req.UserId),
+		ChainID: int(req.ChainId),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &pb.UseReasoningChainResponse{
+		Chain:   toProtoReasoningChain(result.Chain),
+		Receipt: toProtoReceipt(result.Receipt),
+	}, nil
+}
+
+func (s *Server) PurchaseReasoningChain(ctx context.Context, req *pb.PurchaseReasoningChainRequest) (*pb.PurchaseReasoningChainResponse, error) {
+	result, err := s.svc.PurchaseReasoningChain(ctx, service.PurchaseReasoningChainInput{
+		BuyerID: int(req.BuyerId),
+		ChainID: int(req.ChainId),
+		Kind:    req.Kind,
+		Uses:    int(req.Uses),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &pb.PurchaseReasoningChainResponse{
+		EntitlementId: result.EntitlementID,
+		Chain:         toProtoReasoningChain(result.Chain),
+		Kind:          result.Kind,
+		Receipt:       toProtoReceipt(result.Receipt),
+	}, nil
+}
+
+func (s *Server) GetMyEntitlements(ctx context.Context, req *pb.GetMyEntitlementsRequest) (*pb.GetMyEntitlementsResponse, error) {
+	result, err := s.svc.GetMyEntitlements(ctx, service.GetMyEntitlementsInput{UserID: int(req.UserId)})
+	if err != nil {
+		return nil, err
+	}
+	entitlements := make([]*pb.ChainEntitlement, len(result.Entitlements))
+	for i, e := range result.Entitlements {
+		entitlements[i] = toProtoChainEntitlement(e)
+	}
+	return &pb.GetMyEntitlementsResponse{Entitlements: entitlements}, nil
+}
+
+func (s *Server) BrowseReasoningChains(ctx context.Context, req *pb.BrowseReasoningChainsRequest) (*pb.BrowseReasoningChainsResponse, error) {
+	result, err := s.svc.BrowseReasoningChains(ctx, service.BrowseReasoningChainsInput{
+		ChainType: req.ChainType,
+		MinPrice:  req.MinPrice,
+		MaxPrice:  req.MaxPrice,
+		Limit:     int(req.Limit),
+		Offset:    int(req.Offset),
+	})
+	if err != nil {
+		return nil, err
+	}
+	chains := make([]*pb.ReasoningChain, len(result.Chains))
+	for i, c := range result.Chains {
+		chains[i] = toProtoReasoningChain(c)
+	}
+	return &pb.BrowseReasoningChainsResponse{Chains: chains}, nil
+}
+
+func (s *Server) GetStats(ctx context.Context, _ *pb.GetStatsRequest) (*pb.GetStatsResponse, error) {
+	stats, err := s.svc.GetStats(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.GetStatsResponse{
+		TotalMemories:        int32(stats.TotalMemories),
+		AvailableMemories:    int32(stats.AvailableMemories),
+		TotalTransactions:    int32(stats.TotalTransactions),
+		TotalReasoningChains: int32(stats.TotalReasoningChains),
+		TotalVolume:          stats.TotalVolume,
+	}, nil
+}
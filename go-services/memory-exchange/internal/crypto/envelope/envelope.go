@@ -0,0 +1,123 @@
+// Package envelope end-to-end encrypts relay payloads so that relay nodes,
+// which only store and forward sealed bytes, never see the plaintext
+// KV-cache content. It uses X25519 for key agreement and
+// ChaCha20-Poly1305 for authenticated encryption, with HKDF-SHA256
+// deriving the cipher key from the shared secret.
+package envelope
+
+import (
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/rand"
+	"crypto/sha256"
+	"fmt"
+	"io"
+
+	"golang.org/x/crypto/chacha20poly1305"
+	"golang.org/x/crypto/hkdf"
+)
+
+// hkdfInfo binds derived keys to this package so they can't be confused
+// with keys derived for an unrelated purpose from the same ECDH secret.
+const hkdfInfo = "awareness-market/relay-envelope/v1"
+
+// KeyPair is an X25519 key pair. PublicKey is what a buyer publishes
+// (e.g. as users.buyer_pubkey) for sellers/relays to encrypt to.
+type KeyPair struct {
+	Private *ecdh.PrivateKey
+	Public  *ecdh.PublicKey
+}
+
+// GenerateKeyPair creates a new X25519 key pair.
+func GenerateKeyPair() (*KeyPair, error) {
+	priv, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate X25519 key pair: %w", err)
+	}
+	return &KeyPair{Private: priv, Public: priv.PublicKey()}, nil
+}
+
+// Envelope is the sealed payload a relay stores and forwards. It carries
+// the sender's ephemeral public key so the recipient can derive the same
+// shared secret without any prior key exchange.
+type Envelope struct {
+	EphemeralPubKey []byte `json:"ephemeral_pubkey"`
+	Nonce           []byte `json:"nonce"`
+	Ciphertext      []byte `json:"ciphertext"`
+}
+
+// Seal encrypts plaintext for recipientPub using an ephemeral X25519 key,
+// so the caller never needs to hold a long-term private key.
+func Seal(plaintext []byte, recipientPub []byte) (*Envelope, error) {
+	recipientKey, err := ecdh.X25519().NewPublicKey(recipientPub)
+	if err != nil {
+		return nil, fmt.Errorf("invalid recipient public key: %w", err)
+	}
+
+	ephemeral, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate ephemeral key: %w", err)
+	}
+
+	secret, err := ephemeral.ECDH(recipientKey)
+	if err != nil {
+		return nil, fmt.Errorf("ECDH failed: %w", err)
+	}
+
+	aead, err := newAEAD(secret)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	ciphertext := aead.Seal(nil, nonce, plaintext, nil)
+
+	return &Envelope{
+		EphemeralPubKey: ephemeral.PublicKey().Bytes(),
+		Nonce:           nonce,
+		Ciphertext:      ciphertext,
+	}, nil
+}
+
+// Open decrypts an Envelope using the recipient's long-term X25519 private
+// key, recovering the shared secret from the sender's ephemeral public key.
+func Open(env *Envelope, recipientPriv *ecdh.PrivateKey) ([]byte, error) {
+	ephemeralKey, err := ecdh.X25519().NewPublicKey(env.EphemeralPubKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ephemeral public key: %w", err)
+	}
+
+	secret, err := recipientPriv.ECDH(ephemeralKey)
+	if err != nil {
+		return nil, fmt.Errorf("ECDH failed: %w", err)
+	}
+
+	aead, err := newAEAD(secret)
+	if err != nil {
+		return nil, err
+	}
+
+	plaintext, err := aead.Open(nil, env.Nonce, env.Ciphertext, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt envelope: %w", err)
+	}
+	return plaintext, nil
+}
+
+// newAEAD derives a ChaCha20-Poly1305 key from an ECDH shared secret via
+// HKDF-SHA256.
+func newAEAD(secret []byte) (cipher.AEAD, error) {
+	key := make([]byte, chacha20poly1305.KeySize)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, secret, nil, []byte(hkdfInfo)), key); err != nil {
+		return nil, fmt.Errorf("failed to derive key: %w", err)
+	}
+	aead, err := chacha20poly1305.New(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create AEAD: %w", err)
+	}
+	return aead, nil
+}
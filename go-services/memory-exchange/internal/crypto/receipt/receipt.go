@@ -0,0 +1,108 @@
+// Package receipt issues and verifies signed proof-of-purchase receipts for
+// the memory exchange, analogous to how Wormhole guardian nodes sign query
+// responses: a receipt is a small, deterministically-serialized claim
+// ("buyer X holds item Y") hashed with SHA-256 and signed with the
+// service's Ed25519 key, so a downstream service can accept it as proof
+// without re-querying this service's database.
+package receipt
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// Receipt is the claim a signature attests to: BuyerID holds ItemID
+// (purchased for Price as part of transaction TxID). Nonce makes two
+// receipts for the same (TxID, BuyerID, ItemID) distinguishable if one is
+// ever reissued.
+type Receipt struct {
+	TxID      string    `json:"tx_id"`
+	BuyerID   int       `json:"buyer_id"`
+	SellerID  int       `json:"seller_id"`
+	ItemType  string    `json:"item_type"`
+	ItemID    int       `json:"item_id"`
+	Price     float64   `json:"price"`
+	Timestamp time.Time `json:"timestamp"`
+	Nonce     string    `json:"nonce"`
+}
+
+// ErrAPISecretUnset is returned by any operation needing the signing key
+// when API_SECRET isn't set. There is deliberately no hardcoded fallback
+// here: unlike the shared-secret auth other services in this repo derive
+// from API_SECRET, this key's public half is published at
+// /.well-known/marketplace-pubkey for anyone to fetch, so a fallback
+// literal would let anyone derive the private key too and forge receipts.
+var ErrAPISecretUnset = errors.New("receipt: API_SECRET is not set; refusing to sign or verify with a derivable key")
+
+var (
+	keyOnce sync.Once
+	keyErr  error
+	priv    ed25519.PrivateKey
+	pub     ed25519.PublicKey
+)
+
+// keyPair lazily derives the service's Ed25519 signing key from API_SECRET.
+// Deriving rather than generating at startup means every replica signs with
+// the same key without needing a shared key file.
+func keyPair() (ed25519.PrivateKey, ed25519.PublicKey, error) {
+	keyOnce.Do(func() {
+		secret := os.Getenv("API_SECRET")
+		if secret == "" {
+			keyErr = ErrAPISecretUnset
+			return
+		}
+		seed := sha256.Sum256([]byte("awareness-market/memory-exchange/receipt-signing-key/v1:" + secret))
+		priv = ed25519.NewKeyFromSeed(seed[:])
+		pub = priv.Public().(ed25519.PublicKey)
+	})
+	return priv, pub, keyErr
+}
+
+// PublicKey returns the service's Ed25519 public key, the same key
+// published at GET /.well-known/marketplace-pubkey.
+func PublicKey() (ed25519.PublicKey, error) {
+	_, pub, err := keyPair()
+	return pub, err
+}
+
+// Hash returns the SHA-256 hash of r's canonical (field-order-stable) JSON
+// encoding, the value Sign actually signs and Verify checks against.
+func Hash(r Receipt) ([]byte, error) {
+	encoded, err := json.Marshal(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize receipt: %w", err)
+	}
+	sum := sha256.Sum256(encoded)
+	return sum[:], nil
+}
+
+// Sign hashes r and signs the hash with the service's Ed25519 key,
+// returning both so the caller can persist or return them without
+// recomputing.
+func Sign(r Receipt) (hash []byte, signature []byte, err error) {
+	hash, err = Hash(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	priv, _, err := keyPair()
+	if err != nil {
+		return nil, nil, err
+	}
+	return hash, ed25519.Sign(priv, hash), nil
+}
+
+// Verify reports whether signature is a valid Ed25519 signature over hash
+// under the service's published public key.
+func Verify(hash, signature []byte) (bool, error) {
+	_, pub, err := keyPair()
+	if err != nil {
+		return false, err
+	}
+	return ed25519.Verify(pub, hash, signature), nil
+}
@@ -1,16 +1,32 @@
 package handlers
 
 import (
-	"database/sql"
-	"encoding/json"
+	"encoding/hex"
+	"errors"
 	"net/http"
+	"strconv"
 
-	"github.com/awareness/memory-exchange/internal/database"
+	"github.com/awareness/memory-exchange/internal/crypto/receipt"
 	"github.com/awareness/memory-exchange/internal/models"
+	"github.com/awareness/memory-exchange/internal/search"
+	"github.com/awareness/memory-exchange/internal/service"
 
 	"github.com/gin-gonic/gin"
 )
 
+// Svc is the shared service.Service instance every handler in this package
+// delegates business logic to, set once at startup via Init. The REST
+// handlers below are thin adapters: translate the Gin request into a plain
+// Go input struct, call Svc, translate the result (or error) back into an
+// APIResponse. internal/api/grpc adapts the same Svc for gRPC clients.
+var Svc *service.Service
+
+// Init wires the shared Service instance other handlers in this package
+// delegate to. Call it once at startup before the router handles requests.
+func Init(svc *service.Service) {
+	Svc = svc
+}
+
 // PublishMemory godoc
 // @Summary Publish a new memory to the exchange
 // @Description Publish a KV-Cache memory for trading on the marketplace
@@ -43,58 +59,122 @@ func PublishMemory(c *gin.Context) {
 		return
 	}
 
-	// Serialize KV cache data
-	kvCacheJSON, err := json.Marshal(req.KVCacheData)
+	sellerID, _ := userID.(int)
+	result, err := Svc.PublishMemory(c.Request.Context(), service.PublishMemoryInput{
+		SellerID:    sellerID,
+		MemoryType:  req.MemoryType,
+		KVCacheData: req.KVCacheData,
+		Price:       req.Price,
+	})
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, models.APIResponse{
 			Success: false,
-			Error:   stringPtr("Failed to serialize KV cache data"),
+			Error:   stringPtr(err.Error()),
 		})
 		return
 	}
 
-	// Insert into database
-	query := `
-		INSERT INTO memory_exchanges (
-			seller_id, memory_type, kv_cache_data, price, status, created_at
-		) VALUES (?, ?, ?, ?, 'pending', NOW())
-	`
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Data: map[string]interface{}{
+			"memory_id": result.MemoryID,
+			"message":   "Memory published successfully",
+		},
+	})
+}
 
-	result, err := database.DB.Exec(query, userID, req.MemoryType, string(kvCacheJSON), req.Price)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, models.APIResponse{
+// ReserveMemory godoc
+// @Summary Reserve a memory from the exchange
+// @Description Place a hold on a memory and its price, pending confirmation or refund
+// @Tags memory
+// @Accept json
+// @Produce json
+// @Param request body models.ReserveMemoryRequest true "Reservation details"
+// @Success 200 {object} models.APIResponse "Memory reserved successfully"
+// @Failure 400 {object} models.APIResponse "Invalid request, memory unavailable, or insufficient balance"
+// @Failure 401 {object} models.APIResponse "Unauthorized"
+// @Failure 404 {object} models.APIResponse "Memory not found"
+// @Failure 500 {object} models.APIResponse "Internal server error"
+// @Security ApiKeyAuth
+// @Router /memory/reserve [post]
+func ReserveMemory(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.APIResponse{
+			Success: false,
+			Error:   stringPtr("User not authenticated"),
+		})
+		return
+	}
+
+	var req models.ReserveMemoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
 			Success: false,
-			Error:   stringPtr("Failed to publish memory: " + err.Error()),
+			Error:   stringPtr("Invalid request body: " + err.Error()),
 		})
 		return
 	}
 
-	memoryID, _ := result.LastInsertId()
+	buyerID, _ := userID.(int)
+	result, err := Svc.ReserveMemory(c.Request.Context(), service.ReserveMemoryInput{
+		BuyerID:  buyerID,
+		MemoryID: req.MemoryID,
+	})
+	switch {
+	case errors.Is(err, service.ErrMemoryNotFound):
+		c.JSON(http.StatusNotFound, models.APIResponse{
+			Success: false,
+			Error:   stringPtr("Memory not found"),
+		})
+		return
+	case errors.Is(err, service.ErrMemoryUnavailable):
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Error:   stringPtr("Memory is not available for purchase"),
+		})
+		return
+	case errors.Is(err, service.ErrInsufficientBalance):
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Error:   stringPtr("Insufficient balance"),
+		})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Error:   stringPtr(err.Error()),
+		})
+		return
+	}
 
 	c.JSON(http.StatusOK, models.APIResponse{
 		Success: true,
 		Data: map[string]interface{}{
-			"memory_id": memoryID,
-			"message":   "Memory published successfully",
+			"reservation_id": result.ReservationID,
+			"memory":         result.Memory,
+			"expires_at":     result.ExpiresAt,
+			"message":        "Memory reserved successfully",
 		},
 	})
 }
 
-// PurchaseMemory godoc
-// @Summary Purchase a memory from the exchange
-// @Description Purchase access to a KV-Cache memory
+// ConfirmMemory godoc
+// @Summary Confirm a memory reservation
+// @Description Settle a reservation, releasing the escrowed funds to the seller
 // @Tags memory
 // @Accept json
 // @Produce json
-// @Param request body models.PurchaseMemoryRequest true "Purchase details"
-// @Success 200 {object} models.APIResponse "Memory purchased successfully"
-// @Failure 400 {object} models.APIResponse "Invalid request"
+// @Param request body models.ConfirmMemoryRequest true "Confirmation details"
+// @Success 200 {object} models.APIResponse "Memory purchase confirmed"
+// @Failure 400 {object} models.APIResponse "Invalid request, reservation not pending, or expired"
 // @Failure 401 {object} models.APIResponse "Unauthorized"
-// @Failure 404 {object} models.APIResponse "Memory not found"
+// @Failure 403 {object} models.APIResponse "Reservation does not belong to this buyer"
+// @Failure 404 {object} models.APIResponse "Reservation not found"
 // @Failure 500 {object} models.APIResponse "Internal server error"
 // @Security ApiKeyAuth
-// @Router /memory/purchase [post]
-func PurchaseMemory(c *gin.Context) {
+// @Router /memory/confirm [post]
+func ConfirmMemory(c *gin.Context) {
 	userID, exists := c.Get("user_id")
 	if !exists {
 		c.JSON(http.StatusUnauthorized, models.APIResponse{
@@ -104,7 +184,7 @@ func PurchaseMemory(c *gin.Context) {
 		return
 	}
 
-	var req models.PurchaseMemoryRequest
+	var req models.ConfirmMemoryRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, models.APIResponse{
 			Success: false,
@@ -113,71 +193,126 @@ func PurchaseMemory(c *gin.Context) {
 		return
 	}
 
-	// Check if memory exists and is available
-	var memory models.MemoryExchange
-	query := `
-		SELECT id, seller_id, memory_type, kv_cache_data, price, status
-		FROM memory_exchanges
-		WHERE id = ?
-	`
-
-	err := database.DB.QueryRow(query, req.MemoryID).Scan(
-		&memory.ID,
-		&memory.SellerID,
-		&memory.MemoryType,
-		&memory.KVCacheData,
-		&memory.Price,
-		&memory.Status,
-	)
-
-	if err == sql.ErrNoRows {
+	buyerID, _ := userID.(int)
+	result, err := Svc.ConfirmMemory(c.Request.Context(), service.ConfirmMemoryInput{
+		BuyerID:       buyerID,
+		ReservationID: req.ReservationID,
+	})
+	switch {
+	case errors.Is(err, service.ErrReservationNotFound):
 		c.JSON(http.StatusNotFound, models.APIResponse{
 			Success: false,
-			Error:   stringPtr("Memory not found"),
+			Error:   stringPtr("Reservation not found"),
+		})
+		return
+	case errors.Is(err, service.ErrReservationNotOwned):
+		c.JSON(http.StatusForbidden, models.APIResponse{
+			Success: false,
+			Error:   stringPtr("Reservation does not belong to this buyer"),
+		})
+		return
+	case errors.Is(err, service.ErrReservationNotPending):
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Error:   stringPtr("Reservation is not pending"),
+		})
+		return
+	case errors.Is(err, service.ErrReservationExpired):
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Error:   stringPtr("Reservation has expired"),
+		})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Error:   stringPtr(err.Error()),
 		})
 		return
 	}
 
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, models.APIResponse{
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Data: map[string]interface{}{
+			"reservation_id": result.ReservationID,
+			"memory":         result.Memory,
+			"receipt":        receiptData(result.Receipt),
+			"message":        "Memory purchase confirmed",
+		},
+	})
+}
+
+// RefundMemory godoc
+// @Summary Refund a memory reservation
+// @Description Cancel a pending reservation and return the held funds to the buyer
+// @Tags memory
+// @Accept json
+// @Produce json
+// @Param request body models.RefundMemoryRequest true "Refund details"
+// @Success 200 {object} models.APIResponse "Reservation refunded"
+// @Failure 400 {object} models.APIResponse "Invalid request or reservation not pending"
+// @Failure 401 {object} models.APIResponse "Unauthorized"
+// @Failure 403 {object} models.APIResponse "Reservation does not belong to this buyer"
+// @Failure 404 {object} models.APIResponse "Reservation not found"
+// @Failure 500 {object} models.APIResponse "Internal server error"
+// @Security ApiKeyAuth
+// @Router /memory/refund [post]
+func RefundMemory(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.APIResponse{
 			Success: false,
-			Error:   stringPtr("Database error: " + err.Error()),
+			Error:   stringPtr("User not authenticated"),
 		})
 		return
 	}
 
-	if memory.Status != "available" {
+	var req models.RefundMemoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, models.APIResponse{
 			Success: false,
-			Error:   stringPtr("Memory is not available for purchase"),
+			Error:   stringPtr("Invalid request body: " + err.Error()),
 		})
 		return
 	}
 
-	// Create transaction record
-	txQuery := `
-		INSERT INTO memory_exchanges (
-			seller_id, buyer_id, memory_type, kv_cache_data, price, status, created_at
-		) VALUES (?, ?, ?, ?, ?, 'completed', NOW())
-	`
-
-	result, err := database.DB.Exec(txQuery, memory.SellerID, userID, memory.MemoryType, memory.KVCacheData, memory.Price)
-	if err != nil {
+	buyerID, _ := userID.(int)
+	result, err := Svc.RefundMemory(c.Request.Context(), service.RefundMemoryInput{
+		BuyerID:       buyerID,
+		ReservationID: req.ReservationID,
+	})
+	switch {
+	case errors.Is(err, service.ErrReservationNotFound):
+		c.JSON(http.StatusNotFound, models.APIResponse{
+			Success: false,
+			Error:   stringPtr("Reservation not found"),
+		})
+		return
+	case errors.Is(err, service.ErrReservationNotOwned):
+		c.JSON(http.StatusForbidden, models.APIResponse{
+			Success: false,
+			Error:   stringPtr("Reservation does not belong to this buyer"),
+		})
+		return
+	case errors.Is(err, service.ErrReservationNotPending):
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Error:   stringPtr("Reservation is not pending"),
+		})
+		return
+	case err != nil:
 		c.JSON(http.StatusInternalServerError, models.APIResponse{
 			Success: false,
-			Error:   stringPtr("Failed to create transaction: " + err.Error()),
+			Error:   stringPtr(err.Error()),
 		})
 		return
 	}
 
-	transactionID, _ := result.LastInsertId()
-
 	c.JSON(http.StatusOK, models.APIResponse{
 		Success: true,
 		Data: map[string]interface{}{
-			"transaction_id": transactionID,
-			"memory":         memory,
-			"message":        "Memory purchased successfully",
+			"reservation_id": result.ReservationID,
+			"message":        "Reservation refunded",
 		},
 	})
 }
@@ -198,70 +333,69 @@ func PurchaseMemory(c *gin.Context) {
 // @Security ApiKeyAuth
 // @Router /memory/browse [get]
 func BrowseMemories(c *gin.Context) {
-	memoryType := c.Query("memory_type")
-	minPrice := c.Query("min_price")
-	maxPrice := c.Query("max_price")
-	limit := c.DefaultQuery("limit", "20")
-	offset := c.DefaultQuery("offset", "0")
-
-	query := `
-		SELECT id, seller_id, memory_type, price, status, created_at
-		FROM memory_exchanges
-		WHERE status = 'available'
-	`
-
-	var args []interface{}
-
-	if memoryType != "" {
-		query += " AND memory_type = ?"
-		args = append(args, memoryType)
-	}
-
-	if minPrice != "" {
-		query += " AND price >= ?"
-		args = append(args, minPrice)
-	}
-
-	if maxPrice != "" {
-		query += " AND price <= ?"
-		args = append(args, maxPrice)
+	result, err := Svc.BrowseMemories(c.Request.Context(), service.BrowseMemoriesInput{
+		MemoryType: c.Query("memory_type"),
+		MinPrice:   queryFloat(c, "min_price"),
+		MaxPrice:   queryFloat(c, "max_price"),
+		Limit:      queryIntDefault(c, "limit", 20),
+		Offset:     queryIntDefault(c, "offset", 0),
+		SimilarTo:  c.Query("similar_to"),
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Error:   stringPtr(err.Error()),
+		})
+		return
 	}
 
-	query += " ORDER BY created_at DESC LIMIT ? OFFSET ?"
-	args = append(args, limit, offset)
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Data: map[string]interface{}{
+			"memories": result.Memories,
+			"count":    len(result.Memories),
+		},
+	})
+}
 
-	rows, err := database.DB.Query(query, args...)
+// SearchCatalog godoc
+// @Summary Search the memory/reasoning-chain catalog
+// @Description BM25 text search over memory type/category, backed by Elasticsearch/OpenSearch (falls back to MySQL LIKE when SEARCH_ENGINE=mysql or the search engine is unavailable)
+// @Tags memory
+// @Accept json
+// @Produce json
+// @Param q query string false "Free-text query matched against memory_type/category"
+// @Param kind query string false "Restrict to memory or reasoning_chain"
+// @Param min_price query number false "Minimum price filter"
+// @Param max_price query number false "Maximum price filter"
+// @Param k query int false "Number of results to return" default(20)
+// @Success 200 {object} models.APIResponse "Search results"
+// @Failure 500 {object} models.APIResponse "Internal server error"
+// @Security ApiKeyAuth
+// @Router /search [get]
+func SearchCatalog(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("k", "20"))
+
+	docs, err := search.Default.Search(c.Request.Context(), search.Request{
+		Kind:     c.Query("kind"),
+		Query:    c.Query("q"),
+		MinPrice: queryFloat(c, "min_price"),
+		MaxPrice: queryFloat(c, "max_price"),
+		Limit:    limit,
+	})
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, models.APIResponse{
 			Success: false,
-			Error:   stringPtr("Database error: " + err.Error()),
+			Error:   stringPtr("Search failed: " + err.Error()),
 		})
 		return
 	}
-	defer rows.Close()
-
-	var memories []models.MemoryExchange
-	for rows.Next() {
-		var memory models.MemoryExchange
-		err := rows.Scan(
-			&memory.ID,
-			&memory.SellerID,
-			&memory.MemoryType,
-			&memory.Price,
-			&memory.Status,
-			&memory.CreatedAt,
-		)
-		if err != nil {
-			continue
-		}
-		memories = append(memories, memory)
-	}
 
 	c.JSON(http.StatusOK, models.APIResponse{
 		Success: true,
 		Data: map[string]interface{}{
-			"memories": memories,
-			"count":    len(memories),
+			"results": docs,
+			"count":   len(docs),
 		},
 	})
 }
@@ -289,50 +423,25 @@ func GetMyHistory(c *gin.Context) {
 		return
 	}
 
-	limit := c.DefaultQuery("limit", "20")
-	offset := c.DefaultQuery("offset", "0")
-
-	query := `
-		SELECT id, seller_id, buyer_id, memory_type, price, status, created_at
-		FROM memory_exchanges
-		WHERE seller_id = ? OR buyer_id = ?
-		ORDER BY created_at DESC
-		LIMIT ? OFFSET ?
-	`
-
-	rows, err := database.DB.Query(query, userID, userID, limit, offset)
+	uid, _ := userID.(int)
+	result, err := Svc.GetMyHistory(c.Request.Context(), service.GetMyHistoryInput{
+		UserID: uid,
+		Limit:  queryIntDefault(c, "limit", 20),
+		Offset: queryIntDefault(c, "offset", 0),
+	})
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, models.APIResponse{
 			Success: false,
-			Error:   stringPtr("Database error: " + err.Error()),
+			Error:   stringPtr(err.Error()),
 		})
 		return
 	}
-	defer rows.Close()
-
-	var transactions []models.MemoryExchange
-	for rows.Next() {
-		var tx models.MemoryExchange
-		err := rows.Scan(
-			&tx.ID,
-			&tx.SellerID,
-			&tx.BuyerID,
-			&tx.MemoryType,
-			&tx.Price,
-			&tx.Status,
-			&tx.CreatedAt,
-		)
-		if err != nil {
-			continue
-		}
-		transactions = append(transactions, tx)
-	}
 
 	c.JSON(http.StatusOK, models.APIResponse{
 		Success: true,
 		Data: map[string]interface{}{
-			"transactions": transactions,
-			"count":        len(transactions),
+			"transactions": result.Transactions,
+			"count":        len(result.Transactions),
 		},
 	})
 }
@@ -369,38 +478,25 @@ func PublishReasoningChain(c *gin.Context) {
 		return
 	}
 
-	// Serialize chain data
-	chainDataJSON, err := json.Marshal(req.KVCacheSnapshot)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, models.APIResponse{
-			Success: false,
-			Error:   stringPtr("Failed to serialize chain data"),
-		})
-		return
-	}
-
-	// Insert into database
-	query := `
-		INSERT INTO reasoning_chains (
-			creator_id, chain_type, chain_data, price, status, created_at
-		) VALUES (?, ?, ?, ?, 'available', NOW())
-	`
-
-	result, err := database.DB.Exec(query, userID, req.Category, string(chainDataJSON), req.PricePerUse)
+	creatorID, _ := userID.(int)
+	result, err := Svc.PublishReasoningChain(c.Request.Context(), service.PublishReasoningChainInput{
+		CreatorID:       creatorID,
+		Category:        req.Category,
+		KVCacheSnapshot: req.KVCacheSnapshot,
+		PricePerUse:     req.PricePerUse,
+	})
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, models.APIResponse{
 			Success: false,
-			Error:   stringPtr("Failed to publish reasoning chain: " + err.Error()),
+			Error:   stringPtr(err.Error()),
 		})
 		return
 	}
 
-	chainID, _ := result.LastInsertId()
-
 	c.JSON(http.StatusOK, models.APIResponse{
 		Success: true,
 		Data: map[string]interface{}{
-			"chain_id": chainID,
+			"chain_id": result.ChainID,
 			"message":  "Reasoning chain published successfully",
 		},
 	})
@@ -440,51 +536,146 @@ func UseReasoningChain(c *gin.Context) {
 		return
 	}
 
-	// Check if chain exists and user has access
-	var chain models.ReasoningChain
-	query := `
-		SELECT id, creator_id, category, kv_cache_snapshot, price_per_use, status
-		FROM reasoning_chains
-		WHERE id = ?
-	`
+	uid, _ := userID.(int)
+	result, err := Svc.UseReasoningChain(c.Request.Context(), service.UseReasoningChainInput{
+		UserID:  uid,
+		ChainID: req.ChainID,
+	})
+	switch {
+	case errors.Is(err, service.ErrChainNotFound):
+		c.JSON(http.StatusNotFound, models.APIResponse{
+			Success: false,
+			Error:   stringPtr("Reasoning chain not found"),
+		})
+		return
+	case errors.Is(err, service.ErrChainAccessDenied):
+		c.JSON(http.StatusForbidden, models.APIResponse{
+			Success: false,
+			Error:   stringPtr("Access denied: You must purchase this reasoning chain"),
+		})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Error:   stringPtr(err.Error()),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Data: map[string]interface{}{
+			"chain":   result.Chain,
+			"receipt": receiptData(result.Receipt),
+		},
+	})
+}
 
-	var kvCacheSnapshot sql.NullString
-	err := database.DB.QueryRow(query, req.ChainID).Scan(
-		&chain.ID,
-		&chain.CreatorID,
-		&chain.Category,
-		&kvCacheSnapshot,
-		&chain.PricePerUse,
-		&chain.Status,
-	)
+// PurchaseReasoningChain godoc
+// @Summary Purchase access to a reasoning chain
+// @Description Grant the caller a chain_entitlements record (per-use, subscription, or unlimited), debiting the price from their balance the same way memory purchases are escrowed
+// @Tags reasoning-chain
+// @Accept json
+// @Produce json
+// @Param request body models.PurchaseReasoningChainRequest true "Purchase details"
+// @Success 200 {object} models.APIResponse "Entitlement granted"
+// @Failure 400 {object} models.APIResponse "Invalid request or insufficient balance"
+// @Failure 401 {object} models.APIResponse "Unauthorized"
+// @Failure 404 {object} models.APIResponse "Chain not found"
+// @Failure 500 {object} models.APIResponse "Internal server error"
+// @Security ApiKeyAuth
+// @Router /reasoning-chain/purchase [post]
+func PurchaseReasoningChain(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.APIResponse{
+			Success: false,
+			Error:   stringPtr("User not authenticated"),
+		})
+		return
+	}
 
-	if kvCacheSnapshot.Valid {
-		chain.KVCacheSnapshot = &kvCacheSnapshot.String
+	var req models.PurchaseReasoningChainRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Error:   stringPtr("Invalid request body: " + err.Error()),
+		})
+		return
 	}
 
-	if err == sql.ErrNoRows {
+	buyerID, _ := userID.(int)
+	result, err := Svc.PurchaseReasoningChain(c.Request.Context(), service.PurchaseReasoningChainInput{
+		BuyerID: buyerID,
+		ChainID: req.ChainID,
+		Kind:    req.Kind,
+		Uses:    req.Uses,
+	})
+	switch {
+	case errors.Is(err, service.ErrChainNotFound):
 		c.JSON(http.StatusNotFound, models.APIResponse{
 			Success: false,
 			Error:   stringPtr("Reasoning chain not found"),
 		})
 		return
+	case errors.Is(err, service.ErrInvalidEntitlementKind):
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Error:   stringPtr("Invalid entitlement kind"),
+		})
+		return
+	case errors.Is(err, service.ErrInsufficientBalance):
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Error:   stringPtr("Insufficient balance"),
+		})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Error:   stringPtr(err.Error()),
+		})
+		return
 	}
 
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, models.APIResponse{
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Data: map[string]interface{}{
+			"entitlement_id": result.EntitlementID,
+			"chain":          result.Chain,
+			"kind":           result.Kind,
+			"receipt":        receiptData(result.Receipt),
+			"message":        "Reasoning chain entitlement granted",
+		},
+	})
+}
+
+// GetMyEntitlements godoc
+// @Summary List the caller's reasoning-chain entitlements
+// @Description Get every chain_entitlements grant the authenticated user has purchased, including expired or exhausted ones
+// @Tags reasoning-chain
+// @Produce json
+// @Success 200 {object} models.APIResponse "Entitlements"
+// @Failure 401 {object} models.APIResponse "Unauthorized"
+// @Failure 500 {object} models.APIResponse "Internal server error"
+// @Security ApiKeyAuth
+// @Router /reasoning-chain/my-entitlements [get]
+func GetMyEntitlements(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.APIResponse{
 			Success: false,
-			Error:   stringPtr("Database error: " + err.Error()),
+			Error:   stringPtr("User not authenticated"),
 		})
 		return
 	}
 
-	// Check access (owner or purchased)
-	userIDInt, _ := userID.(int)
-	if chain.CreatorID != userIDInt {
-		// TODO: Check if user has purchased access
-		c.JSON(http.StatusForbidden, models.APIResponse{
+	uid, _ := userID.(int)
+	result, err := Svc.GetMyEntitlements(c.Request.Context(), service.GetMyEntitlementsInput{UserID: uid})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
 			Success: false,
-			Error:   stringPtr("Access denied: You must purchase this reasoning chain"),
+			Error:   stringPtr(err.Error()),
 		})
 		return
 	}
@@ -492,11 +683,26 @@ func UseReasoningChain(c *gin.Context) {
 	c.JSON(http.StatusOK, models.APIResponse{
 		Success: true,
 		Data: map[string]interface{}{
-			"chain": chain,
+			"entitlements": result.Entitlements,
+			"count":        len(result.Entitlements),
 		},
 	})
 }
 
+// receiptData shapes a service.IssuedReceipt for an APIResponse, or nil if
+// issuing it failed (the caller's underlying purchase/access still
+// succeeded).
+func receiptData(issued *service.IssuedReceipt) interface{} {
+	if issued == nil {
+		return nil
+	}
+	return map[string]interface{}{
+		"receipt":   issued.Receipt,
+		"hash":      issued.Hash,
+		"signature": issued.Signature,
+	}
+}
+
 // BrowseReasoningChains godoc
 // @Summary Browse reasoning chains
 // @Description Get a list of available reasoning chains with optional filtering
@@ -513,70 +719,26 @@ func UseReasoningChain(c *gin.Context) {
 // @Security ApiKeyAuth
 // @Router /reasoning-chain/browse [get]
 func BrowseReasoningChains(c *gin.Context) {
-	chainType := c.Query("chain_type")
-	minPrice := c.Query("min_price")
-	maxPrice := c.Query("max_price")
-	limit := c.DefaultQuery("limit", "20")
-	offset := c.DefaultQuery("offset", "0")
-
-	query := `
-		SELECT id, creator_id, category, price_per_use, status, created_at
-		FROM reasoning_chains
-		WHERE status = 'active'
-	`
-
-	var args []interface{}
-
-	if chainType != "" {
-		query += " AND category = ?"
-		args = append(args, chainType)
-	}
-
-	if minPrice != "" {
-		query += " AND price >= ?"
-		args = append(args, minPrice)
-	}
-
-	if maxPrice != "" {
-		query += " AND price <= ?"
-		args = append(args, maxPrice)
-	}
-
-	query += " ORDER BY created_at DESC LIMIT ? OFFSET ?"
-	args = append(args, limit, offset)
-
-	rows, err := database.DB.Query(query, args...)
+	result, err := Svc.BrowseReasoningChains(c.Request.Context(), service.BrowseReasoningChainsInput{
+		ChainType: c.Query("chain_type"),
+		MinPrice:  queryFloat(c, "min_price"),
+		MaxPrice:  queryFloat(c, "max_price"),
+		Limit:     queryIntDefault(c, "limit", 20),
+		Offset:    queryIntDefault(c, "offset", 0),
+	})
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, models.APIResponse{
 			Success: false,
-			Error:   stringPtr("Database error: " + err.Error()),
+			Error:   stringPtr(err.Error()),
 		})
 		return
 	}
-	defer rows.Close()
-
-	var chains []models.ReasoningChain
-	for rows.Next() {
-		var chain models.ReasoningChain
-		err := rows.Scan(
-			&chain.ID,
-			&chain.CreatorID,
-			&chain.Category,
-			&chain.PricePerUse,
-			&chain.Status,
-			&chain.CreatedAt,
-		)
-		if err != nil {
-			continue
-		}
-		chains = append(chains, chain)
-	}
 
 	c.JSON(http.StatusOK, models.APIResponse{
 		Success: true,
 		Data: map[string]interface{}{
-			"chains": chains,
-			"count":  len(chains),
+			"chains": result.Chains,
+			"count":  len(result.Chains),
 		},
 	})
 }
@@ -592,35 +754,155 @@ func BrowseReasoningChains(c *gin.Context) {
 // @Security ApiKeyAuth
 // @Router /stats [get]
 func GetStats(c *gin.Context) {
-	var stats struct {
-		TotalMemories      int     `json:"total_memories"`
-		AvailableMemories  int     `json:"available_memories"`
-		TotalTransactions  int     `json:"total_transactions"`
-		TotalReasoningChains int   `json:"total_reasoning_chains"`
-		TotalVolume        float64 `json:"total_volume"`
+	stats, err := Svc.GetStats(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Error:   stringPtr(err.Error()),
+		})
+		return
 	}
 
-	// Count total memories
-	database.DB.QueryRow("SELECT COUNT(*) FROM memory_exchanges").Scan(&stats.TotalMemories)
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Data:    stats,
+	})
+}
 
-	// Count available memories
-	database.DB.QueryRow("SELECT COUNT(*) FROM memory_exchanges WHERE status = 'available'").Scan(&stats.AvailableMemories)
+// VerifyReceipt godoc
+// @Summary Verify a purchase receipt
+// @Description Re-derive a receipt's signed hash and verify it against the service's published Ed25519 public key
+// @Tags memory
+// @Produce json
+// @Param tx_id path string true "Transaction ID the receipt was issued for"
+// @Success 200 {object} models.APIResponse "Verification result"
+// @Failure 404 {object} models.APIResponse "Receipt not found"
+// @Failure 500 {object} models.APIResponse "Internal server error"
+// @Security ApiKeyAuth
+// @Router /memory/receipts/{tx_id}/verify [get]
+func VerifyReceipt(c *gin.Context) {
+	result, err := Svc.VerifyReceipt(c.Request.Context(), c.Param("tx_id"))
+	switch {
+	case errors.Is(err, service.ErrReceiptNotFound):
+		c.JSON(http.StatusNotFound, models.APIResponse{
+			Success: false,
+			Error:   stringPtr("Receipt not found"),
+		})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Error:   stringPtr(err.Error()),
+		})
+		return
+	}
 
-	// Count completed transactions
-	database.DB.QueryRow("SELECT COUNT(*) FROM memory_exchanges WHERE status = 'completed'").Scan(&stats.TotalTransactions)
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Data: map[string]interface{}{
+			"tx_id": c.Param("tx_id"),
+			"valid": result.Valid,
+		},
+	})
+}
 
-	// Count reasoning chains
-	database.DB.QueryRow("SELECT COUNT(*) FROM reasoning_chains").Scan(&stats.TotalReasoningChains)
+// WellKnownPublicKey godoc
+// @Summary Get the receipt-signing public key
+// @Description Return the Ed25519 public key receipts are signed with, hex-encoded, for downstream services to verify receipts offline
+// @Tags system
+// @Produce json
+// @Success 200 {object} models.APIResponse "Public key"
+// @Failure 500 {object} models.APIResponse "Internal server error"
+// @Router /.well-known/marketplace-pubkey [get]
+func WellKnownPublicKey(c *gin.Context) {
+	pub, err := receipt.PublicKey()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Error:   stringPtr(err.Error()),
+		})
+		return
+	}
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Data: map[string]interface{}{
+			"algorithm":  "ed25519",
+			"public_key": hex.EncodeToString(pub),
+		},
+	})
+}
+
+// verifyExternalReceiptInput is the request body for VerifyExternalReceipt:
+// the receipt claim plus the hex-encoded signature a buyer or downstream
+// service was handed alongside it.
+type verifyExternalReceiptInput struct {
+	Receipt   receipt.Receipt `json:"receipt" binding:"required"`
+	Signature string          `json:"signature" binding:"required"`
+}
+
+// VerifyExternalReceipt godoc
+// @Summary Verify a caller-presented receipt offline
+// @Description Verify a Receipt and signature a caller presents directly (not looked up by tx_id), purely against the service's published Ed25519 public key - no database access, so it works for receipts this instance never issued or has since lost.
+// @Tags memory
+// @Accept json
+// @Produce json
+// @Param body body verifyExternalReceiptInput true "Receipt and signature to verify"
+// @Success 200 {object} models.APIResponse "Verification result"
+// @Failure 400 {object} models.APIResponse "Invalid request body"
+// @Failure 500 {object} models.APIResponse "Internal server error"
+// @Router /.well-known/verify-receipt [post]
+func VerifyExternalReceipt(c *gin.Context) {
+	var input verifyExternalReceiptInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Error:   stringPtr(err.Error()),
+		})
+		return
+	}
 
-	// Calculate total volume
-	database.DB.QueryRow("SELECT COALESCE(SUM(price), 0) FROM memory_exchanges WHERE status = 'completed'").Scan(&stats.TotalVolume)
+	result, err := Svc.VerifyExternalReceipt(input.Receipt, input.Signature)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Error:   stringPtr(err.Error()),
+		})
+		return
+	}
 
 	c.JSON(http.StatusOK, models.APIResponse{
 		Success: true,
-		Data:    stats,
+		Data: map[string]interface{}{
+			"tx_id": input.Receipt.TxID,
+			"valid": result.Valid,
+		},
 	})
 }
 
+// queryFloat parses query param name as a float64, returning nil if it's
+// absent or not a valid number.
+func queryFloat(c *gin.Context, name string) *float64 {
+	v := c.Query(name)
+	if v == "" {
+		return nil
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		return nil
+	}
+	return &f
+}
+
+// queryIntDefault parses query param name as an int, falling back to
+// def if it's absent or not a valid integer.
+func queryIntDefault(c *gin.Context, name string, def int) int {
+	v, err := strconv.Atoi(c.Query(name))
+	if err != nil {
+		return def
+	}
+	return v
+}
+
 // Helper function
 func stringPtr(s string) *string {
 	return &s
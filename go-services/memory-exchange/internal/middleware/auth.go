@@ -10,7 +10,9 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-// APIKeyAuth middleware validates API keys
+// APIKeyAuth middleware validates API keys, enforces their per-key token-
+// bucket rate limit and monthly quota, and makes their granted scopes
+// available to RequireScope.
 func APIKeyAuth() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Extract API key from Authorization header
@@ -86,18 +88,41 @@ func APIKeyAuth() gin.HandlerFunc {
 			return
 		}
 
-		// Update last used timestamp
-		go func() {
-			updateQuery := `
-				UPDATE api_keys
-				SET last_used_at = NOW()
-				WHERE key_hash = SHA2(?, 256)
-			`
-			database.DB.Exec(updateQuery, apiKey)
-		}()
-
-		// Set user ID in context
+		keyHash := hashAPIKey(apiKey)
+		limits, err := getKeyLimits(apiKey, keyHash)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"success": false,
+				"error":   "Database error",
+			})
+			c.Abort()
+			return
+		}
+
+		setRateLimitHeaders(c, limits)
+		if !limits.limiter.Allow() {
+			c.JSON(http.StatusTooManyRequests, gin.H{
+				"success": false,
+				"error":   "Rate limit exceeded",
+			})
+			c.Abort()
+			return
+		}
+		if !checkAndIncrementQuota(keyHash, limits.quotaMonthly) {
+			c.JSON(http.StatusTooManyRequests, gin.H{
+				"success": false,
+				"error":   "Monthly quota exceeded",
+			})
+			c.Abort()
+			return
+		}
+
+		// Batch the last_used_at write instead of doing it inline per request
+		flusher.touch(apiKey)
+
+		// Set user ID and scopes in context
 		c.Set("user_id", userID)
+		c.Set("scopes", limits.scopes)
 		c.Next()
 	}
 }
@@ -0,0 +1,290 @@
+package middleware
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/awareness/memory-exchange/internal/database"
+	"github.com/gin-gonic/gin"
+	"golang.org/x/time/rate"
+)
+
+// keyCacheSize bounds how many API keys' rate limiters/quotas/scopes are
+// held in memory at once. Keys beyond this are evicted least-recently-used,
+// so a hot set of callers never gets pushed out by a burst of one-off keys.
+const keyCacheSize = 10000
+
+// keyLimits is the per-API-key state APIKeyAuth enforces on every request.
+type keyLimits struct {
+	limiter      *rate.Limiter
+	quotaMonthly int
+	scopes       map[string]bool
+}
+
+// keyLimitCache is a mutex-guarded LRU cache of keyLimits keyed by API key
+// hash, so most requests don't need a round trip to load rate_limit_rpm,
+// quota_monthly, and scopes before enforcing them.
+type keyLimitCache struct {
+	mu      sync.Mutex
+	entries map[string]*list.Element // keyHash -> element holding *cacheEntry
+	order   *list.List               // front = most recently used
+}
+
+type cacheEntry struct {
+	keyHash string
+	limits  *keyLimits
+}
+
+func newKeyLimitCache() *keyLimitCache {
+	return &keyLimitCache{
+		entries: make(map[string]*list.Element),
+		order:   list.New(),
+	}
+}
+
+func (c *keyLimitCache) get(keyHash string) (*keyLimits, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.entries[keyHash]
+	if !ok {
+		return nil, false
+	}
+	c.order.MoveToFront(el)
+	return el.Value.(*cacheEntry).limits, true
+}
+
+func (c *keyLimitCache) put(keyHash string, limits *keyLimits) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.entries[keyHash]; ok {
+		el.Value.(*cacheEntry).limits = limits
+		c.order.MoveToFront(el)
+		return
+	}
+
+	el := c.order.PushFront(&cacheEntry{keyHash: keyHash, limits: limits})
+	c.entries[keyHash] = el
+
+	if c.order.Len() > keyCacheSize {
+		oldest := c.order.Back()
+		if oldest != nil {
+			c.order.Remove(oldest)
+			delete(c.entries, oldest.Value.(*cacheEntry).keyHash)
+		}
+	}
+}
+
+var limitCache = newKeyLimitCache()
+
+// hashAPIKey matches the SHA2(?, 256) MySQL expects in key_hash columns, so
+// the in-memory cache key lines up with what's stored in the database.
+func hashAPIKey(apiKey string) string {
+	sum := sha256.Sum256([]byte(apiKey))
+	return hex.EncodeToString(sum[:])
+}
+
+// loadKeyLimits reads rate_limit_rpm, quota_monthly, and scopes for apiKey
+// and builds the keyLimits the cache stores. Scopes are stored as a
+// comma-separated string.
+func loadKeyLimits(apiKey string) (*keyLimits, error) {
+	var rpm int
+	var quotaMonthly int
+	var scopesCSV string
+
+	query := `
+		SELECT rate_limit_rpm, quota_monthly, scopes
+		FROM api_keys
+		WHERE key_hash = SHA2(?, 256)
+		LIMIT 1
+	`
+	if err := database.DB.QueryRow(query, apiKey).Scan(&rpm, &quotaMonthly, &scopesCSV); err != nil {
+		return nil, err
+	}
+
+	if rpm <= 0 {
+		rpm = 60
+	}
+	scopes := make(map[string]bool)
+	for _, s := range strings.Split(scopesCSV, ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			scopes[s] = true
+		}
+	}
+
+	return &keyLimits{
+		limiter:      rate.NewLimiter(rate.Limit(float64(rpm)/60.0), rpm),
+		quotaMonthly: quotaMonthly,
+		scopes:       scopes,
+	}, nil
+}
+
+func getKeyLimits(apiKey, keyHash string) (*keyLimits, error) {
+	if limits, ok := limitCache.get(keyHash); ok {
+		return limits, nil
+	}
+	limits, err := loadKeyLimits(apiKey)
+	if err != nil {
+		return nil, err
+	}
+	limitCache.put(keyHash, limits)
+	return limits, nil
+}
+
+// monthlyUsage tracks requests served this calendar month per API key, reset
+// whenever the month rolls over. It's in-process only (not shared across
+// replicas) - good enough to stop a single runaway caller without adding a
+// round trip to the database on every request.
+type monthlyUsage struct {
+	count int
+	month time.Time // first of the month this count belongs to
+}
+
+var (
+	usageMu sync.Mutex
+	usage   = map[string]*monthlyUsage{}
+)
+
+// checkAndIncrementQuota returns false if apiKey has used up its monthly
+// quota (quotaMonthly <= 0 means unlimited).
+func checkAndIncrementQuota(keyHash string, quotaMonthly int) bool {
+	if quotaMonthly <= 0 {
+		return true
+	}
+
+	now := time.Now()
+	monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+
+	usageMu.Lock()
+	defer usageMu.Unlock()
+
+	u, ok := usage[keyHash]
+	if !ok || !u.month.Equal(monthStart) {
+		u = &monthlyUsage{month: monthStart}
+		usage[keyHash] = u
+	}
+	if u.count >= quotaMonthly {
+		return false
+	}
+	u.count++
+	return true
+}
+
+// lastUsedFlusher batches last_used_at writes instead of issuing one UPDATE
+// per request: keys touched since the previous flush are collected and
+// written in a single query every flushInterval, or as soon as
+// flushBatchSize keys have queued up.
+type lastUsedFlusher struct {
+	keys  chan string
+	pend  map[string]bool
+	mu    sync.Mutex
+}
+
+const (
+	flushInterval  = 5 * time.Second
+	flushBatchSize = 1000
+)
+
+var flusher = &lastUsedFlusher{
+	keys: make(chan string, flushBatchSize*2),
+	pend: make(map[string]bool),
+}
+
+func init() {
+	go flusher.run()
+}
+
+func (f *lastUsedFlusher) touch(apiKey string) {
+	select {
+	case f.keys <- apiKey:
+	default:
+		// Flusher is backed up; dropping a last_used_at update is harmless.
+	}
+}
+
+func (f *lastUsedFlusher) run() {
+	ticker := time.NewTicker(flushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case key := <-f.keys:
+			f.mu.Lock()
+			f.pend[key] = true
+			shouldFlush := len(f.pend) >= flushBatchSize
+			f.mu.Unlock()
+			if shouldFlush {
+				f.flush()
+			}
+		case <-ticker.C:
+			f.flush()
+		}
+	}
+}
+
+func (f *lastUsedFlusher) flush() {
+	f.mu.Lock()
+	if len(f.pend) == 0 {
+		f.mu.Unlock()
+		return
+	}
+	keys := make([]string, 0, len(f.pend))
+	for k := range f.pend {
+		keys = append(keys, k)
+	}
+	f.pend = make(map[string]bool)
+	f.mu.Unlock()
+
+	placeholders := make([]string, len(keys))
+	args := make([]interface{}, len(keys))
+	for i, k := range keys {
+		placeholders[i] = "SHA2(?, 256)"
+		args[i] = k
+	}
+	query := fmt.Sprintf(
+		"UPDATE api_keys SET last_used_at = NOW() WHERE key_hash IN (%s)",
+		strings.Join(placeholders, ", "),
+	)
+	database.DB.Exec(query, args...)
+}
+
+// setRateLimitHeaders emits the standard X-RateLimit-* response headers so
+// clients can back off before they get a 429.
+func setRateLimitHeaders(c *gin.Context, limits *keyLimits) {
+	now := time.Now()
+	remaining := int(limits.limiter.TokensAt(now))
+	if remaining < 0 {
+		remaining = 0
+	}
+
+	c.Header("X-RateLimit-Limit", strconv.Itoa(int(limits.limiter.Limit()*60)))
+	c.Header("X-RateLimit-Remaining", strconv.Itoa(remaining))
+	c.Header("X-RateLimit-Reset", strconv.FormatInt(now.Add(time.Minute).Unix(), 10))
+}
+
+// RequireScope builds middleware that 403s unless the caller's API key (set
+// on the context by APIKeyAuth) has the given scope, e.g. "discover:read",
+// "wmatrix:write", or "purchase:execute".
+func RequireScope(scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scopes, _ := c.Get("scopes")
+		granted, _ := scopes.(map[string]bool)
+		if !granted[scope] {
+			c.JSON(http.StatusForbidden, gin.H{
+				"success": false,
+				"error":   fmt.Sprintf("API key is missing required scope %q", scope),
+			})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
@@ -19,7 +19,7 @@ type MemoryExchange struct {
 	Price            float64   `json:"price"`
 	QualityScore     *float64  `json:"quality_score,omitempty"`
 	AlignmentQuality *string   `json:"alignment_quality,omitempty"` // JSON
-	Status           string    `json:"status"` // pending, completed, failed
+	Status           string    `json:"status"` // pending, available, reserved, released (paid/refunded are escrow_transactions states)
 	CreatedAt        time.Time `json:"created_at"`
 }
 
@@ -54,12 +54,25 @@ type PublishMemoryRequest struct {
 	Description  *string                `json:"description,omitempty"`
 }
 
-// PurchaseMemoryRequest represents the request to purchase a memory
-type PurchaseMemoryRequest struct {
+// ReserveMemoryRequest represents the request to place a memory on hold
+// ahead of payment.
+type ReserveMemoryRequest struct {
 	MemoryID    int    `json:"memory_id" binding:"required"`
 	TargetModel string `json:"target_model" binding:"required"`
 }
 
+// ConfirmMemoryRequest represents the request to settle a reservation and
+// release the escrowed funds to the seller.
+type ConfirmMemoryRequest struct {
+	ReservationID int64 `json:"reservation_id" binding:"required"`
+}
+
+// RefundMemoryRequest represents the request to cancel a reservation and
+// return the held funds to the buyer.
+type RefundMemoryRequest struct {
+	ReservationID int64 `json:"reservation_id" binding:"required"`
+}
+
 // BrowseMemoriesRequest represents the request to browse memories
 type BrowseMemoriesRequest struct {
 	MemoryType *string  `form:"memory_type,omitempty"`
@@ -90,6 +103,27 @@ type UseReasoningChainRequest struct {
 	TargetModel string                 `json:"target_model" binding:"required"`
 }
 
+// PurchaseReasoningChainRequest represents the request to buy access to a
+// reasoning chain. Kind selects the shape of the entitlement granted:
+// per_use (consumes Uses, default 1), subscription (time-boxed), or
+// unlimited.
+type PurchaseReasoningChainRequest struct {
+	ChainID int    `json:"chain_id" binding:"required"`
+	Kind    string `json:"kind" binding:"required,oneof=per_use subscription unlimited"`
+	Uses    int    `json:"uses,omitempty"`
+}
+
+// ChainEntitlement represents a user's access grant to a reasoning chain:
+// a fixed number of uses, a time-boxed subscription, or unlimited access.
+type ChainEntitlement struct {
+	ID            int64      `json:"id"`
+	ChainID       int        `json:"chain_id"`
+	Kind          string     `json:"kind"` // per_use, subscription, unlimited
+	UsesRemaining *int       `json:"uses_remaining,omitempty"`
+	ExpiresAt     *time.Time `json:"expires_at,omitempty"`
+	GrantedTxID   string     `json:"granted_tx_id"`
+}
+
 // BrowseReasoningChainsRequest represents the request to browse reasoning chains
 type BrowseReasoningChainsRequest struct {
 	Category    *string  `form:"category,omitempty"`
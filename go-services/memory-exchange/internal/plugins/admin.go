@@ -0,0 +1,161 @@
+package plugins
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/awareness/memory-exchange/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+// pluginBindingRequest is the body for POST/PUT /admin/plugins/*route.
+type pluginBindingRequest struct {
+	PluginName string `json:"plugin_name" binding:"required"`
+	Priority   int    `json:"priority"`
+	Config     Config `json:"config"`
+	Enabled    *bool  `json:"enabled"`
+}
+
+// routeParam extracts the route key from a gin wildcard param registered
+// as "/admin/plugins/*route" (e.g. "/POST:/api/v1/memory/publish"), since
+// a route key itself contains slashes and can't be a plain gin :param.
+func routeParam(c *gin.Context) string {
+	return strings.TrimPrefix(c.Param("route"), "/")
+}
+
+// ListRoutePlugins godoc
+// @Summary List plugins bound to a route
+// @Description Returns every plugin binding configured for the given route
+// @Tags admin
+// @Produce json
+// @Param route path string true "Route key, e.g. POST:/api/v1/memory/publish"
+// @Success 200 {object} models.APIResponse
+// @Failure 500 {object} models.APIResponse
+// @Security ApiKeyAuth
+// @Router /admin/plugins/{route} [get]
+func (m *Manager) ListRoutePlugins(c *gin.Context) {
+	route := routeParam(c)
+
+	rows, err := m.db.Query(`
+		SELECT plugin_name, priority, config, enabled
+		FROM route_plugins
+		WHERE route = ?
+		ORDER BY priority ASC
+	`, route)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{Success: false, Error: strPtr("Failed to load route plugins: " + err.Error())})
+		return
+	}
+	defer rows.Close()
+
+	bindings := []pluginBindingRequest{}
+	for rows.Next() {
+		var b pluginBindingRequest
+		var cfg []byte
+		var enabled bool
+		if err := rows.Scan(&b.PluginName, &b.Priority, &cfg, &enabled); err != nil {
+			continue
+		}
+		b.Config = cfg
+		b.Enabled = &enabled
+		bindings = append(bindings, b)
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{Success: true, Data: bindings})
+}
+
+// UpsertRoutePlugin godoc
+// @Summary Bind or update a plugin on a route
+// @Description Validates the config against the plugin's schema, persists it, and hot-reloads the compiled chain
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param route path string true "Route key, e.g. POST:/api/v1/memory/publish"
+// @Param request body pluginBindingRequest true "Plugin binding"
+// @Success 200 {object} models.APIResponse
+// @Failure 400 {object} models.APIResponse
+// @Failure 500 {object} models.APIResponse
+// @Security ApiKeyAuth
+// @Router /admin/plugins/{route} [post]
+func (m *Manager) UpsertRoutePlugin(c *gin.Context) {
+	route := routeParam(c)
+
+	var req pluginBindingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{Success: false, Error: strPtr("Invalid request body: " + err.Error())})
+		return
+	}
+
+	plugin, ok := Lookup(req.PluginName)
+	if !ok {
+		c.JSON(http.StatusBadRequest, models.APIResponse{Success: false, Error: strPtr("Unknown plugin: " + req.PluginName)})
+		return
+	}
+	if err := plugin.Schema().Validate(req.Config); err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{Success: false, Error: strPtr("Invalid plugin config: " + err.Error())})
+		return
+	}
+
+	priority := req.Priority
+	if priority == 0 {
+		priority = plugin.Priority()
+	}
+	enabled := true
+	if req.Enabled != nil {
+		enabled = *req.Enabled
+	}
+
+	_, err := m.db.Exec(`
+		INSERT INTO route_plugins (route, plugin_name, priority, config, enabled)
+		VALUES (?, ?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE priority = VALUES(priority), config = VALUES(config), enabled = VALUES(enabled)
+	`, route, req.PluginName, priority, []byte(req.Config), enabled)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{Success: false, Error: strPtr("Failed to persist plugin binding: " + err.Error())})
+		return
+	}
+
+	if err := m.Reload(); err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{Success: false, Error: strPtr("Saved but failed to activate: " + err.Error())})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{Success: true, Data: map[string]interface{}{"message": "Plugin binding activated"}})
+}
+
+// DeleteRoutePlugin godoc
+// @Summary Unbind a plugin from a route
+// @Description Removes the binding and hot-reloads the compiled chain
+// @Tags admin
+// @Produce json
+// @Param route path string true "Route key, e.g. POST:/api/v1/memory/publish"
+// @Param plugin_name query string true "Plugin to remove"
+// @Success 200 {object} models.APIResponse
+// @Failure 400 {object} models.APIResponse
+// @Failure 500 {object} models.APIResponse
+// @Security ApiKeyAuth
+// @Router /admin/plugins/{route} [delete]
+func (m *Manager) DeleteRoutePlugin(c *gin.Context) {
+	route := routeParam(c)
+	pluginName := c.Query("plugin_name")
+	if pluginName == "" {
+		c.JSON(http.StatusBadRequest, models.APIResponse{Success: false, Error: strPtr("plugin_name query param is required")})
+		return
+	}
+
+	if _, err := m.db.Exec(`DELETE FROM route_plugins WHERE route = ? AND plugin_name = ?`, route, pluginName); err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{Success: false, Error: strPtr("Failed to remove plugin binding: " + err.Error())})
+		return
+	}
+
+	if err := m.Reload(); err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{Success: false, Error: strPtr("Removed but failed to reload: " + err.Error())})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{Success: true, Data: map[string]interface{}{"message": "Plugin binding removed"}})
+}
+
+func strPtr(s string) *string {
+	return &s
+}
@@ -0,0 +1,104 @@
+package plugins
+
+import (
+	"database/sql"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/awareness/memory-exchange/internal/database"
+	"github.com/gin-gonic/gin"
+)
+
+func init() {
+	Register(&apiKeyAuthPlugin{})
+}
+
+// apiKeyAuthPlugin is the plugin-chain counterpart of
+// middleware.APIKeyAuth(): it reads and looks up the key the same way, but
+// it does not enforce the per-key rate limit, monthly quota or scopes that
+// the middleware does, and some of its error messages differ.
+type apiKeyAuthPlugin struct{}
+
+func (apiKeyAuthPlugin) Name() string   { return "api-key-auth" }
+func (apiKeyAuthPlugin) Priority() int  { return 10 }
+func (apiKeyAuthPlugin) Schema() Schema { return Schema{} }
+
+func (apiKeyAuthPlugin) Handler(cfg Config) (gin.HandlerFunc, error) {
+	return func(c *gin.Context) {
+		authHeader := c.GetHeader("Authorization")
+		if authHeader == "" {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"success": false,
+				"error":   "Missing Authorization header",
+			})
+			c.Abort()
+			return
+		}
+
+		parts := strings.SplitN(authHeader, " ", 2)
+		if len(parts) != 2 || parts[0] != "Bearer" {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"success": false,
+				"error":   "Invalid Authorization header format. Use: Bearer <api_key>",
+			})
+			c.Abort()
+			return
+		}
+
+		apiKey := parts[1]
+
+		var userID int
+		var isActive bool
+		var expiresAt sql.NullTime
+
+		query := `
+			SELECT user_id, is_active, expires_at
+			FROM api_keys
+			WHERE key_hash = SHA2(?, 256)
+			LIMIT 1
+		`
+		err := database.DB.QueryRow(query, apiKey).Scan(&userID, &isActive, &expiresAt)
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"success": false,
+				"error":   "Invalid API key",
+			})
+			c.Abort()
+			return
+		} else if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"success": false,
+				"error":   "Failed to validate API key",
+			})
+			c.Abort()
+			return
+		}
+
+		if !isActive {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"success": false,
+				"error":   "API key is inactive",
+			})
+			c.Abort()
+			return
+		}
+
+		if expiresAt.Valid && expiresAt.Time.Before(time.Now()) {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"success": false,
+				"error":   "API key has expired",
+			})
+			c.Abort()
+			return
+		}
+
+		go func() {
+			database.DB.Exec(`UPDATE api_keys SET last_used_at = NOW() WHERE key_hash = SHA2(?, 256)`, apiKey)
+		}()
+
+		c.Set("user_id", userID)
+		c.Set("api_key", apiKey)
+		c.Next()
+	}, nil
+}
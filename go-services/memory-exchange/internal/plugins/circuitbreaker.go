@@ -0,0 +1,110 @@
+package plugins
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func init() {
+	Register(&circuitBreakerPlugin{})
+}
+
+// circuitBreakerPlugin trips after a run of consecutive 5xx responses on
+// a route and rejects further requests for a cooldown window rather than
+// letting them queue up behind a failing handler or downstream
+// dependency. Mirrors the client-side breaker in
+// mcp-gateway/pkg/client, applied to an inbound route instead of an
+// outbound call.
+type circuitBreakerPlugin struct{}
+
+type circuitBreakerConfig struct {
+	FailureThreshold int `json:"failure_threshold"`
+	CooldownSeconds  int `json:"cooldown_seconds"`
+}
+
+func (circuitBreakerPlugin) Name() string  { return "circuit-breaker" }
+func (circuitBreakerPlugin) Priority() int { return 15 }
+func (circuitBreakerPlugin) Schema() Schema {
+	return Schema{
+		Required: []string{"failure_threshold", "cooldown_seconds"},
+		Properties: map[string]PropertySpec{
+			"failure_threshold": {Type: "number"},
+			"cooldown_seconds":  {Type: "number"},
+		},
+	}
+}
+
+// routeBreaker is the per-route breaker state. Bound once per compiled
+// chain entry, so all requests for a route share one breaker.
+type routeBreaker struct {
+	failureThreshold int
+	cooldown         time.Duration
+
+	mu       sync.Mutex
+	failures int
+	openedAt time.Time
+	open     bool
+}
+
+func (circuitBreakerPlugin) Handler(cfg Config) (gin.HandlerFunc, error) {
+	var conf circuitBreakerConfig
+	if err := json.Unmarshal(cfg, &conf); err != nil {
+		return nil, err
+	}
+
+	b := &routeBreaker{
+		failureThreshold: conf.FailureThreshold,
+		cooldown:         time.Duration(conf.CooldownSeconds) * time.Second,
+	}
+
+	return func(c *gin.Context) {
+		if b.blocked() {
+			c.JSON(http.StatusServiceUnavailable, gin.H{
+				"success": false,
+				"error":   "Service temporarily unavailable, try again shortly",
+			})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+		b.record(c.Writer.Status() >= http.StatusInternalServerError)
+	}, nil
+}
+
+func (b *routeBreaker) blocked() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if !b.open {
+		return false
+	}
+	if time.Since(b.openedAt) >= b.cooldown {
+		// Half-open: let one trial request through; record() decides
+		// whether to close the breaker or keep it open.
+		b.openedAt = time.Now()
+		return false
+	}
+	return true
+}
+
+func (b *routeBreaker) record(failed bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if !failed {
+		b.failures = 0
+		b.open = false
+		return
+	}
+
+	b.failures++
+	if b.failures >= b.failureThreshold {
+		b.open = true
+		b.openedAt = time.Now()
+	}
+}
@@ -0,0 +1,48 @@
+package plugins
+
+import (
+	"encoding/json"
+
+	"github.com/gin-gonic/gin"
+)
+
+func init() {
+	Register(&corsPlugin{})
+}
+
+// corsPlugin replaces the hard-coded middleware.CORS() call with a
+// per-route, configurable equivalent.
+type corsPlugin struct{}
+
+type corsConfig struct {
+	AllowOrigin string `json:"allow_origin"`
+}
+
+func (corsPlugin) Name() string   { return "cors" }
+func (corsPlugin) Priority() int  { return 0 }
+func (corsPlugin) Schema() Schema { return Schema{} }
+
+func (corsPlugin) Handler(cfg Config) (gin.HandlerFunc, error) {
+	conf := corsConfig{AllowOrigin: "*"}
+	if len(cfg) > 0 {
+		if err := json.Unmarshal(cfg, &conf); err != nil {
+			return nil, err
+		}
+		if conf.AllowOrigin == "" {
+			conf.AllowOrigin = "*"
+		}
+	}
+
+	return func(c *gin.Context) {
+		c.Writer.Header().Set("Access-Control-Allow-Origin", conf.AllowOrigin)
+		c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
+		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With")
+		c.Writer.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT, DELETE, PATCH")
+
+		if c.Request.Method == "OPTIONS" {
+			c.AbortWithStatus(204)
+			return
+		}
+		c.Next()
+	}, nil
+}
@@ -0,0 +1,104 @@
+// Package plugins implements an APISIX-style per-route plugin chain:
+// small, independently configurable middleware units (auth, rate limiting,
+// transforms, logging) that are composed per route instead of being
+// hard-coded into router setup. See Register and Manager.
+package plugins
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Config is a plugin's route-specific configuration, stored and validated
+// as raw JSON so the plugin framework never needs to know a given
+// plugin's concrete config shape.
+type Config = json.RawMessage
+
+// Plugin is a self-contained piece of route middleware. Plugins register
+// themselves with the Registry from an init() func, the same pattern Go's
+// database/sql drivers use.
+type Plugin interface {
+	// Name uniquely identifies the plugin, e.g. "api-key-auth".
+	Name() string
+	// Schema describes the shape cfg must satisfy; Manager validates
+	// against it before a config is persisted or activated.
+	Schema() Schema
+	// Priority controls chain ordering: lower runs first. Auth plugins use
+	// low numbers so they run before the circuit breaker and rate limiting;
+	// request-transform (5) runs ahead of them.
+	Priority() int
+	// Handler builds a gin.HandlerFunc bound to cfg. Called once per
+	// compiled chain, not per request, so cfg parsing happens up front.
+	Handler(cfg Config) (gin.HandlerFunc, error)
+}
+
+// Schema is a minimal JSON-schema-like description of a plugin's config,
+// covering the "object with required/typed properties" shape every
+// built-in plugin needs. It intentionally doesn't implement the full JSON
+// Schema spec.
+type Schema struct {
+	Required   []string                `json:"required,omitempty"`
+	Properties map[string]PropertySpec `json:"properties,omitempty"`
+}
+
+// PropertySpec describes one config property's expected JSON type.
+type PropertySpec struct {
+	Type string `json:"type"` // "string", "number", "boolean", "array", "object"
+}
+
+// Validate checks that raw is a JSON object satisfying s: all Required
+// keys present and every known property's value matching its declared
+// type. Unknown properties are ignored rather than rejected, so plugin
+// configs can grow new optional fields without breaking old callers.
+func (s Schema) Validate(raw Config) error {
+	if len(raw) == 0 {
+		raw = Config("{}")
+	}
+
+	var obj map[string]interface{}
+	if err := json.Unmarshal(raw, &obj); err != nil {
+		return fmt.Errorf("config must be a JSON object: %w", err)
+	}
+
+	for _, key := range s.Required {
+		if _, ok := obj[key]; !ok {
+			return fmt.Errorf("missing required field %q", key)
+		}
+	}
+
+	for key, spec := range s.Properties {
+		val, ok := obj[key]
+		if !ok {
+			continue
+		}
+		if !matchesType(val, spec.Type) {
+			return fmt.Errorf("field %q must be of type %s", key, spec.Type)
+		}
+	}
+
+	return nil
+}
+
+func matchesType(val interface{}, typ string) bool {
+	switch typ {
+	case "string":
+		_, ok := val.(string)
+		return ok
+	case "number":
+		_, ok := val.(float64)
+		return ok
+	case "boolean":
+		_, ok := val.(bool)
+		return ok
+	case "array":
+		_, ok := val.([]interface{})
+		return ok
+	case "object":
+		_, ok := val.(map[string]interface{})
+		return ok
+	default:
+		return true
+	}
+}
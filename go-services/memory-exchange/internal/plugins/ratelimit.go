@@ -0,0 +1,185 @@
+package plugins
+
+import (
+	"container/list"
+	"encoding/json"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/awareness/memory-exchange/internal/database"
+	"github.com/gin-gonic/gin"
+)
+
+func init() {
+	Register(&rateLimitPlugin{})
+}
+
+// rateLimitPlugin enforces a token-bucket rate limit per API key. Buckets
+// live in memory for the fast path (every request refills/drains a
+// bucket with no DB round trip); each rejected request is recorded in
+// rate_limit_counters from its own goroutine so it is visible to
+// admin-analytics. Bucket state is not persisted and starts full after a
+// restart.
+type rateLimitPlugin struct{}
+
+type rateLimitConfig struct {
+	// RequestsPerMinute is the bucket's refill rate.
+	RequestsPerMinute int `json:"requests_per_minute"`
+	// Burst is the bucket capacity; defaults to RequestsPerMinute.
+	Burst int `json:"burst"`
+}
+
+func (rateLimitPlugin) Name() string  { return "rate-limit" }
+func (rateLimitPlugin) Priority() int { return 20 }
+func (rateLimitPlugin) Schema() Schema {
+	return Schema{
+		Required: []string{"requests_per_minute"},
+		Properties: map[string]PropertySpec{
+			"requests_per_minute": {Type: "number"},
+			"burst":               {Type: "number"},
+		},
+	}
+}
+
+// bucket is a token bucket for one API key. tokens is stored as a float
+// so partial refills between requests aren't lost to truncation.
+type bucket struct {
+	mu       sync.Mutex
+	tokens   float64
+	lastFill time.Time
+}
+
+// bucketCacheSize bounds how many callers' buckets a guarded endpoint keeps
+// in memory at once, the same way middleware.keyLimitCache bounds
+// per-API-key state: keys beyond this are evicted least-recently-used, so
+// an attacker hitting the endpoint from unbounded source IPs can't grow
+// this map without limit.
+const bucketCacheSize = 10000
+
+// bucketCache is an LRU cache of *bucket keyed by API key (or client IP
+// when no key is present).
+type bucketCache struct {
+	mu      sync.Mutex
+	entries map[string]*list.Element // key -> element holding *bucketEntry
+	order   *list.List               // front = most recently used
+}
+
+type bucketEntry struct {
+	key string
+	b   *bucket
+}
+
+func newBucketCache() *bucketCache {
+	return &bucketCache{
+		entries: make(map[string]*list.Element),
+		order:   list.New(),
+	}
+}
+
+func (c *bucketCache) get(key string) (*bucket, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	c.order.MoveToFront(el)
+	return el.Value.(*bucketEntry).b, true
+}
+
+func (c *bucketCache) put(key string, b *bucket) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.entries[key]; ok {
+		el.Value.(*bucketEntry).b = b
+		c.order.MoveToFront(el)
+		return
+	}
+
+	el := c.order.PushFront(&bucketEntry{key: key, b: b})
+	c.entries[key] = el
+
+	if c.order.Len() > bucketCacheSize {
+		oldest := c.order.Back()
+		if oldest != nil {
+			c.order.Remove(oldest)
+			delete(c.entries, oldest.Value.(*bucketEntry).key)
+		}
+	}
+}
+
+func (rateLimitPlugin) Handler(cfg Config) (gin.HandlerFunc, error) {
+	var conf rateLimitConfig
+	if err := json.Unmarshal(cfg, &conf); err != nil {
+		return nil, err
+	}
+	if conf.Burst <= 0 {
+		conf.Burst = conf.RequestsPerMinute
+	}
+	refillPerSecond := float64(conf.RequestsPerMinute) / 60.0
+
+	buckets := newBucketCache()
+
+	return func(c *gin.Context) {
+		apiKey, _ := c.Get("api_key")
+		key, _ := apiKey.(string)
+		if key == "" {
+			key = c.ClientIP()
+		}
+
+		b, ok := buckets.get(key)
+		if !ok {
+			b = &bucket{tokens: float64(conf.Burst), lastFill: time.Now()}
+			buckets.put(key, b)
+		}
+
+		b.mu.Lock()
+		now := time.Now()
+		elapsed := now.Sub(b.lastFill).Seconds()
+		b.tokens += elapsed * refillPerSecond
+		if b.tokens > float64(conf.Burst) {
+			b.tokens = float64(conf.Burst)
+		}
+		b.lastFill = now
+
+		allowed := b.tokens >= 1
+		if allowed {
+			b.tokens--
+		}
+		b.mu.Unlock()
+
+		if !allowed {
+			go recordRateLimitHit(key)
+			c.JSON(http.StatusTooManyRequests, gin.H{
+				"success": false,
+				"error":   "Rate limit exceeded",
+			})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}, nil
+}
+
+// recordRateLimitHit persists a throttled request so admin-analytics can
+// report on rate-limit pressure per key. Best-effort: a failure here must
+// never affect the request that triggered it, which has already been
+// rejected by the time this runs.
+func recordRateLimitHit(key string) {
+	if database.DB == nil {
+		return
+	}
+	_, err := database.DB.Exec(`
+		INSERT INTO rate_limit_counters (api_key, window_start, hit_count)
+		VALUES (?, DATE_FORMAT(NOW(), '%Y-%m-%d %H:%i:00'), 1)
+		ON DUPLICATE KEY UPDATE hit_count = hit_count + 1
+	`, key)
+	if err != nil {
+		log.Printf("plugins: failed to record rate limit hit for %s: %v", key, err)
+	}
+}
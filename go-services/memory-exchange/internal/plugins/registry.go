@@ -0,0 +1,43 @@
+package plugins
+
+import (
+	"fmt"
+	"sync"
+)
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[string]Plugin{}
+)
+
+// Register adds a plugin to the global registry. Built-in plugins call
+// this from their own init() func; it panics on a duplicate name since
+// that always indicates a programming error, not a runtime condition.
+func Register(p Plugin) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	if _, exists := registry[p.Name()]; exists {
+		panic(fmt.Sprintf("plugins: duplicate registration for %q", p.Name()))
+	}
+	registry[p.Name()] = p
+}
+
+// Lookup returns the registered plugin with the given name, if any.
+func Lookup(name string) (Plugin, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	p, ok := registry[name]
+	return p, ok
+}
+
+// Names returns the names of all registered plugins.
+func Names() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	return names
+}
@@ -0,0 +1,51 @@
+package plugins
+
+import (
+	"encoding/json"
+
+	"github.com/gin-gonic/gin"
+)
+
+func init() {
+	Register(&requestTransformPlugin{})
+}
+
+// requestTransformPlugin rewrites request headers before the route
+// handler sees them, e.g. to inject a header a downstream service
+// expects or to strip one a client sent that shouldn't reach it.
+type requestTransformPlugin struct{}
+
+type requestTransformConfig struct {
+	SetHeaders    map[string]string `json:"set_headers"`
+	RemoveHeaders []string          `json:"remove_headers"`
+}
+
+func (requestTransformPlugin) Name() string  { return "request-transform" }
+func (requestTransformPlugin) Priority() int { return 5 }
+func (requestTransformPlugin) Schema() Schema {
+	return Schema{
+		Properties: map[string]PropertySpec{
+			"set_headers":    {Type: "object"},
+			"remove_headers": {Type: "array"},
+		},
+	}
+}
+
+func (requestTransformPlugin) Handler(cfg Config) (gin.HandlerFunc, error) {
+	var conf requestTransformConfig
+	if len(cfg) > 0 {
+		if err := json.Unmarshal(cfg, &conf); err != nil {
+			return nil, err
+		}
+	}
+
+	return func(c *gin.Context) {
+		for _, header := range conf.RemoveHeaders {
+			c.Request.Header.Del(header)
+		}
+		for header, value := range conf.SetHeaders {
+			c.Request.Header.Set(header, value)
+		}
+		c.Next()
+	}, nil
+}
@@ -0,0 +1,65 @@
+package plugins
+
+import (
+	"encoding/json"
+	"log"
+	"time"
+
+	"github.com/awareness/memory-exchange/internal/database"
+	"github.com/gin-gonic/gin"
+)
+
+func init() {
+	Register(&responseLoggerPlugin{})
+}
+
+// responseLoggerPlugin records every call's method, status, and latency to
+// api_usage_logs, subsuming what shared/middleware.MetricsMiddleware did
+// for services that hard-coded it; as a plugin it can be bound per route
+// and reloaded without a restart like everything else in the chain.
+type responseLoggerPlugin struct{}
+
+type responseLoggerConfig struct {
+	ServiceName string `json:"service_name"`
+}
+
+func (responseLoggerPlugin) Name() string  { return "response-logger" }
+func (responseLoggerPlugin) Priority() int { return 100 }
+func (responseLoggerPlugin) Schema() Schema {
+	return Schema{
+		Required: []string{"service_name"},
+		Properties: map[string]PropertySpec{
+			"service_name": {Type: "string"},
+		},
+	}
+}
+
+func (responseLoggerPlugin) Handler(cfg Config) (gin.HandlerFunc, error) {
+	var conf responseLoggerConfig
+	if err := json.Unmarshal(cfg, &conf); err != nil {
+		return nil, err
+	}
+
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		elapsedMs := time.Since(start).Milliseconds()
+		status := c.Writer.Status()
+		go logAPICall(conf.ServiceName, c.Request.Method, c.FullPath(), status, elapsedMs, c.GetString("api_key"), c.Request.UserAgent())
+	}, nil
+}
+
+func logAPICall(serviceName, method, endpoint string, statusCode int, responseTimeMs int64, apiKey, userAgent string) {
+	if database.DB == nil {
+		return
+	}
+	_, err := database.DB.Exec(`
+		INSERT INTO api_usage_logs
+		(service_name, endpoint, method, status_code, response_time_ms, api_key, user_agent, is_error, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, serviceName, endpoint, method, statusCode, responseTimeMs, apiKey, userAgent, statusCode >= 400, time.Now())
+	if err != nil {
+		log.Printf("Failed to log API call: %v", err)
+	}
+}
@@ -0,0 +1,231 @@
+package relayapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math/rand"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/awareness/memory-exchange/internal/crypto/envelope"
+	"github.com/awareness/memory-exchange/internal/database"
+)
+
+const (
+	maxDeliveryAttempts = 5
+	baseRetryDelay      = 2 * time.Second
+	maxRetryDelay       = 2 * time.Minute
+	relayFailThreshold  = 3
+	relayCooldown       = 15 * time.Minute
+	destinationQueueLen = 64
+)
+
+var httpClient = &http.Client{Timeout: 5 * time.Second}
+
+// Metrics, when set, receives relay delivery counters. cmd/main.go does not
+// set it, so by default relay deliveries record no metrics.
+var Metrics interface {
+	RecordRelayForward()
+	RecordRelaySuccess()
+	RecordRelayRetry()
+}
+
+// destQueue is the per-buyer destination queue: deliveries for one buyer
+// are processed in order by a single goroutine, so a slow or dead relay
+// for buyer A never blocks deliveries to buyer B.
+type destQueue struct {
+	buyerID int
+	jobs    chan []byte
+}
+
+var (
+	queuesMu sync.Mutex
+	queues   = map[int]*destQueue{}
+
+	blacklistMu sync.Mutex
+	blacklist   = map[string]time.Time{} // relay_url -> blacklisted until
+	failures    = map[string]int{}       // relay_url -> consecutive failures
+)
+
+// Deliver seals payload for buyerPubKey and enqueues it for asynchronous
+// delivery to the buyer, trying their highest-priority relay first (their
+// own agent endpoint, by convention registered at priority 0) and falling
+// back through the rest of their registered relays in priority order.
+func Deliver(buyerID int, payload []byte, buyerPubKey []byte) error {
+	env, err := envelope.Seal(payload, buyerPubKey)
+	if err != nil {
+		return fmt.Errorf("failed to seal envelope for buyer %d: %w", buyerID, err)
+	}
+	sealed, err := json.Marshal(env)
+	if err != nil {
+		return fmt.Errorf("failed to encode envelope: %w", err)
+	}
+
+	q := queueFor(buyerID)
+	select {
+	case q.jobs <- sealed:
+	default:
+		return fmt.Errorf("relay destination queue for buyer %d is full", buyerID)
+	}
+	return nil
+}
+
+func queueFor(buyerID int) *destQueue {
+	queuesMu.Lock()
+	defer queuesMu.Unlock()
+
+	if q, ok := queues[buyerID]; ok {
+		return q
+	}
+	q := &destQueue{buyerID: buyerID, jobs: make(chan []byte, destinationQueueLen)}
+	queues[buyerID] = q
+	go q.run()
+	return q
+}
+
+func (q *destQueue) run() {
+	for sealed := range q.jobs {
+		if err := deliverToBuyer(q.buyerID, sealed); err != nil {
+			log.Printf("relay: giving up delivering to buyer %d: %v", q.buyerID, err)
+		}
+	}
+}
+
+// deliverToBuyer tries each of the buyer's registered relays in priority
+// order, skipping ones currently blacklisted, with exponential backoff and
+// full jitter between attempts.
+func deliverToBuyer(buyerID int, sealed []byte) error {
+	relays, err := relaysFor(buyerID)
+	if err != nil {
+		return fmt.Errorf("failed to load relays: %w", err)
+	}
+	if len(relays) == 0 {
+		return fmt.Errorf("no registered relays")
+	}
+
+	var lastErr error
+	for attempt := 0; attempt < maxDeliveryAttempts; attempt++ {
+		if attempt > 0 {
+			time.Sleep(backoff(attempt))
+			if Metrics != nil {
+				Metrics.RecordRelayRetry()
+			}
+		}
+
+		for _, relay := range relays {
+			if isBlacklisted(relay.RelayURL) {
+				continue
+			}
+
+			if Metrics != nil {
+				Metrics.RecordRelayForward()
+			}
+			if err := forwardTo(relay.RelayURL, buyerID, sealed); err != nil {
+				lastErr = err
+				recordFailure(relay.RelayURL)
+				recordHealth(relay.RelayURL, false)
+				continue
+			}
+
+			recordSuccess(relay.RelayURL)
+			recordHealth(relay.RelayURL, true)
+			if Metrics != nil {
+				Metrics.RecordRelaySuccess()
+			}
+			return nil
+		}
+	}
+
+	if lastErr == nil {
+		lastErr = fmt.Errorf("all relays blacklisted")
+	}
+	return lastErr
+}
+
+func relaysFor(buyerID int) ([]BuyerRelay, error) {
+	rows, err := database.DB.Query(`
+		SELECT id, buyer_id, relay_url, priority, last_seen
+		FROM buyer_relays
+		WHERE buyer_id = ?
+		ORDER BY priority ASC
+	`, buyerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var relays []BuyerRelay
+	for rows.Next() {
+		var r BuyerRelay
+		if err := rows.Scan(&r.ID, &r.BuyerID, &r.RelayURL, &r.Priority, &r.LastSeen); err != nil {
+			continue
+		}
+		relays = append(relays, r)
+	}
+	return relays, nil
+}
+
+func forwardTo(relayURL string, buyerID int, sealed []byte) error {
+	body, err := json.Marshal(ForwardEnvelopeRequest{
+		BuyerID:  buyerID,
+		Envelope: string(sealed),
+	})
+	if err != nil {
+		return err
+	}
+
+	resp, err := httpClient.Post(relayURL+"/api/v1/relay/forward", "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("relay %s returned status %d", relayURL, resp.StatusCode)
+	}
+	return nil
+}
+
+// backoff returns an exponential delay with full jitter, capped at
+// maxRetryDelay, for the given (1-indexed) attempt number.
+func backoff(attempt int) time.Duration {
+	d := baseRetryDelay * time.Duration(1<<uint(attempt-1))
+	if d > maxRetryDelay {
+		d = maxRetryDelay
+	}
+	return time.Duration(rand.Int63n(int64(d)))
+}
+
+func isBlacklisted(relayURL string) bool {
+	blacklistMu.Lock()
+	defer blacklistMu.Unlock()
+	until, ok := blacklist[relayURL]
+	if !ok {
+		return false
+	}
+	if time.Now().After(until) {
+		delete(blacklist, relayURL)
+		delete(failures, relayURL)
+		return false
+	}
+	return true
+}
+
+func recordFailure(relayURL string) {
+	blacklistMu.Lock()
+	defer blacklistMu.Unlock()
+	failures[relayURL]++
+	if failures[relayURL] >= relayFailThreshold {
+		blacklist[relayURL] = time.Now().Add(relayCooldown)
+	}
+}
+
+func recordSuccess(relayURL string) {
+	blacklistMu.Lock()
+	defer blacklistMu.Unlock()
+	delete(failures, relayURL)
+	delete(blacklist, relayURL)
+}
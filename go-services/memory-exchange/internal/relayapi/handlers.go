@@ -0,0 +1,232 @@
+package relayapi
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/awareness/memory-exchange/internal/database"
+	"github.com/awareness/memory-exchange/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterRelay godoc
+// @Summary Register a relay endpoint
+// @Description A relay node advertises itself as a delivery endpoint for a buyer
+// @Tags relay
+// @Accept json
+// @Produce json
+// @Param request body RegisterRelayRequest true "Relay registration"
+// @Success 200 {object} models.APIResponse "Relay registered"
+// @Failure 400 {object} models.APIResponse "Invalid request"
+// @Failure 500 {object} models.APIResponse "Internal server error"
+// @Security ApiKeyAuth
+// @Router /relay/register [post]
+func RegisterRelay(c *gin.Context) {
+	var req RegisterRelayRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Error:   stringPtr("Invalid request body: " + err.Error()),
+		})
+		return
+	}
+
+	query := `
+		INSERT INTO buyer_relays (buyer_id, relay_url, priority, last_seen)
+		VALUES (?, ?, ?, NOW())
+		ON DUPLICATE KEY UPDATE priority = VALUES(priority), last_seen = NOW()
+	`
+	if _, err := database.DB.Exec(query, req.BuyerID, req.RelayURL, req.Priority); err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Error:   stringPtr("Failed to register relay: " + err.Error()),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Data: map[string]interface{}{
+			"message": "Relay registered successfully",
+		},
+	})
+}
+
+// ForwardEnvelope godoc
+// @Summary Forward a sealed envelope to a relay
+// @Description Accept a sealed envelope for a buyer and persist it with a TTL
+// @Tags relay
+// @Accept json
+// @Produce json
+// @Param request body ForwardEnvelopeRequest true "Sealed envelope"
+// @Success 200 {object} models.APIResponse "Envelope accepted"
+// @Failure 400 {object} models.APIResponse "Invalid request"
+// @Failure 500 {object} models.APIResponse "Internal server error"
+// @Security ApiKeyAuth
+// @Router /relay/forward [post]
+func ForwardEnvelope(c *gin.Context) {
+	var req ForwardEnvelopeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Error:   stringPtr("Invalid request body: " + err.Error()),
+		})
+		return
+	}
+
+	ttl := defaultTTL
+	if req.TTL > 0 {
+		ttl = time.Duration(req.TTL) * time.Second
+	}
+	expiresAt := time.Now().Add(ttl)
+
+	query := `
+		INSERT INTO relay_transactions (buyer_id, envelope, status, created_at, expires_at)
+		VALUES (?, ?, 'pending', NOW(), ?)
+	`
+	result, err := database.DB.Exec(query, req.BuyerID, req.Envelope, expiresAt)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Error:   stringPtr("Failed to persist envelope: " + err.Error()),
+		})
+		return
+	}
+
+	transactionID, _ := result.LastInsertId()
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Data: map[string]interface{}{
+			"transaction_id": transactionID,
+			"expires_at":     expiresAt,
+		},
+	})
+}
+
+// PickupEnvelopes godoc
+// @Summary Poll a relay for pending envelopes
+// @Description A buyer polls its relay for envelopes persisted since the given time
+// @Tags relay
+// @Produce json
+// @Param buyer_id query int true "Buyer ID"
+// @Param since query string false "RFC3339 timestamp; defaults to the epoch"
+// @Success 200 {object} models.APIResponse "Pending envelopes"
+// @Failure 400 {object} models.APIResponse "Invalid request"
+// @Failure 500 {object} models.APIResponse "Internal server error"
+// @Security ApiKeyAuth
+// @Router /relay/pickup [get]
+func PickupEnvelopes(c *gin.Context) {
+	buyerID := c.Query("buyer_id")
+	if buyerID == "" {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Error:   stringPtr("buyer_id is required"),
+		})
+		return
+	}
+
+	since := time.Unix(0, 0)
+	if raw := c.Query("since"); raw != "" {
+		parsed, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.APIResponse{
+				Success: false,
+				Error:   stringPtr("since must be an RFC3339 timestamp"),
+			})
+			return
+		}
+		since = parsed
+	}
+
+	query := `
+		SELECT id, buyer_id, envelope, status, created_at, expires_at
+		FROM relay_transactions
+		WHERE buyer_id = ? AND status = 'pending' AND created_at > ? AND expires_at > NOW()
+		ORDER BY created_at ASC
+	`
+	rows, err := database.DB.Query(query, buyerID, since)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Error:   stringPtr("Database error: " + err.Error()),
+		})
+		return
+	}
+	defer rows.Close()
+
+	transactions := []RelayTransaction{}
+	for rows.Next() {
+		var tx RelayTransaction
+		if err := rows.Scan(&tx.ID, &tx.BuyerID, &tx.Envelope, &tx.Status, &tx.CreatedAt, &tx.ExpiresAt); err != nil {
+			continue
+		}
+		transactions = append(transactions, tx)
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Data: map[string]interface{}{
+			"transactions": transactions,
+			"count":        len(transactions),
+		},
+	})
+}
+
+// AckEnvelope godoc
+// @Summary Acknowledge receipt of a relayed envelope
+// @Description Buyer confirms receipt so the relay can purge the transaction
+// @Tags relay
+// @Accept json
+// @Produce json
+// @Param request body AckRequest true "Acknowledgement"
+// @Success 200 {object} models.APIResponse "Acknowledged"
+// @Failure 400 {object} models.APIResponse "Invalid request"
+// @Failure 404 {object} models.APIResponse "Transaction not found"
+// @Failure 500 {object} models.APIResponse "Internal server error"
+// @Security ApiKeyAuth
+// @Router /relay/ack [post]
+func AckEnvelope(c *gin.Context) {
+	var req AckRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Error:   stringPtr("Invalid request body: " + err.Error()),
+		})
+		return
+	}
+
+	query := `
+		UPDATE relay_transactions
+		SET status = 'delivered'
+		WHERE id = ? AND buyer_id = ? AND status = 'pending'
+	`
+	result, err := database.DB.Exec(query, req.TransactionID, req.BuyerID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Error:   stringPtr("Failed to acknowledge transaction: " + err.Error()),
+		})
+		return
+	}
+
+	affected, _ := result.RowsAffected()
+	if affected == 0 {
+		c.JSON(http.StatusNotFound, models.APIResponse{
+			Success: false,
+			Error:   stringPtr("Transaction not found or already acknowledged"),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Data: map[string]interface{}{
+			"message": "Acknowledged",
+		},
+	})
+}
+
+func stringPtr(s string) *string {
+	return &s
+}
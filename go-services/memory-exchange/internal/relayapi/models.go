@@ -0,0 +1,54 @@
+// Package relayapi implements a store-and-forward relay network so a
+// purchased memory, reasoning chain, or W-Matrix can still reach a buyer
+// whose agent is offline or behind NAT: relay nodes register themselves,
+// sellers forward sealed envelopes to them, and buyers poll for pickup.
+package relayapi
+
+import "time"
+
+// BuyerRelay is a relay endpoint a buyer has registered to receive
+// deliveries on its behalf, ordered by Priority (lower is tried first).
+type BuyerRelay struct {
+	ID       int64     `json:"id"`
+	BuyerID  int       `json:"buyer_id"`
+	RelayURL string    `json:"relay_url"`
+	Priority int       `json:"priority"`
+	LastSeen time.Time `json:"last_seen"`
+}
+
+// RelayTransaction is a sealed envelope held by a relay node for a buyer
+// until it is picked up or expires.
+type RelayTransaction struct {
+	ID         int64     `json:"id"`
+	BuyerID    int       `json:"buyer_id"`
+	Envelope   string    `json:"envelope"` // JSON-encoded envelope.Envelope
+	Status     string    `json:"status"`   // pending, delivered, expired
+	CreatedAt  time.Time `json:"created_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+}
+
+// RegisterRelayRequest is submitted by a relay node to advertise itself
+// for a buyer it serves.
+type RegisterRelayRequest struct {
+	BuyerID  int    `json:"buyer_id" binding:"required"`
+	RelayURL string `json:"relay_url" binding:"required,url"`
+	Priority int    `json:"priority"`
+}
+
+// ForwardEnvelopeRequest hands a sealed envelope to a relay for a buyer.
+type ForwardEnvelopeRequest struct {
+	BuyerID  int    `json:"buyer_id" binding:"required"`
+	Envelope string `json:"envelope" binding:"required"`
+	TTL      int    `json:"ttl_seconds"` // defaults to defaultTTL if zero
+}
+
+// AckRequest confirms a buyer has received a transaction so the relay can
+// purge it.
+type AckRequest struct {
+	TransactionID int64 `json:"transaction_id" binding:"required"`
+	BuyerID       int   `json:"buyer_id" binding:"required"`
+}
+
+// defaultTTL is how long a relay holds an undelivered envelope before it
+// is eligible for expiry.
+const defaultTTL = 72 * time.Hour
@@ -0,0 +1,112 @@
+package relayapi
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/awareness/memory-exchange/internal/database"
+)
+
+// relayHealth accumulates lifetime delivery outcomes for a relay URL,
+// independent of the blacklist's short-lived failure streak, so the
+// reconciliation job can judge relays by overall reliability rather than
+// a momentary blip.
+type relayHealth struct {
+	successes int64
+	failures  int64
+}
+
+var (
+	healthMu sync.Mutex
+	health   = map[string]*relayHealth{}
+)
+
+func recordHealth(relayURL string, ok bool) {
+	healthMu.Lock()
+	defer healthMu.Unlock()
+	h, found := health[relayURL]
+	if !found {
+		h = &relayHealth{}
+		health[relayURL] = h
+	}
+	if ok {
+		h.successes++
+	} else {
+		h.failures++
+	}
+}
+
+func successRate(h *relayHealth) float64 {
+	total := h.successes + h.failures
+	if total == 0 {
+		return 1 // unproven relays start neutral, not penalized
+	}
+	return float64(h.successes) / float64(total)
+}
+
+// RunReconciliation periodically adjusts each relay's priority based on its
+// observed success rate: reliable relays are promoted toward priority 0
+// (tried first), unreliable ones are demoted. It runs until ctx is
+// canceled.
+func RunReconciliation(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			reconcileOnce()
+		}
+	}
+}
+
+func reconcileOnce() {
+	rows, err := database.DB.Query(`SELECT id, relay_url, priority FROM buyer_relays`)
+	if err != nil {
+		log.Printf("relay: reconciliation query failed: %v", err)
+		return
+	}
+	defer rows.Close()
+
+	type row struct {
+		id       int64
+		relayURL string
+		priority int
+	}
+	var toUpdate []row
+
+	healthMu.Lock()
+	for rows.Next() {
+		var r row
+		if err := rows.Scan(&r.id, &r.relayURL, &r.priority); err != nil {
+			continue
+		}
+		h, found := health[r.relayURL]
+		if !found {
+			continue
+		}
+		rate := successRate(h)
+		newPriority := r.priority
+		switch {
+		case rate >= 0.95 && r.priority > 0:
+			newPriority = r.priority - 1
+		case rate < 0.5:
+			newPriority = r.priority + 1
+		}
+		if newPriority != r.priority {
+			r.priority = newPriority
+			toUpdate = append(toUpdate, r)
+		}
+	}
+	healthMu.Unlock()
+
+	for _, r := range toUpdate {
+		if _, err := database.DB.Exec(`UPDATE buyer_relays SET priority = ? WHERE id = ?`, r.priority, r.id); err != nil {
+			log.Printf("relay: failed to update priority for relay %d: %v", r.id, err)
+		}
+	}
+}
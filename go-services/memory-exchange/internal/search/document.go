@@ -0,0 +1,19 @@
+// Package search provides an Elasticsearch/OpenSearch-backed index over
+// memory_exchanges and reasoning_chains, so SearchCatalog can run one
+// BM25+filter query instead of a MySQL LIKE/range scan. BrowseMemories and
+// BrowseReasoningChains do not use it; they query MySQL directly.
+package search
+
+// Document is the shape indexed for each memory_exchanges or
+// reasoning_chains row. Field names are snake_case to match the mapping in
+// mapping.go.
+type Document struct {
+	ID          string  `json:"id"`
+	Kind        string  `json:"kind"` // "memory" or "reasoning_chain"
+	Tag         string  `json:"tag"`  // memory_type or category, BM25-matched
+	SourceModel string  `json:"source_model,omitempty"`
+	TargetModel string  `json:"target_model,omitempty"`
+	CreatorID   string  `json:"creator_id"`
+	Price       float64 `json:"price"`
+	Status      string  `json:"status"`
+}
@@ -0,0 +1,196 @@
+package search
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/elastic/go-elasticsearch/v8"
+	"github.com/elastic/go-elasticsearch/v8/esapi"
+)
+
+// Request is the parsed form of a GET /api/v1/search query.
+type Request struct {
+	Kind     string // "memory" or "reasoning_chain"
+	Query    string // q: BM25-matched against Tag
+	MinPrice *float64
+	MaxPrice *float64
+	Limit    int
+}
+
+// Engine is implemented by every search backend SearchCatalog can use.
+// MySQLEngine runs LIKE/range queries against MySQL so SearchCatalog keeps
+// working when Elasticsearch is unavailable or SEARCH_ENGINE=mysql.
+type Engine interface {
+	Search(ctx context.Context, req Request) ([]Document, error)
+}
+
+// ESEngine is the Elasticsearch/OpenSearch-backed Engine.
+type ESEngine struct {
+	es   *elasticsearch.Client
+	name string
+}
+
+// NewESEngine connects to the given ES/OpenSearch addresses and binds to
+// indexName (DefaultIndexName if empty).
+func NewESEngine(addresses []string, indexName string) (*ESEngine, error) {
+	if indexName == "" {
+		indexName = DefaultIndexName
+	}
+
+	es, err := elasticsearch.NewClient(elasticsearch.Config{Addresses: addresses})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create elasticsearch client: %w", err)
+	}
+
+	return &ESEngine{es: es, name: indexName}, nil
+}
+
+// EnsureMapping creates the index with its mapping if it does not already exist.
+func (e *ESEngine) EnsureMapping(ctx context.Context) error {
+	exists, err := esapi.IndicesExistsRequest{Index: []string{e.name}}.Do(ctx, e.es)
+	if err != nil {
+		return fmt.Errorf("failed to check index existence: %w", err)
+	}
+	defer exists.Body.Close()
+
+	if exists.StatusCode == 200 {
+		return nil
+	}
+
+	create, err := esapi.IndicesCreateRequest{
+		Index: e.name,
+		Body:  strings.NewReader(mapping()),
+	}.Do(ctx, e.es)
+	if err != nil {
+		return fmt.Errorf("failed to create index: %w", err)
+	}
+	defer create.Body.Close()
+
+	if create.IsError() {
+		return fmt.Errorf("failed to create index %q: %s", e.name, create.String())
+	}
+	return nil
+}
+
+// BulkIndex upserts many documents in a single request using the ES bulk API.
+func (e *ESEngine) BulkIndex(ctx context.Context, docs []Document) error {
+	if len(docs) == 0 {
+		return nil
+	}
+
+	var buf bytes.Buffer
+	for _, doc := range docs {
+		meta := map[string]interface{}{
+			"index": map[string]interface{}{"_index": e.name, "_id": doc.Kind + "-" + doc.ID},
+		}
+		metaLine, _ := json.Marshal(meta)
+		docLine, err := json.Marshal(doc)
+		if err != nil {
+			return fmt.Errorf("failed to marshal document %s: %w", doc.ID, err)
+		}
+		buf.Write(metaLine)
+		buf.WriteByte('\n')
+		buf.Write(docLine)
+		buf.WriteByte('\n')
+	}
+
+	res, err := esapi.BulkRequest{Body: bytes.NewReader(buf.Bytes())}.Do(ctx, e.es)
+	if err != nil {
+		return fmt.Errorf("bulk index request failed: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.IsError() {
+		return fmt.Errorf("bulk index request failed: %s", res.String())
+	}
+	return nil
+}
+
+// Search runs a bool query combining the Kind/price filters with a
+// match (BM25) clause over Tag, or match_all when Query is empty.
+func (e *ESEngine) Search(ctx context.Context, req Request) ([]Document, error) {
+	body, err := json.Marshal(e.buildQuery(req))
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal search query: %w", err)
+	}
+
+	res, err := esapi.SearchRequest{
+		Index: []string{e.name},
+		Body:  bytes.NewReader(body),
+	}.Do(ctx, e.es)
+	if err != nil {
+		return nil, fmt.Errorf("search request failed: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.IsError() {
+		return nil, fmt.Errorf("search request failed: %s", res.String())
+	}
+
+	var parsed struct {
+		Hits struct {
+			Hits []struct {
+				Source Document `json:"_source"`
+			} `json:"hits"`
+		} `json:"hits"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&parsed); err != nil {
+		return nil, fmt.Errorf("failed to decode search response: %w", err)
+	}
+
+	docs := make([]Document, 0, len(parsed.Hits.Hits))
+	for _, hit := range parsed.Hits.Hits {
+		docs = append(docs, hit.Source)
+	}
+	return docs, nil
+}
+
+func (e *ESEngine) buildQuery(req Request) map[string]interface{} {
+	filters := []map[string]interface{}{}
+	if req.Kind != "" {
+		filters = append(filters, map[string]interface{}{
+			"term": map[string]interface{}{"kind": req.Kind},
+		})
+	}
+	if req.MinPrice != nil || req.MaxPrice != nil {
+		priceRange := map[string]interface{}{}
+		if req.MinPrice != nil {
+			priceRange["gte"] = *req.MinPrice
+		}
+		if req.MaxPrice != nil {
+			priceRange["lte"] = *req.MaxPrice
+		}
+		filters = append(filters, map[string]interface{}{
+			"range": map[string]interface{}{"price": priceRange},
+		})
+	}
+
+	must := []map[string]interface{}{}
+	if req.Query != "" {
+		must = append(must, map[string]interface{}{
+			"match": map[string]interface{}{"tag": req.Query},
+		})
+	} else {
+		must = append(must, map[string]interface{}{"match_all": map[string]interface{}{}})
+	}
+
+	return map[string]interface{}{
+		"size": limitOrDefault(req.Limit),
+		"query": map[string]interface{}{
+			"bool": map[string]interface{}{
+				"must":   must,
+				"filter": filters,
+			},
+		},
+	}
+}
+
+func limitOrDefault(limit int) int {
+	if limit > 0 {
+		return limit
+	}
+	return 20
+}
@@ -0,0 +1,138 @@
+package search
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/awareness/memory-exchange/internal/database"
+)
+
+// Indexer periodically re-scans memory_exchanges and reasoning_chains for
+// rows created since its last checkpoint and bulk-upserts them into an
+// ESEngine. Neither table has an updated_at column, so this tails
+// created_at: status transitions on an existing row (ReserveMemory,
+// ConfirmMemory and refunds update memory_exchanges.status in place) are
+// not picked up, so an indexed document keeps the status its row had when
+// it was first scanned.
+type Indexer struct {
+	engine   *ESEngine
+	interval time.Duration
+	since    time.Time
+}
+
+// NewIndexer builds an Indexer that re-scans every interval, starting from
+// the epoch so the first run indexes both tables in full.
+func NewIndexer(engine *ESEngine, interval time.Duration) *Indexer {
+	return &Indexer{engine: engine, interval: interval, since: time.Unix(0, 0)}
+}
+
+// Run scans once immediately, then on every tick, until ctx is cancelled.
+func (ix *Indexer) Run(ctx context.Context) {
+	ix.scanOnce(ctx)
+
+	ticker := time.NewTicker(ix.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			ix.scanOnce(ctx)
+		}
+	}
+}
+
+func (ix *Indexer) scanOnce(ctx context.Context) {
+	docs, maxSeen := ix.scanMemories(ctx, ix.since)
+	chainDocs, maxChainSeen := ix.scanReasoningChains(ctx, ix.since)
+	docs = append(docs, chainDocs...)
+	if maxChainSeen.After(maxSeen) {
+		maxSeen = maxChainSeen
+	}
+
+	if len(docs) == 0 {
+		return
+	}
+
+	if err := ix.engine.BulkIndex(ctx, docs); err != nil {
+		log.Printf("search: bulk index failed: %v", err)
+		return
+	}
+	ix.since = maxSeen
+	log.Printf("search: indexed %d catalog documents created since checkpoint", len(docs))
+}
+
+func (ix *Indexer) scanMemories(ctx context.Context, since time.Time) ([]Document, time.Time) {
+	rows, err := database.DB.QueryContext(ctx, `
+		SELECT id, seller_id, memory_type, price, status, created_at
+		FROM memory_exchanges WHERE created_at > ? ORDER BY created_at ASC
+	`, since)
+	if err != nil {
+		log.Printf("search: memory_exchanges checkpoint scan failed: %v", err)
+		return nil, since
+	}
+	defer rows.Close()
+
+	maxSeen := since
+	var docs []Document
+	for rows.Next() {
+		var id, sellerID int
+		var tag, status string
+		var price float64
+		var createdAt time.Time
+		if err := rows.Scan(&id, &sellerID, &tag, &price, &status, &createdAt); err != nil {
+			continue
+		}
+		docs = append(docs, Document{
+			ID:        fmt.Sprintf("%d", id),
+			Kind:      "memory",
+			Tag:       tag,
+			CreatorID: fmt.Sprintf("%d", sellerID),
+			Price:     price,
+			Status:    status,
+		})
+		if createdAt.After(maxSeen) {
+			maxSeen = createdAt
+		}
+	}
+	return docs, maxSeen
+}
+
+func (ix *Indexer) scanReasoningChains(ctx context.Context, since time.Time) ([]Document, time.Time) {
+	rows, err := database.DB.QueryContext(ctx, `
+		SELECT id, creator_id, category, price_per_use, status, created_at
+		FROM reasoning_chains WHERE created_at > ? ORDER BY created_at ASC
+	`, since)
+	if err != nil {
+		log.Printf("search: reasoning_chains checkpoint scan failed: %v", err)
+		return nil, since
+	}
+	defer rows.Close()
+
+	maxSeen := since
+	var docs []Document
+	for rows.Next() {
+		var id, creatorID int
+		var tag, status string
+		var price float64
+		var createdAt time.Time
+		if err := rows.Scan(&id, &creatorID, &tag, &price, &status, &createdAt); err != nil {
+			continue
+		}
+		docs = append(docs, Document{
+			ID:        fmt.Sprintf("%d", id),
+			Kind:      "reasoning_chain",
+			Tag:       tag,
+			CreatorID: fmt.Sprintf("%d", creatorID),
+			Price:     price,
+			Status:    status,
+		})
+		if createdAt.After(maxSeen) {
+			maxSeen = createdAt
+		}
+	}
+	return docs, maxSeen
+}
@@ -0,0 +1,30 @@
+package search
+
+// DefaultIndexName is the index this package manages. Memories and
+// reasoning chains share one index, distinguished by Document.Kind, since
+// both are just priced, browsable catalog entries to a search client.
+const DefaultIndexName = "memory-exchange-catalog"
+
+// mapping is the ES/OpenSearch index mapping: a keyword Kind filter, a BM25
+// text field over Tag (memory_type/category), keyword fields for the model
+// pair and creator, and a numeric range on Price.
+func mapping() string {
+	return `{
+  "settings": {
+    "number_of_shards": 1,
+    "number_of_replicas": 1
+  },
+  "mappings": {
+    "properties": {
+      "id":            {"type": "keyword"},
+      "kind":          {"type": "keyword"},
+      "tag":           {"type": "text"},
+      "source_model":  {"type": "keyword"},
+      "target_model":  {"type": "keyword"},
+      "creator_id":    {"type": "keyword"},
+      "price":         {"type": "double"},
+      "status":        {"type": "keyword"}
+    }
+  }
+}`
+}
@@ -0,0 +1,113 @@
+package search
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/awareness/memory-exchange/internal/database"
+)
+
+// MySQLEngine is the fallback Engine used when Elasticsearch is unavailable
+// or SEARCH_ENGINE=mysql: the same status-filtered, price-ranged query
+// BrowseMemories/BrowseReasoningChains run, with
+// req.Query matched via LIKE instead of BM25.
+type MySQLEngine struct{}
+
+func (MySQLEngine) Search(ctx context.Context, req Request) ([]Document, error) {
+	if req.Kind == "reasoning_chain" {
+		return searchReasoningChains(ctx, req)
+	}
+	return searchMemories(ctx, req)
+}
+
+func searchMemories(ctx context.Context, req Request) ([]Document, error) {
+	query := `SELECT id, seller_id, memory_type, price, status
+	          FROM memory_exchanges WHERE status = 'available'`
+	var args []interface{}
+
+	if req.Query != "" {
+		query += " AND memory_type LIKE ?"
+		args = append(args, "%"+req.Query+"%")
+	}
+	if req.MinPrice != nil {
+		query += " AND price >= ?"
+		args = append(args, *req.MinPrice)
+	}
+	if req.MaxPrice != nil {
+		query += " AND price <= ?"
+		args = append(args, *req.MaxPrice)
+	}
+	query += " ORDER BY created_at DESC LIMIT ?"
+	args = append(args, limitOrDefault(req.Limit))
+
+	rows, err := database.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("mysql search query failed: %w", err)
+	}
+	defer rows.Close()
+
+	var docs []Document
+	for rows.Next() {
+		var id, sellerID int
+		var tag, status string
+		var price float64
+		if err := rows.Scan(&id, &sellerID, &tag, &price, &status); err != nil {
+			continue
+		}
+		docs = append(docs, Document{
+			ID:        fmt.Sprintf("%d", id),
+			Kind:      "memory",
+			Tag:       tag,
+			CreatorID: fmt.Sprintf("%d", sellerID),
+			Price:     price,
+			Status:    status,
+		})
+	}
+	return docs, nil
+}
+
+func searchReasoningChains(ctx context.Context, req Request) ([]Document, error) {
+	query := `SELECT id, creator_id, category, price_per_use, status
+	          FROM reasoning_chains WHERE status = 'active'`
+	var args []interface{}
+
+	if req.Query != "" {
+		query += " AND category LIKE ?"
+		args = append(args, "%"+req.Query+"%")
+	}
+	if req.MinPrice != nil {
+		query += " AND price_per_use >= ?"
+		args = append(args, *req.MinPrice)
+	}
+	if req.MaxPrice != nil {
+		query += " AND price_per_use <= ?"
+		args = append(args, *req.MaxPrice)
+	}
+	query += " ORDER BY created_at DESC LIMIT ?"
+	args = append(args, limitOrDefault(req.Limit))
+
+	rows, err := database.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("mysql search query failed: %w", err)
+	}
+	defer rows.Close()
+
+	var docs []Document
+	for rows.Next() {
+		var id, creatorID int
+		var tag, status string
+		var price float64
+		if err := rows.Scan(&id, &creatorID, &tag, &price, &status); err != nil {
+			continue
+		}
+		docs = append(docs, Document{
+			ID:        fmt.Sprintf("%d", id),
+			Kind:      "reasoning_chain",
+			Tag:       tag,
+			CreatorID: fmt.Sprintf("%d", creatorID),
+			Price:     price,
+			Status:    status,
+		})
+	}
+	return docs, nil
+}
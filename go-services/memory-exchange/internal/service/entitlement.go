@@ -0,0 +1,283 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/awareness/memory-exchange/internal/models"
+)
+
+// Entitlement kinds stored in chain_entitlements.kind.
+const (
+	EntitlementPerUse       = "per_use"
+	EntitlementSubscription = "subscription"
+	EntitlementUnlimited    = "unlimited"
+)
+
+const (
+	// defaultPerUseGrant is how many uses a per_use purchase grants when
+	// the caller doesn't specify a quantity.
+	defaultPerUseGrant = 1
+	// subscriptionDuration is how long a subscription entitlement lasts
+	// before it must be repurchased.
+	subscriptionDuration = 30 * 24 * time.Hour
+	// subscriptionPriceMultiplier and unlimitedPriceMultiplier price a
+	// subscription/unlimited grant as a multiple of the chain's per-use
+	// price, since neither consumes a fixed number of uses.
+	subscriptionPriceMultiplier = 20
+	unlimitedPriceMultiplier    = 100
+)
+
+// ErrInvalidEntitlementKind is returned by PurchaseReasoningChain when Kind
+// isn't one of per_use, subscription, or unlimited.
+var ErrInvalidEntitlementKind = errors.New("invalid entitlement kind")
+
+// PurchaseReasoningChainInput is the business-logic input for
+// PurchaseReasoningChain.
+type PurchaseReasoningChainInput struct {
+	BuyerID int
+	ChainID int
+	Kind    string
+	// Uses is how many uses to grant for a per_use purchase; ignored for
+	// subscription/unlimited. Defaults to defaultPerUseGrant if <= 0.
+	Uses int
+}
+
+// PurchaseReasoningChainResult is the outcome of a successful
+// PurchaseReasoningChain call.
+type PurchaseReasoningChainResult struct {
+	EntitlementID int64
+	Chain         models.ReasoningChain
+	Kind          string
+	// Receipt is a signed proof-of-purchase the buyer can present off
+	// service, or nil if issuing/persisting it failed (the purchase itself
+	// still succeeds; see issueReceiptBestEffort).
+	Receipt *IssuedReceipt
+}
+
+// PurchaseReasoningChain grants in.BuyerID a chain_entitlements row for
+// in.ChainID and debits/credits the price between buyer and creator in the
+// same transaction, mirroring ReserveMemory/ConfirmMemory's escrow debit:
+// the buyer's balance is checked and deducted with a single conditional
+// UPDATE, so a concurrent purchase that would overdraw the buyer fails
+// instead of racing.
+func (s *Service) PurchaseReasoningChain(ctx context.Context, in PurchaseReasoningChainInput) (*PurchaseReasoningChainResult, error) {
+	uses := in.Uses
+	switch in.Kind {
+	case EntitlementPerUse:
+		if uses <= 0 {
+			uses = defaultPerUseGrant
+		}
+	case EntitlementSubscription, EntitlementUnlimited:
+	default:
+		return nil, ErrInvalidEntitlementKind
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var chain models.ReasoningChain
+	err = tx.QueryRowContext(ctx, `
+		SELECT id, creator_id, category, price_per_use, status
+		FROM reasoning_chains
+		WHERE id = ?
+		FOR UPDATE
+	`, in.ChainID).Scan(&chain.ID, &chain.CreatorID, &chain.Category, &chain.PricePerUse, &chain.Status)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrChainNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+
+	var price float64
+	var usesRemaining *int
+	var expiresAt *time.Time
+	switch in.Kind {
+	case EntitlementPerUse:
+		price = chain.PricePerUse * float64(uses)
+		usesRemaining = &uses
+	case EntitlementSubscription:
+		price = chain.PricePerUse * subscriptionPriceMultiplier
+		until := time.Now().Add(subscriptionDuration)
+		expiresAt = &until
+	case EntitlementUnlimited:
+		price = chain.PricePerUse * unlimitedPriceMultiplier
+	}
+
+	res, err := tx.ExecContext(ctx,
+		`UPDATE users SET balance = balance - ? WHERE id = ? AND balance >= ?`,
+		price, in.BuyerID, price,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to debit buyer balance: %w", err)
+	}
+	if affected, _ := res.RowsAffected(); affected == 0 {
+		return nil, ErrInsufficientBalance
+	}
+
+	if _, err := tx.ExecContext(ctx,
+		`UPDATE users SET balance = balance + ? WHERE id = ?`, price, chain.CreatorID,
+	); err != nil {
+		return nil, fmt.Errorf("failed to credit creator balance: %w", err)
+	}
+
+	nonce, err := randomNonce()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate entitlement tx id: %w", err)
+	}
+	txID := fmt.Sprintf("chain-entitlement-%d-%d-%s", in.ChainID, in.BuyerID, nonce[:8])
+
+	grantResult, err := tx.ExecContext(ctx, `
+		INSERT INTO chain_entitlements (
+			user_id, chain_id, kind, uses_remaining, expires_at, granted_tx_id
+		) VALUES (?, ?, ?, ?, ?, ?)
+	`, in.BuyerID, in.ChainID, in.Kind, usesRemaining, expiresAt, txID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to grant entitlement: %w", err)
+	}
+	entitlementID, _ := grantResult.LastInsertId()
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit entitlement purchase: %w", err)
+	}
+
+	issued := s.issueReceiptBestEffort(ctx, issueReceiptInput{
+		TxID:     txID,
+		BuyerID:  in.BuyerID,
+		SellerID: chain.CreatorID,
+		ItemType: "reasoning_chain_entitlement",
+		ItemID:   chain.ID,
+		Price:    price,
+	})
+
+	return &PurchaseReasoningChainResult{
+		EntitlementID: entitlementID,
+		Chain:         chain,
+		Kind:          in.Kind,
+		Receipt:       issued,
+	}, nil
+}
+
+// RequireChainAccess checks whether userID may use chainID, whose creator
+// is creatorID, and records an audit row in chain_usage_events.
+//
+// Creators always have access. Everyone else needs a live
+// chain_entitlements grant: unlimited and unexpired subscription grants
+// are checked without being consumed, while a per_use grant's
+// uses_remaining is decremented atomically as part of the same
+// transaction as the usage event, so two concurrent uses of a grant with
+// one use left can't both succeed.
+func (s *Service) RequireChainAccess(ctx context.Context, userID, chainID, creatorID int) error {
+	if userID == creatorID {
+		if _, err := s.db.ExecContext(ctx, `
+			INSERT INTO chain_usage_events (user_id, chain_id, entitlement_id, used_at)
+			VALUES (?, ?, NULL, NOW())
+		`, userID, chainID); err != nil {
+			log.Printf("reasoning-chain: failed to record creator usage event for chain %d: %v", chainID, err)
+		}
+		return nil
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var entitlementID int64
+	var kind string
+	err = tx.QueryRowContext(ctx, `
+		SELECT id, kind
+		FROM chain_entitlements
+		WHERE user_id = ? AND chain_id = ?
+		AND (kind = 'unlimited'
+			OR (kind = 'subscription' AND (expires_at IS NULL OR expires_at > NOW()))
+			OR (kind = 'per_use' AND uses_remaining > 0))
+		ORDER BY FIELD(kind, 'unlimited', 'subscription', 'per_use')
+		LIMIT 1
+		FOR UPDATE
+	`, userID, chainID).Scan(&entitlementID, &kind)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrChainAccessDenied
+	}
+	if err != nil {
+		return fmt.Errorf("database error: %w", err)
+	}
+
+	if kind == EntitlementPerUse {
+		res, err := tx.ExecContext(ctx, `
+			UPDATE chain_entitlements
+			SET uses_remaining = uses_remaining - 1
+			WHERE id = ? AND uses_remaining > 0
+		`, entitlementID)
+		if err != nil {
+			return fmt.Errorf("failed to decrement entitlement: %w", err)
+		}
+		if affected, _ := res.RowsAffected(); affected == 0 {
+			return ErrChainAccessDenied
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO chain_usage_events (user_id, chain_id, entitlement_id, used_at)
+		VALUES (?, ?, ?, NOW())
+	`, userID, chainID, entitlementID); err != nil {
+		return fmt.Errorf("failed to record usage event: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// GetMyEntitlementsInput is the business-logic input for GetMyEntitlements.
+type GetMyEntitlementsInput struct {
+	UserID int
+}
+
+// GetMyEntitlementsResult is the outcome of a GetMyEntitlements call.
+type GetMyEntitlementsResult struct {
+	Entitlements []models.ChainEntitlement
+}
+
+// GetMyEntitlements lists every reasoning-chain entitlement in.UserID has
+// ever been granted, including expired or exhausted ones, so a user can
+// audit their purchase history alongside what's still usable.
+func (s *Service) GetMyEntitlements(ctx context.Context, in GetMyEntitlementsInput) (*GetMyEntitlementsResult, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, chain_id, kind, uses_remaining, expires_at, granted_tx_id
+		FROM chain_entitlements
+		WHERE user_id = ?
+		ORDER BY id DESC
+	`, in.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	defer rows.Close()
+
+	var entitlements []models.ChainEntitlement
+	for rows.Next() {
+		var e models.ChainEntitlement
+		var usesRemaining sql.NullInt64
+		var expiresAt sql.NullTime
+		if err := rows.Scan(&e.ID, &e.ChainID, &e.Kind, &usesRemaining, &expiresAt, &e.GrantedTxID); err != nil {
+			continue
+		}
+		if usesRemaining.Valid {
+			uses := int(usesRemaining.Int64)
+			e.UsesRemaining = &uses
+		}
+		if expiresAt.Valid {
+			e.ExpiresAt = &expiresAt.Time
+		}
+		entitlements = append(entitlements, e)
+	}
+
+	return &GetMyEntitlementsResult{Entitlements: entitlements}, nil
+}
@@ -0,0 +1,104 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"testing"
+)
+
+// TestRequireChainAccess_ConcurrentUsesRemainingRace fires many simultaneous
+// RequireChainAccess calls against a per_use entitlement with a single use
+// left and asserts that exactly one caller succeeds - the conditional
+// UPDATE ... WHERE uses_remaining > 0 inside RequireChainAccess's
+// transaction is what's supposed to serialize these instead of letting
+// every caller consume the same last use.
+func TestRequireChainAccess_ConcurrentUsesRemainingRace(t *testing.T) {
+	db := testDB(t)
+	ctx := context.Background()
+	svc := New(db)
+
+	const numCallers = 10
+
+	res, err := db.ExecContext(ctx, `INSERT INTO users (balance) VALUES (0)`)
+	if err != nil {
+		t.Fatalf("insert creator: %v", err)
+	}
+	creatorID, _ := res.LastInsertId()
+
+	res, err = db.ExecContext(ctx, `INSERT INTO users (balance) VALUES (0)`)
+	if err != nil {
+		t.Fatalf("insert user: %v", err)
+	}
+	userID, _ := res.LastInsertId()
+
+	chainRes, err := db.ExecContext(ctx, `
+		INSERT INTO reasoning_chains (creator_id, chain_type, chain_data, price, status, created_at)
+		VALUES (?, 'test-chain', '{}', 1, 'available', NOW())
+	`, creatorID)
+	if err != nil {
+		t.Fatalf("insert reasoning chain: %v", err)
+	}
+	chainID, _ := chainRes.LastInsertId()
+
+	entRes, err := db.ExecContext(ctx, `
+		INSERT INTO chain_entitlements (user_id, chain_id, kind, uses_remaining, expires_at, granted_tx_id)
+		VALUES (?, ?, ?, 1, NULL, 'test-entitlement')
+	`, userID, chainID, EntitlementPerUse)
+	if err != nil {
+		t.Fatalf("insert entitlement: %v", err)
+	}
+	entitlementID, _ := entRes.LastInsertId()
+
+	t.Cleanup(func() {
+		db.ExecContext(ctx, `DELETE FROM chain_usage_events WHERE chain_id = ?`, chainID)
+		db.ExecContext(ctx, `DELETE FROM chain_entitlements WHERE id = ?`, entitlementID)
+		db.ExecContext(ctx, `DELETE FROM reasoning_chains WHERE id = ?`, chainID)
+		db.ExecContext(ctx, `DELETE FROM users WHERE id IN (?, ?)`, creatorID, userID)
+	})
+
+	var wg sync.WaitGroup
+	results := make([]error, numCallers)
+	for i := 0; i < numCallers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i] = svc.RequireChainAccess(ctx, int(userID), int(chainID), int(creatorID))
+		}(i)
+	}
+	wg.Wait()
+
+	var succeeded int
+	for i, err := range results {
+		switch err {
+		case nil:
+			succeeded++
+		case ErrChainAccessDenied:
+			// expected for everyone who lost the race
+		default:
+			t.Errorf("caller %d: unexpected error: %v", i, err)
+		}
+	}
+	if succeeded != 1 {
+		t.Errorf("got %d successful chain accesses for a single use_remaining, want exactly 1", succeeded)
+	}
+
+	var usesRemaining int
+	if err := db.QueryRowContext(ctx,
+		`SELECT uses_remaining FROM chain_entitlements WHERE id = ?`, entitlementID,
+	).Scan(&usesRemaining); err != nil {
+		t.Fatalf("read final uses_remaining: %v", err)
+	}
+	if usesRemaining != 0 {
+		t.Errorf("uses_remaining = %d, want 0", usesRemaining)
+	}
+
+	var usageEvents int
+	if err := db.QueryRowContext(ctx,
+		`SELECT COUNT(*) FROM chain_usage_events WHERE chain_id = ? AND entitlement_id = ?`, chainID, entitlementID,
+	).Scan(&usageEvents); err != nil {
+		t.Fatalf("count usage events: %v", err)
+	}
+	if usageEvents != 1 {
+		t.Errorf("chain_usage_events rows = %d, want 1", usageEvents)
+	}
+}
@@ -0,0 +1,402 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/awareness/memory-exchange/internal/models"
+)
+
+// reservationTTL is how long a reservation holds a memory (and the buyer's
+// funds) before reapExpiredReservations releases it back to the pool.
+const reservationTTL = 15 * time.Minute
+
+// ErrInsufficientBalance is returned by ReserveMemory when the buyer's
+// account balance can't cover the memory's price.
+var ErrInsufficientBalance = errors.New("insufficient balance")
+
+// ErrReservationNotFound is returned by ConfirmMemory and RefundMemory when
+// no escrow_transactions row matches the requested ID.
+var ErrReservationNotFound = errors.New("reservation not found")
+
+// ErrReservationNotOwned is returned by ConfirmMemory and RefundMemory when
+// the caller didn't create the reservation.
+var ErrReservationNotOwned = errors.New("reservation does not belong to this buyer")
+
+// ErrReservationNotPending is returned by ConfirmMemory and RefundMemory
+// when the reservation has already left the "reserved" state (confirmed,
+// refunded, or reaped).
+var ErrReservationNotPending = errors.New("reservation is not pending")
+
+// ErrReservationExpired is returned by ConfirmMemory when the reservation's
+// hold has already timed out; the caller must reserve again.
+var ErrReservationExpired = errors.New("reservation has expired")
+
+// ReserveMemoryInput is the business-logic input for ReserveMemory.
+type ReserveMemoryInput struct {
+	BuyerID  int
+	MemoryID int
+}
+
+// ReserveMemoryResult is the outcome of a successful ReserveMemory call.
+type ReserveMemoryResult struct {
+	ReservationID int64
+	Memory        models.MemoryExchange
+	ExpiresAt     time.Time
+}
+
+// ReserveMemory places the memory identified by in.MemoryID on hold for
+// in.BuyerID: it checks out the listing row, deducts the buyer's balance,
+// and records the hold as an escrow_transactions row in "reserved" state,
+// all inside one transaction so a concurrent reserve of the same memory
+// either blocks on the row lock or sees it already unavailable.
+//
+// The listing transitions available -> reserved. It reverts to available
+// (and the buyer is refunded) if the reservation is refunded directly or
+// reaped by reapExpiredReservations after reservationTTL elapses.
+func (s *Service) ReserveMemory(ctx context.Context, in ReserveMemoryInput) (*ReserveMemoryResult, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var memory models.MemoryExchange
+	err = tx.QueryRowContext(ctx, `
+		SELECT id, seller_id, memory_type, kv_cache_data, price, status
+		FROM memory_exchanges
+		WHERE id = ?
+		FOR UPDATE
+	`, in.MemoryID).Scan(
+		&memory.ID,
+		&memory.SellerID,
+		&memory.MemoryType,
+		&memory.KVCacheData,
+		&memory.Price,
+		&memory.Status,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrMemoryNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+
+	if memory.Status != "available" {
+		return nil, ErrMemoryUnavailable
+	}
+
+	res, err := tx.ExecContext(ctx,
+		`UPDATE users SET balance = balance - ? WHERE id = ? AND balance >= ?`,
+		memory.Price, in.BuyerID, memory.Price,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hold buyer balance: %w", err)
+	}
+	if affected, _ := res.RowsAffected(); affected == 0 {
+		return nil, ErrInsufficientBalance
+	}
+
+	expiresAt := time.Now().Add(reservationTTL)
+	ledgerResult, err := tx.ExecContext(ctx, `
+		INSERT INTO escrow_transactions (
+			memory_id, buyer_id, seller_id, amount, state, reserved_at, expires_at
+		) VALUES (?, ?, ?, ?, 'reserved', NOW(), ?)
+	`, memory.ID, in.BuyerID, memory.SellerID, memory.Price, expiresAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to record reservation: %w", err)
+	}
+	reservationID, _ := ledgerResult.LastInsertId()
+
+	if _, err := tx.ExecContext(ctx,
+		`UPDATE memory_exchanges SET status = 'reserved' WHERE id = ?`, memory.ID,
+	); err != nil {
+		return nil, fmt.Errorf("failed to reserve listing: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit reservation: %w", err)
+	}
+
+	memory.BuyerID = in.BuyerID
+	memory.Status = "reserved"
+	return &ReserveMemoryResult{ReservationID: reservationID, Memory: memory, ExpiresAt: expiresAt}, nil
+}
+
+// ConfirmMemoryInput is the business-logic input for ConfirmMemory.
+type ConfirmMemoryInput struct {
+	BuyerID       int
+	ReservationID int64
+}
+
+// ConfirmMemoryResult is the outcome of a successful ConfirmMemory call.
+type ConfirmMemoryResult struct {
+	ReservationID int64
+	Memory        models.MemoryExchange
+	// Receipt is a signed proof-of-purchase the buyer can present off
+	// service, or nil if issuing/persisting it failed (the purchase
+	// itself still succeeds; see issueReceiptBestEffort).
+	Receipt *IssuedReceipt
+}
+
+// ConfirmMemory settles a reservation: the escrowed amount is paid out to
+// the seller and the listing is marked released. The reservation moves
+// reserved -> paid -> released within a single transaction so the ledger
+// records both the payment and the release even though callers only see
+// one RPC.
+func (s *Service) ConfirmMemory(ctx context.Context, in ConfirmMemoryInput) (*ConfirmMemoryResult, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	reservation, err := lockReservation(ctx, tx, in.ReservationID)
+	if err != nil {
+		return nil, err
+	}
+	if reservation.buyerID != in.BuyerID {
+		return nil, ErrReservationNotOwned
+	}
+	if reservation.state != "reserved" {
+		return nil, ErrReservationNotPending
+	}
+	if time.Now().After(reservation.expiresAt) {
+		return nil, ErrReservationExpired
+	}
+
+	if _, err := tx.ExecContext(ctx,
+		`UPDATE escrow_transactions SET state = 'paid', confirmed_at = NOW() WHERE id = ?`, in.ReservationID,
+	); err != nil {
+		return nil, fmt.Errorf("failed to mark reservation paid: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx,
+		`UPDATE users SET balance = balance + ? WHERE id = ?`, reservation.amount, reservation.sellerID,
+	); err != nil {
+		return nil, fmt.Errorf("failed to release funds to seller: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx,
+		`UPDATE escrow_transactions SET state = 'released', released_at = NOW() WHERE id = ?`, in.ReservationID,
+	); err != nil {
+		return nil, fmt.Errorf("failed to mark reservation released: %w", err)
+	}
+
+	var memory models.MemoryExchange
+	err = tx.QueryRowContext(ctx, `
+		SELECT id, seller_id, memory_type, kv_cache_data, price, status
+		FROM memory_exchanges
+		WHERE id = ?
+		FOR UPDATE
+	`, reservation.memoryID).Scan(
+		&memory.ID,
+		&memory.SellerID,
+		&memory.MemoryType,
+		&memory.KVCacheData,
+		&memory.Price,
+		&memory.Status,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx,
+		`UPDATE memory_exchanges SET buyer_id = ?, status = 'released' WHERE id = ?`, in.BuyerID, memory.ID,
+	); err != nil {
+		return nil, fmt.Errorf("failed to release listing: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit confirmation: %w", err)
+	}
+
+	memory.BuyerID = in.BuyerID
+	memory.Status = "released"
+
+	go s.deliverPurchaseToRelay(in.BuyerID, memory)
+
+	txID := fmt.Sprintf("mem-%d", in.ReservationID)
+	issued := s.issueReceiptBestEffort(ctx, issueReceiptInput{
+		TxID:     txID,
+		BuyerID:  in.BuyerID,
+		SellerID: memory.SellerID,
+		ItemType: "memory",
+		ItemID:   memory.ID,
+		Price:    memory.Price,
+	})
+
+	return &ConfirmMemoryResult{ReservationID: in.ReservationID, Memory: memory, Receipt: issued}, nil
+}
+
+// RefundMemoryInput is the business-logic input for RefundMemory.
+type RefundMemoryInput struct {
+	BuyerID       int
+	ReservationID int64
+}
+
+// RefundMemoryResult is the outcome of a successful RefundMemory call.
+type RefundMemoryResult struct {
+	ReservationID int64
+}
+
+// RefundMemory cancels a pending reservation: the held balance is returned
+// to the buyer and the listing reverts to available for other buyers.
+func (s *Service) RefundMemory(ctx context.Context, in RefundMemoryInput) (*RefundMemoryResult, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	reservation, err := lockReservation(ctx, tx, in.ReservationID)
+	if err != nil {
+		return nil, err
+	}
+	if reservation.buyerID != in.BuyerID {
+		return nil, ErrReservationNotOwned
+	}
+	if reservation.state != "reserved" {
+		return nil, ErrReservationNotPending
+	}
+
+	if err := refundReservation(ctx, tx, reservation); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit refund: %w", err)
+	}
+
+	return &RefundMemoryResult{ReservationID: in.ReservationID}, nil
+}
+
+// heldReservation is an escrow_transactions row locked for update by
+// lockReservation.
+type heldReservation struct {
+	id        int64
+	memoryID  int
+	buyerID   int
+	sellerID  int
+	amount    float64
+	state     string
+	expiresAt time.Time
+}
+
+// lockReservation reads and row-locks the escrow_transactions row
+// identified by id within tx, so a concurrent confirm/refund/reap of the
+// same reservation serializes on the lock instead of racing.
+func lockReservation(ctx context.Context, tx *sql.Tx, id int64) (*heldReservation, error) {
+	var r heldReservation
+	err := tx.QueryRowContext(ctx, `
+		SELECT id, memory_id, buyer_id, seller_id, amount, state, expires_at
+		FROM escrow_transactions
+		WHERE id = ?
+		FOR UPDATE
+	`, id).Scan(&r.id, &r.memoryID, &r.buyerID, &r.sellerID, &r.amount, &r.state, &r.expiresAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrReservationNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	return &r, nil
+}
+
+// refundReservation moves a locked, still-reserved reservation to
+// "refunded": the held balance is returned to the buyer and the listing
+// reverts to available. Callers (RefundMemory, reapExpiredReservations)
+// are responsible for the surrounding transaction and its commit.
+func refundReservation(ctx context.Context, tx *sql.Tx, reservation *heldReservation) error {
+	if _, err := tx.ExecContext(ctx,
+		`UPDATE escrow_transactions SET state = 'refunded', refunded_at = NOW() WHERE id = ?`, reservation.id,
+	); err != nil {
+		return fmt.Errorf("failed to mark reservation refunded: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx,
+		`UPDATE users SET balance = balance + ? WHERE id = ?`, reservation.amount, reservation.buyerID,
+	); err != nil {
+		return fmt.Errorf("failed to refund buyer: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx,
+		`UPDATE memory_exchanges SET status = 'available' WHERE id = ?`, reservation.memoryID,
+	); err != nil {
+		return fmt.Errorf("failed to release listing: %w", err)
+	}
+
+	return nil
+}
+
+// RunEscrowReaper periodically refunds reservations whose hold has expired
+// before a confirm or refund call arrived, so a buyer who disappears
+// mid-checkout doesn't keep a listing (and their own funds) locked up
+// forever. It runs until ctx is canceled.
+func (s *Service) RunEscrowReaper(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.reapExpiredReservations(ctx)
+		}
+	}
+}
+
+func (s *Service) reapExpiredReservations(ctx context.Context) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT id FROM escrow_transactions WHERE state = 'reserved' AND expires_at < NOW()`,
+	)
+	if err != nil {
+		log.Printf("escrow: reaper query failed: %v", err)
+		return
+	}
+	var expired []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			continue
+		}
+		expired = append(expired, id)
+	}
+	rows.Close()
+
+	for _, id := range expired {
+		if err := s.reapOne(ctx, id); err != nil {
+			log.Printf("escrow: failed to reap reservation %d: %v", id, err)
+		}
+	}
+}
+
+// reapOne re-locks reservation id and refunds it if it's still reserved and
+// still expired, guarding against a confirm/refund that raced the reaper's
+// initial scan.
+func (s *Service) reapOne(ctx context.Context, id int64) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	reservation, err := lockReservation(ctx, tx, id)
+	if err != nil {
+		return err
+	}
+	if reservation.state != "reserved" || time.Now().Before(reservation.expiresAt) {
+		return nil
+	}
+
+	if err := refundReservation(ctx, tx, reservation); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,128 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"sync"
+	"testing"
+
+	_ "github.com/go-sql-driver/mysql"
+)
+
+// testDB opens the MySQL instance named by TEST_DATABASE_URL, skipping the
+// test when it isn't set. There's no in-repo schema/migration tooling for
+// memory-exchange (unlike recommendation-engine), so this expects the
+// users/memory_exchanges/escrow_transactions tables already exist, the same
+// way the service itself does in every other environment it runs in.
+func testDB(t *testing.T) *sql.DB {
+	t.Helper()
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL not set; skipping test against a real MySQL instance")
+	}
+	db, err := sql.Open("mysql", dsn)
+	if err != nil {
+		t.Fatalf("open test database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	if err := db.Ping(); err != nil {
+		t.Fatalf("ping test database: %v", err)
+	}
+	return db
+}
+
+// TestReserveMemory_ConcurrentReservationsOnSameMemory fires many
+// simultaneous ReserveMemory calls at the same memory_id and asserts that
+// exactly one wins the reservation - the SELECT ... FOR UPDATE on the
+// listing row inside ReserveMemory's transaction is what's supposed to
+// serialize these instead of letting every caller "buy" the same memory.
+func TestReserveMemory_ConcurrentReservationsOnSameMemory(t *testing.T) {
+	db := testDB(t)
+	ctx := context.Background()
+	svc := New(db)
+
+	const numBuyers = 10
+	const price = 10.0
+
+	res, err := db.ExecContext(ctx, `INSERT INTO users (balance) VALUES (0)`)
+	if err != nil {
+		t.Fatalf("insert seller: %v", err)
+	}
+	sellerID, _ := res.LastInsertId()
+
+	buyerIDs := make([]int64, numBuyers)
+	for i := range buyerIDs {
+		res, err := db.ExecContext(ctx, `INSERT INTO users (balance) VALUES (?)`, price)
+		if err != nil {
+			t.Fatalf("insert buyer %d: %v", i, err)
+		}
+		buyerIDs[i], _ = res.LastInsertId()
+	}
+
+	memRes, err := db.ExecContext(ctx, `
+		INSERT INTO memory_exchanges (seller_id, memory_type, kv_cache_data, price, status, created_at)
+		VALUES (?, 'kv_cache', '{}', ?, 'available', NOW())
+	`, sellerID, price)
+	if err != nil {
+		t.Fatalf("insert memory listing: %v", err)
+	}
+	memoryID, _ := memRes.LastInsertId()
+
+	t.Cleanup(func() {
+		db.ExecContext(ctx, `DELETE FROM escrow_transactions WHERE memory_id = ?`, memoryID)
+		db.ExecContext(ctx, `DELETE FROM memory_exchanges WHERE id = ?`, memoryID)
+		db.ExecContext(ctx, `DELETE FROM users WHERE id = ?`, sellerID)
+		for _, id := range buyerIDs {
+			db.ExecContext(ctx, `DELETE FROM users WHERE id = ?`, id)
+		}
+	})
+
+	var wg sync.WaitGroup
+	results := make([]error, numBuyers)
+	for i, buyerID := range buyerIDs {
+		wg.Add(1)
+		go func(i int, buyerID int64) {
+			defer wg.Done()
+			_, err := svc.ReserveMemory(ctx, ReserveMemoryInput{
+				BuyerID:  int(buyerID),
+				MemoryID: int(memoryID),
+			})
+			results[i] = err
+		}(i, buyerID)
+	}
+	wg.Wait()
+
+	var succeeded int
+	for i, err := range results {
+		switch err {
+		case nil:
+			succeeded++
+		case ErrMemoryUnavailable:
+			// expected for everyone who lost the race
+		default:
+			t.Errorf("buyer %d: unexpected error: %v", i, err)
+		}
+	}
+	if succeeded != 1 {
+		t.Errorf("got %d successful reservations for the same memory_id, want exactly 1", succeeded)
+	}
+
+	var status string
+	if err := db.QueryRowContext(ctx, `SELECT status FROM memory_exchanges WHERE id = ?`, memoryID).Scan(&status); err != nil {
+		t.Fatalf("read final listing status: %v", err)
+	}
+	if status != "reserved" {
+		t.Errorf("listing status = %q, want %q", status, "reserved")
+	}
+
+	var reservedCount int
+	if err := db.QueryRowContext(ctx,
+		`SELECT COUNT(*) FROM escrow_transactions WHERE memory_id = ? AND state = 'reserved'`, memoryID,
+	).Scan(&reservedCount); err != nil {
+		t.Fatalf("count reservations: %v", err)
+	}
+	if reservedCount != 1 {
+		t.Errorf("reserved escrow_transactions rows = %d, want 1", reservedCount)
+	}
+}
@@ -0,0 +1,247 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+
+	"github.com/awareness/memory-exchange/internal/models"
+	"github.com/awareness/memory-exchange/internal/relayapi"
+	"github.com/awareness/memory-exchange/internal/vectorclient"
+)
+
+// ErrMemoryNotFound is returned by ReserveMemory when no memory_exchanges
+// row matches the requested ID.
+var ErrMemoryNotFound = errors.New("memory not found")
+
+// ErrMemoryUnavailable is returned by ReserveMemory when the memory exists
+// but isn't in the "available" state (already reserved, sold, etc).
+var ErrMemoryUnavailable = errors.New("memory is not available for purchase")
+
+// PublishMemoryInput is the business-logic input for PublishMemory.
+type PublishMemoryInput struct {
+	SellerID    int
+	MemoryType  string
+	KVCacheData map[string]interface{}
+	Price       float64
+}
+
+// PublishMemoryResult is the outcome of a successful PublishMemory call.
+type PublishMemoryResult struct {
+	MemoryID int64
+}
+
+// PublishMemory records a new KV-Cache memory as pending, ready for a buyer
+// to purchase.
+func (s *Service) PublishMemory(ctx context.Context, in PublishMemoryInput) (*PublishMemoryResult, error) {
+	kvCacheJSON, err := json.Marshal(in.KVCacheData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize KV cache data: %w", err)
+	}
+
+	query := `
+		INSERT INTO memory_exchanges (
+			seller_id, memory_type, kv_cache_data, price, status, created_at
+		) VALUES (?, ?, ?, ?, 'pending', NOW())
+	`
+
+	result, err := s.db.ExecContext(ctx, query, in.SellerID, in.MemoryType, string(kvCacheJSON), in.Price)
+	if err != nil {
+		return nil, fmt.Errorf("failed to publish memory: %w", err)
+	}
+
+	memoryID, _ := result.LastInsertId()
+	return &PublishMemoryResult{MemoryID: memoryID}, nil
+}
+
+// deliverPurchaseToRelay seals the purchased memory for the buyer's
+// published X25519 key and enqueues it to their registered relays. It
+// logs and returns on any failure rather than surfacing an error to the
+// purchase request, since relay delivery is a supplementary delivery path
+// alongside the presigned download URL.
+func (s *Service) deliverPurchaseToRelay(buyerID int, memory models.MemoryExchange) {
+	var pubKeyHex sql.NullString
+	err := s.db.QueryRow(`SELECT buyer_pubkey FROM users WHERE id = ?`, buyerID).Scan(&pubKeyHex)
+	if err != nil || !pubKeyHex.Valid || pubKeyHex.String == "" {
+		return
+	}
+
+	pubKey, err := hex.DecodeString(pubKeyHex.String)
+	if err != nil {
+		log.Printf("relay: buyer %d has an invalid published pubkey: %v", buyerID, err)
+		return
+	}
+
+	payload, err := json.Marshal(memory)
+	if err != nil {
+		log.Printf("relay: failed to encode memory %d for relay delivery: %v", memory.ID, err)
+		return
+	}
+
+	if err := relayapi.Deliver(buyerID, payload, pubKey); err != nil {
+		log.Printf("relay: failed to enqueue memory %d for buyer %d: %v", memory.ID, buyerID, err)
+	}
+}
+
+// BrowseMemoriesInput is the business-logic input for BrowseMemories.
+type BrowseMemoriesInput struct {
+	MemoryType string
+	MinPrice   *float64
+	MaxPrice   *float64
+	Limit      int
+	Offset     int
+	// SimilarTo, when set, is a memory ID (assumed to also identify its
+	// embedding in vector-operations) used to rank results by similarity.
+	SimilarTo string
+}
+
+// BrowseMemoriesResult is the outcome of a BrowseMemories call.
+type BrowseMemoriesResult struct {
+	Memories []models.MemoryExchange
+}
+
+// BrowseMemories lists available memories, optionally filtered by type and
+// price range and ranked by similarity to SimilarTo.
+func (s *Service) BrowseMemories(ctx context.Context, in BrowseMemoriesInput) (*BrowseMemoriesResult, error) {
+	query := `
+		SELECT id, seller_id, memory_type, price, status, created_at
+		FROM memory_exchanges
+		WHERE status = 'available'
+	`
+
+	var args []interface{}
+
+	if in.MemoryType != "" {
+		query += " AND memory_type = ?"
+		args = append(args, in.MemoryType)
+	}
+
+	if in.MinPrice != nil {
+		query += " AND price >= ?"
+		args = append(args, *in.MinPrice)
+	}
+
+	if in.MaxPrice != nil {
+		query += " AND price <= ?"
+		args = append(args, *in.MaxPrice)
+	}
+
+	query += " ORDER BY created_at DESC LIMIT ? OFFSET ?"
+	args = append(args, in.Limit, in.Offset)
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	defer rows.Close()
+
+	var memories []models.MemoryExchange
+	for rows.Next() {
+		var memory models.MemoryExchange
+		if err := rows.Scan(
+			&memory.ID,
+			&memory.SellerID,
+			&memory.MemoryType,
+			&memory.Price,
+			&memory.Status,
+			&memory.CreatedAt,
+		); err != nil {
+			continue
+		}
+		memories = append(memories, memory)
+	}
+
+	if in.SimilarTo != "" {
+		memories = rankBySimilarity(memories, in.SimilarTo)
+	}
+
+	return &BrowseMemoriesResult{Memories: memories}, nil
+}
+
+// rankBySimilarity reorders memories by similarity to similarTo (a memory
+// ID, assumed to also identify its embedding in vector-operations) using
+// the ANN index there. Memories outside the similarity results keep their
+// original relative order, appended after the ranked ones, so a
+// vector-operations outage degrades to the existing sort instead of
+// dropping results.
+func rankBySimilarity(memories []models.MemoryExchange, similarTo string) []models.MemoryExchange {
+	similarIDs, err := vectorclient.SimilarVectorIDs(similarTo, len(memories))
+	if err != nil {
+		return memories
+	}
+
+	rank := make(map[string]int, len(similarIDs))
+	for i, id := range similarIDs {
+		rank[id] = i
+	}
+
+	ranked := make([]models.MemoryExchange, 0, len(memories))
+	rest := make([]models.MemoryExchange, 0, len(memories))
+	for _, m := range memories {
+		if _, ok := rank[fmt.Sprintf("mem-%d", m.ID)]; ok {
+			ranked = append(ranked, m)
+		} else {
+			rest = append(rest, m)
+		}
+	}
+
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return rank[fmt.Sprintf("mem-%d", ranked[i].ID)] < rank[fmt.Sprintf("mem-%d", ranked[j].ID)]
+	})
+
+	return append(ranked, rest...)
+}
+
+// GetMyHistoryInput is the business-logic input for GetMyHistory.
+type GetMyHistoryInput struct {
+	UserID int
+	Limit  int
+	Offset int
+}
+
+// GetMyHistoryResult is the outcome of a GetMyHistory call.
+type GetMyHistoryResult struct {
+	Transactions []models.MemoryExchange
+}
+
+// GetMyHistory lists every memory transaction (purchases and sales)
+// involving in.UserID.
+func (s *Service) GetMyHistory(ctx context.Context, in GetMyHistoryInput) (*GetMyHistoryResult, error) {
+	query := `
+		SELECT id, seller_id, buyer_id, memory_type, price, status, created_at
+		FROM memory_exchanges
+		WHERE seller_id = ? OR buyer_id = ?
+		ORDER BY created_at DESC
+		LIMIT ? OFFSET ?
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, in.UserID, in.UserID, in.Limit, in.Offset)
+	if err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	defer rows.Close()
+
+	var transactions []models.MemoryExchange
+	for rows.Next() {
+		var tx models.MemoryExchange
+		if err := rows.Scan(
+			&tx.ID,
+			&tx.SellerID,
+			&tx.BuyerID,
+			&tx.MemoryType,
+			&tx.Price,
+			&tx.Status,
+			&tx.CreatedAt,
+		); err != nil {
+			continue
+		}
+		transactions = append(transactions, tx)
+	}
+
+	return &GetMyHistoryResult{Transactions: transactions}, nil
+}
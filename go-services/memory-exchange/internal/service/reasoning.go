@@ -0,0 +1,192 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/awareness/memory-exchange/internal/models"
+)
+
+// ErrChainNotFound is returned by UseReasoningChain when no reasoning_chains
+// row matches the requested ID.
+var ErrChainNotFound = errors.New("reasoning chain not found")
+
+// ErrChainAccessDenied is returned by UseReasoningChain when the caller
+// neither created the chain nor has purchased access to it.
+var ErrChainAccessDenied = errors.New("access denied: you must purchase this reasoning chain")
+
+// PublishReasoningChainInput is the business-logic input for
+// PublishReasoningChain.
+type PublishReasoningChainInput struct {
+	CreatorID       int
+	Category        string
+	KVCacheSnapshot map[string]interface{}
+	PricePerUse     float64
+}
+
+// PublishReasoningChainResult is the outcome of a successful
+// PublishReasoningChain call.
+type PublishReasoningChainResult struct {
+	ChainID int64
+}
+
+// PublishReasoningChain records a new reasoning chain as available for use.
+func (s *Service) PublishReasoningChain(ctx context.Context, in PublishReasoningChainInput) (*PublishReasoningChainResult, error) {
+	chainDataJSON, err := json.Marshal(in.KVCacheSnapshot)
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize chain data: %w", err)
+	}
+
+	query := `
+		INSERT INTO reasoning_chains (
+			creator_id, chain_type, chain_data, price, status, created_at
+		) VALUES (?, ?, ?, ?, 'available', NOW())
+	`
+
+	result, err := s.db.ExecContext(ctx, query, in.CreatorID, in.Category, string(chainDataJSON), in.PricePerUse)
+	if err != nil {
+		return nil, fmt.Errorf("failed to publish reasoning chain: %w", err)
+	}
+
+	chainID, _ := result.LastInsertId()
+	return &PublishReasoningChainResult{ChainID: chainID}, nil
+}
+
+// UseReasoningChainInput is the business-logic input for UseReasoningChain.
+type UseReasoningChainInput struct {
+	UserID  int
+	ChainID int
+}
+
+// UseReasoningChainResult is the outcome of a successful UseReasoningChain
+// call.
+type UseReasoningChainResult struct {
+	Chain models.ReasoningChain
+	// Receipt is a signed proof-of-access the caller can present off
+	// service, or nil if issuing/persisting it failed (access is still
+	// granted; see issueReceiptBestEffort).
+	Receipt *IssuedReceipt
+}
+
+// UseReasoningChain returns the reasoning chain identified by in.ChainID if
+// in.UserID has access to it — as creator or via RequireChainAccess — or
+// ErrChainAccessDenied otherwise.
+func (s *Service) UseReasoningChain(ctx context.Context, in UseReasoningChainInput) (*UseReasoningChainResult, error) {
+	var chain models.ReasoningChain
+	query := `
+		SELECT id, creator_id, category, kv_cache_snapshot, price_per_use, status
+		FROM reasoning_chains
+		WHERE id = ?
+	`
+
+	var kvCacheSnapshot sql.NullString
+	err := s.db.QueryRowContext(ctx, query, in.ChainID).Scan(
+		&chain.ID,
+		&chain.CreatorID,
+		&chain.Category,
+		&kvCacheSnapshot,
+		&chain.PricePerUse,
+		&chain.Status,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrChainNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+
+	if kvCacheSnapshot.Valid {
+		chain.KVCacheSnapshot = &kvCacheSnapshot.String
+	}
+
+	if err := s.RequireChainAccess(ctx, in.UserID, chain.ID, chain.CreatorID); err != nil {
+		return nil, err
+	}
+
+	nonce, err := randomNonce()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate receipt nonce: %w", err)
+	}
+	issued := s.issueReceiptBestEffort(ctx, issueReceiptInput{
+		TxID:     fmt.Sprintf("chain-%d-%d-%s", chain.ID, in.UserID, nonce[:8]),
+		BuyerID:  in.UserID,
+		SellerID: chain.CreatorID,
+		ItemType: "reasoning_chain",
+		ItemID:   chain.ID,
+		Price:    chain.PricePerUse,
+	})
+
+	return &UseReasoningChainResult{Chain: chain, Receipt: issued}, nil
+}
+
+// BrowseReasoningChainsInput is the business-logic input for
+// BrowseReasoningChains.
+type BrowseReasoningChainsInput struct {
+	ChainType string
+	MinPrice  *float64
+	MaxPrice  *float64
+	Limit     int
+	Offset    int
+}
+
+// BrowseReasoningChainsResult is the outcome of a BrowseReasoningChains call.
+type BrowseReasoningChainsResult struct {
+	Chains []models.ReasoningChain
+}
+
+// BrowseReasoningChains lists active reasoning chains, optionally filtered
+// by category and price range.
+func (s *Service) BrowseReasoningChains(ctx context.Context, in BrowseReasoningChainsInput) (*BrowseReasoningChainsResult, error) {
+	query := `
+		SELECT id, creator_id, category, price_per_use, status, created_at
+		FROM reasoning_chains
+		WHERE status = 'active'
+	`
+
+	var args []interface{}
+
+	if in.ChainType != "" {
+		query += " AND category = ?"
+		args = append(args, in.ChainType)
+	}
+
+	if in.MinPrice != nil {
+		query += " AND price >= ?"
+		args = append(args, *in.MinPrice)
+	}
+
+	if in.MaxPrice != nil {
+		query += " AND price <= ?"
+		args = append(args, *in.MaxPrice)
+	}
+
+	query += " ORDER BY created_at DESC LIMIT ? OFFSET ?"
+	args = append(args, in.Limit, in.Offset)
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	defer rows.Close()
+
+	var chains []models.ReasoningChain
+	for rows.Next() {
+		var chain models.ReasoningChain
+		if err := rows.Scan(
+			&chain.ID,
+			&chain.CreatorID,
+			&chain.Category,
+			&chain.PricePerUse,
+			&chain.Status,
+			&chain.CreatedAt,
+		); err != nil {
+			continue
+		}
+		chains = append(chains, chain)
+	}
+
+	return &BrowseReasoningChainsResult{Chains: chains}, nil
+}
@@ -0,0 +1,162 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/awareness/memory-exchange/internal/crypto/receipt"
+)
+
+// ErrReceiptNotFound is returned by VerifyReceipt when no receipts row
+// matches the requested transaction ID.
+var ErrReceiptNotFound = errors.New("receipt not found")
+
+// IssuedReceipt bundles a signed Receipt with the hex-encoded hash and
+// signature an API response (or a downstream verifier) actually needs.
+type IssuedReceipt struct {
+	Receipt   receipt.Receipt
+	Hash      string
+	Signature string
+}
+
+// issueReceiptInput is the business-logic input for issueReceipt.
+type issueReceiptInput struct {
+	TxID     string
+	BuyerID  int
+	SellerID int
+	ItemType string
+	ItemID   int
+	Price    float64
+}
+
+// issueReceipt signs a Receipt attesting that in.BuyerID holds in.ItemID
+// and persists its hash and signature in the receipts table so
+// VerifyReceipt can later confirm it against the service's published
+// public key without needing the original Receipt fields again.
+func (s *Service) issueReceipt(ctx context.Context, in issueReceiptInput) (*IssuedReceipt, error) {
+	nonce, err := randomNonce()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate receipt nonce: %w", err)
+	}
+
+	r := receipt.Receipt{
+		TxID:      in.TxID,
+		BuyerID:   in.BuyerID,
+		SellerID:  in.SellerID,
+		ItemType:  in.ItemType,
+		ItemID:    in.ItemID,
+		Price:     in.Price,
+		Timestamp: time.Now().UTC(),
+		Nonce:     nonce,
+	}
+
+	hash, signature, err := receipt.Sign(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign receipt: %w", err)
+	}
+	hashHex := hex.EncodeToString(hash)
+	signatureHex := hex.EncodeToString(signature)
+
+	_, err = s.db.ExecContext(ctx, `
+		INSERT INTO receipts (tx_id, receipt_hash, signature, created_at)
+		VALUES (?, ?, ?, NOW())
+	`, in.TxID, hashHex, signatureHex)
+	if err != nil {
+		return nil, fmt.Errorf("failed to persist receipt: %w", err)
+	}
+
+	return &IssuedReceipt{Receipt: r, Hash: hashHex, Signature: signatureHex}, nil
+}
+
+// issueReceiptBestEffort issues a receipt for an already-committed
+// transaction and logs on failure instead of returning an error, since the
+// underlying purchase/access has already succeeded by the time this runs.
+func (s *Service) issueReceiptBestEffort(ctx context.Context, in issueReceiptInput) *IssuedReceipt {
+	issued, err := s.issueReceipt(ctx, in)
+	if err != nil {
+		log.Printf("receipt: failed to issue receipt for tx %s: %v", in.TxID, err)
+		return nil
+	}
+	return issued
+}
+
+// randomNonce returns a random 16-byte value hex-encoded, used to make two
+// receipts for the same item distinguishable if one is ever reissued.
+func randomNonce() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// VerifyReceiptResult is the outcome of a VerifyReceipt call.
+type VerifyReceiptResult struct {
+	Valid bool
+}
+
+// VerifyReceipt looks up the hash and signature persisted for txID and
+// checks the signature against the service's published Ed25519 public key,
+// so a caller can confirm a receipt is genuine without trusting whatever
+// they were handed.
+func (s *Service) VerifyReceipt(ctx context.Context, txID string) (*VerifyReceiptResult, error) {
+	var hashHex, signatureHex string
+	err := s.db.QueryRowContext(ctx, `
+		SELECT receipt_hash, signature FROM receipts WHERE tx_id = ?
+	`, txID).Scan(&hashHex, &signatureHex)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrReceiptNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+
+	hash, err := hex.DecodeString(hashHex)
+	if err != nil {
+		return nil, fmt.Errorf("corrupt receipt hash for tx %s: %w", txID, err)
+	}
+	signature, err := hex.DecodeString(signatureHex)
+	if err != nil {
+		return nil, fmt.Errorf("corrupt receipt signature for tx %s: %w", txID, err)
+	}
+
+	valid, err := receipt.Verify(hash, signature)
+	if err != nil {
+		return nil, err
+	}
+	return &VerifyReceiptResult{Valid: valid}, nil
+}
+
+// VerifyExternalReceipt verifies a Receipt and signature a caller presents
+// directly, without looking anything up in this service's database. This is
+// what makes a receipt actually portable: a downstream service that was
+// simply handed r and signatureHex (e.g. by a buyer presenting it as proof
+// of access) can confirm it's genuine purely from the service's published
+// public key, the same way VerifyReceipt does for receipts this service
+// issued and stored itself.
+//
+// r's hash is recomputed rather than trusted from the caller, so a caller
+// can't pair a valid signature with tampered claim fields.
+func (s *Service) VerifyExternalReceipt(r receipt.Receipt, signatureHex string) (*VerifyReceiptResult, error) {
+	signature, err := hex.DecodeString(signatureHex)
+	if err != nil {
+		return nil, fmt.Errorf("invalid signature encoding: %w", err)
+	}
+
+	hash, err := receipt.Hash(r)
+	if err != nil {
+		return nil, err
+	}
+
+	valid, err := receipt.Verify(hash, signature)
+	if err != nil {
+		return nil, err
+	}
+	return &VerifyReceiptResult{Valid: valid}, nil
+}
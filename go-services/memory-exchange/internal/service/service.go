@@ -0,0 +1,18 @@
+// Package service holds the memory-exchange marketplace's business logic
+// independent of any transport. The REST handlers in internal/handlers and
+// the gRPC adapters in internal/api/grpc are both thin wrappers over these
+// methods: they translate their transport's request into a plain Go input
+// struct, call the matching Service method, and translate the result back.
+package service
+
+import "database/sql"
+
+// Service implements the marketplace operations against a MySQL-backed store.
+type Service struct {
+	db *sql.DB
+}
+
+// New creates a Service backed by db.
+func New(db *sql.DB) *Service {
+	return &Service{db: db}
+}
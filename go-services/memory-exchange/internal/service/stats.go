@@ -0,0 +1,25 @@
+package service
+
+import "context"
+
+// Stats summarizes the marketplace's overall activity.
+type Stats struct {
+	TotalMemories        int
+	AvailableMemories    int
+	TotalTransactions    int
+	TotalReasoningChains int
+	TotalVolume          float64
+}
+
+// GetStats computes overall marketplace statistics.
+func (s *Service) GetStats(ctx context.Context) (*Stats, error) {
+	var stats Stats
+
+	s.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM memory_exchanges").Scan(&stats.TotalMemories)
+	s.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM memory_exchanges WHERE status = 'available'").Scan(&stats.AvailableMemories)
+	s.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM memory_exchanges WHERE status = 'completed'").Scan(&stats.TotalTransactions)
+	s.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM reasoning_chains").Scan(&stats.TotalReasoningChains)
+	s.db.QueryRowContext(ctx, "SELECT COALESCE(SUM(price), 0) FROM memory_exchanges WHERE status = 'completed'").Scan(&stats.TotalVolume)
+
+	return &stats, nil
+}
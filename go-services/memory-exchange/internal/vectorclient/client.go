@@ -0,0 +1,54 @@
+// Package vectorclient is a minimal client for the vector-operations
+// service, used to rank BrowseMemories results by similarity when a
+// similar_to memory is given.
+package vectorclient
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+)
+
+var httpClient = &http.Client{Timeout: 3 * time.Second}
+
+func baseURL() string {
+	if v := os.Getenv("VECTOR_OPERATIONS_URL"); v != "" {
+		return v
+	}
+	return "http://localhost:8083"
+}
+
+// SimilarVectorIDs returns the IDs of vectors most similar to vectorID,
+// ordered by descending similarity, by calling vector-operations'
+// GET /api/v1/vectors/{id}/similar.
+func SimilarVectorIDs(vectorID string, topK int) ([]string, error) {
+	endpoint := fmt.Sprintf("%s/api/v1/vectors/%s/similar?top_k=%d", baseURL(), url.PathEscape(vectorID), topK)
+
+	resp, err := httpClient.Get(endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reach vector-operations: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("vector-operations returned status %d", resp.StatusCode)
+	}
+
+	var results []struct {
+		Vector struct {
+			ID string `json:"id"`
+		} `json:"vector"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&results); err != nil {
+		return nil, fmt.Errorf("failed to decode vector-operations response: %w", err)
+	}
+
+	ids := make([]string, len(results))
+	for i, r := range results {
+		ids[i] = r.Vector.ID
+	}
+	return ids, nil
+}
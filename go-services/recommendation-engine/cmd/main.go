@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"log"
 	"recommendation-engine/internal/config"
 	"recommendation-engine/internal/handlers"
@@ -27,7 +28,24 @@ func main() {
 		log.Fatal("Failed to load config:", err)
 	}
 
-	handler := handlers.NewHandler()
+	handler := handlers.NewHandler(cfg.DB, cfg.Events)
+
+	// Periodically retrain the collaborative filter's ALS model on the
+	// interactions tracked so far.
+	go handler.Recommender.Collaborative.RunRetraining(context.Background())
+
+	// Nightly-ish precomputation of the item-item top-K neighbor cache that
+	// FindSimilarItems reads from as its fast path.
+	go handler.Recommender.Collaborative.RunNeighborCacheRefresh(context.Background())
+
+	// Periodically flush the bandit ranker's dirty LinUCB arms to MySQL.
+	go handler.Recommender.Bandit.RunFlushing(context.Background())
+
+	// Consume marketplace ListingCreated events so newly listed items are
+	// known to the recommender before any interaction occurs.
+	if err := handler.SubscribeEvents(context.Background()); err != nil {
+		log.Printf("Failed to subscribe to events: %v", err)
+	}
 
 	router := gin.Default()
 
@@ -53,7 +71,10 @@ func main() {
 		{
 			recommendations.GET("", handler.GetRecommendations)
 			recommendations.GET("/similar", handler.GetSimilarItems)
+			recommendations.GET("/explain", handler.ExplainRecommendation)
 			recommendations.POST("/track", handler.TrackInteraction)
+			recommendations.POST("/batch-track", handler.BatchTrackInteractions)
+			recommendations.POST("/feedback", handler.RecommendationFeedback)
 		}
 	}
 
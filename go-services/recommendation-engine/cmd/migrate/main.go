@@ -0,0 +1,121 @@
+// Command migrate is the operator CLI for the recommendation-engine's
+// schema: applying and rolling back migrations, inspecting status, and
+// force-setting the recorded version after a manual fix. It talks to the
+// same DATABASE_URL the service itself uses and bypasses MIGRATION_MODE
+// entirely (every subcommand here runs SQL explicitly on request).
+//
+// Usage:
+//
+//	migrate up              # apply all pending migrations
+//	migrate up VERSION      # apply pending migrations up to and including VERSION
+//	migrate down N          # roll back the N most recently applied migrations
+//	migrate status          # list embedded migrations and their applied state
+//	migrate force VERSION   # record VERSION as applied without running its SQL
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+
+	"recommendation-engine/internal/database/migrations"
+
+	_ "github.com/go-sql-driver/mysql"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	dbURL := os.Getenv("DATABASE_URL")
+	if dbURL == "" {
+		log.Fatal("DATABASE_URL environment variable is not set")
+	}
+
+	db, err := sql.Open("mysql", dbURL)
+	if err != nil {
+		log.Fatalf("open database: %v", err)
+	}
+	defer db.Close()
+	if err := db.Ping(); err != nil {
+		log.Fatalf("ping database: %v", err)
+	}
+
+	migrator, err := migrations.NewMigrator(db)
+	if err != nil {
+		log.Fatalf("load migrations: %v", err)
+	}
+
+	ctx := context.Background()
+	args := os.Args[2:]
+
+	switch os.Args[1] {
+	case "up":
+		target := migrations.Latest
+		if len(args) > 0 {
+			target, err = strconv.Atoi(args[0])
+			if err != nil {
+				log.Fatalf("invalid version %q: %v", args[0], err)
+			}
+		}
+		if err := migrator.Up(ctx, target); err != nil {
+			log.Fatalf("up: %v", err)
+		}
+		fmt.Println("up: ok")
+
+	case "down":
+		if len(args) != 1 {
+			log.Fatal("usage: migrate down N")
+		}
+		steps, err := strconv.Atoi(args[0])
+		if err != nil || steps < 1 {
+			log.Fatalf("invalid step count %q", args[0])
+		}
+		if err := migrator.Down(ctx, steps); err != nil {
+			log.Fatalf("down: %v", err)
+		}
+		fmt.Println("down: ok")
+
+	case "status":
+		entries, err := migrator.Status(ctx)
+		if err != nil {
+			log.Fatalf("status: %v", err)
+		}
+		for _, e := range entries {
+			state := "pending"
+			switch {
+			case e.Applied && e.Drifted:
+				state = "applied (CHECKSUM DRIFT)"
+			case e.Applied:
+				state = fmt.Sprintf("applied at %s", e.AppliedAt.Format("2006-01-02 15:04:05"))
+			}
+			fmt.Printf("%04d_%s: %s\n", e.Migration.Version, e.Migration.Name, state)
+		}
+
+	case "force":
+		if len(args) != 1 {
+			log.Fatal("usage: migrate force VERSION")
+		}
+		version, err := strconv.Atoi(args[0])
+		if err != nil {
+			log.Fatalf("invalid version %q: %v", args[0], err)
+		}
+		if err := migrator.Force(ctx, version); err != nil {
+			log.Fatalf("force: %v", err)
+		}
+		fmt.Printf("force: recorded version %04d as applied\n", version)
+
+	default:
+		usage()
+		os.Exit(2)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: migrate up [VERSION] | down N | status | force VERSION")
+}
@@ -0,0 +1,387 @@
+package algorithms
+
+import (
+	"math"
+	"math/rand"
+	"os"
+	"recommendation-engine/internal/models"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	defaultALSFactors         = 64
+	defaultALSLambda          = 0.01
+	defaultALSAlpha           = 40.0
+	defaultALSIterations      = 15
+	defaultALSRetrainInterval = 10 * time.Minute
+)
+
+// alsConfig tunes the implicit-feedback ALS trainer. Each field is
+// overridable via an env var so a deployment can trade training cost
+// against recommendation freshness/quality without a code change.
+type alsConfig struct {
+	factors         int           // k, the latent dimension
+	lambda          float64       // L2 regularization
+	alpha           float64       // confidence scaling: c_ui = 1 + alpha*r_ui
+	iterations      int           // alternating update rounds
+	retrainInterval time.Duration
+}
+
+// loadALSConfig reads RECOMMENDATION_ALS_FACTORS, _LAMBDA, _ALPHA,
+// _ITERATIONS, and _RETRAIN_INTERVAL_SECONDS, falling back to the
+// defaultALS* constants for anything unset or invalid.
+func loadALSConfig() alsConfig {
+	cfg := alsConfig{
+		factors:         defaultALSFactors,
+		lambda:          defaultALSLambda,
+		alpha:           defaultALSAlpha,
+		iterations:      defaultALSIterations,
+		retrainInterval: defaultALSRetrainInterval,
+	}
+	if v := os.Getenv("RECOMMENDATION_ALS_FACTORS"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			cfg.factors = n
+		}
+	}
+	if v := os.Getenv("RECOMMENDATION_ALS_LAMBDA"); v != "" {
+		if f, err := strconv.ParseFloat(v, 64); err == nil && f >= 0 {
+			cfg.lambda = f
+		}
+	}
+	if v := os.Getenv("RECOMMENDATION_ALS_ALPHA"); v != "" {
+		if f, err := strconv.ParseFloat(v, 64); err == nil && f >= 0 {
+			cfg.alpha = f
+		}
+	}
+	if v := os.Getenv("RECOMMENDATION_ALS_ITERATIONS"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			cfg.iterations = n
+		}
+	}
+	if v := os.Getenv("RECOMMENDATION_ALS_RETRAIN_INTERVAL_SECONDS"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			cfg.retrainInterval = time.Duration(n) * time.Second
+		}
+	}
+	return cfg
+}
+
+// alsModel is a trained implicit-feedback factorization: a k-dimensional
+// latent vector per user and per item, such that x_u . y_i approximates
+// user u's preference for item i. It is replaced wholesale by each
+// retraining pass (see CollaborativeFilter.retrain), so reads only ever
+// need a read lock against a fully-formed pair of maps.
+type alsModel struct {
+	mu          sync.RWMutex
+	userFactors map[string][]float64
+	itemFactors map[string][]float64
+}
+
+func newALSModel() *alsModel {
+	return &alsModel{
+		userFactors: make(map[string][]float64),
+		itemFactors: make(map[string][]float64),
+	}
+}
+
+func (m *alsModel) set(userFactors, itemFactors map[string][]float64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.userFactors = userFactors
+	m.itemFactors = itemFactors
+}
+
+// recommend returns the top-limit items by dot product of userID's factor
+// vector against every item's, excluding items in exclude. It returns nil
+// if userID has no trained factors yet, so the caller can fall back to
+// something else instead of an empty recommendation list.
+func (m *alsModel) recommend(userID string, exclude map[string]bool, itemType map[string]string, limit int) []models.Recommendation {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	uv, ok := m.userFactors[userID]
+	if !ok {
+		return nil
+	}
+
+	recs := make([]models.Recommendation, 0, len(m.itemFactors))
+	for itemID, iv := range m.itemFactors {
+		if exclude[itemID] {
+			continue
+		}
+		recs = append(recs, models.Recommendation{
+			ItemID:   itemID,
+			ItemType: itemType[itemID],
+			Score:    dot(uv, iv),
+			Reason:   "Predicted preference from your interaction history",
+		})
+	}
+	sort.Slice(recs, func(i, j int) bool { return recs[i].Score > recs[j].Score })
+	if limit > 0 && limit < len(recs) {
+		recs = recs[:limit]
+	}
+	return recs
+}
+
+// similarItems returns the top-limit items by cosine similarity of their
+// factor vector to itemID's. It returns nil if itemID has no trained
+// factors yet.
+func (m *alsModel) similarItems(itemID string, itemType map[string]string, limit int) []models.Recommendation {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	target, ok := m.itemFactors[itemID]
+	if !ok {
+		return nil
+	}
+
+	recs := make([]models.Recommendation, 0, len(m.itemFactors))
+	for other, v := range m.itemFactors {
+		if other == itemID {
+			continue
+		}
+		recs = append(recs, models.Recommendation{
+			ItemID:   other,
+			ItemType: itemType[other],
+			Score:    CosineSimilarity(target, v),
+			Reason:   "Similar latent factors to " + itemID,
+		})
+	}
+	sort.Slice(recs, func(i, j int) bool { return recs[i].Score > recs[j].Score })
+	if limit > 0 && limit < len(recs) {
+		recs = recs[:limit]
+	}
+	return recs
+}
+
+// userFactorOrZero returns userID's trained factor vector, or a zero vector
+// of length k if ALS hasn't trained one yet (e.g. a brand-new user), so
+// callers that build a fixed-dimension context vector (see bandit.go) don't
+// need a separate cold-start branch.
+func (m *alsModel) userFactorOrZero(userID string, k int) []float64 {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if v, ok := m.userFactors[userID]; ok {
+		return v
+	}
+	return make([]float64, k)
+}
+
+// itemFactorOrZero is userFactorOrZero for items.
+func (m *alsModel) itemFactorOrZero(itemID string, k int) []float64 {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if v, ok := m.itemFactors[itemID]; ok {
+		return v
+	}
+	return make([]float64, k)
+}
+
+// itemFactorsSnapshot copies the current item factors, so a caller like
+// NeighborCache.recompute can rank every item's neighbors against a stable
+// view without holding m's lock for the duration.
+func (m *alsModel) itemFactorsSnapshot() map[string][]float64 {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	out := make(map[string][]float64, len(m.itemFactors))
+	for id, v := range m.itemFactors {
+		out[id] = v
+	}
+	return out
+}
+
+func dot(a, b []float64) float64 {
+	var s float64
+	for i := range a {
+		s += a[i] * b[i]
+	}
+	return s
+}
+
+// trainALS fits k-dimensional user and item factors to the implicit
+// feedback matrix `ratings` (userID -> itemID -> r_ui, a non-negative
+// weighted interaction count) via Alternating Least Squares for implicit
+// feedback (Hu, Koren & Volinsky, 2008).
+//
+// Confidence is c_ui = 1 + alpha*r_ui; preference p_ui is implicitly 1 for
+// every (u,i) present in a row of `ratings` and 0 everywhere else, so each
+// closed-form update only needs the sparse set of items (or users) that
+// row actually touches:
+//
+//	x_u = (Y'Y + Y'(C^u - I)Y + lambda*I)^-1 Y' C^u p(u)
+//	y_i = (X'X + X'(C^i - I)X + lambda*I)^-1 X' C^i p(i)
+//
+// Computing Y'Y (resp. X'X) once per outer iteration and folding
+// Y'(C^u-I)Y and Y'C^u p(u) into a single pass over u's nonzero items
+// keeps the per-user cost at O(k^2 * n_u + k^3) rather than
+// O(k^2 * n_items).
+func trainALS(ratings map[string]map[string]float64, cfg alsConfig, seed int64) (userFactors, itemFactors map[string][]float64) {
+	itemSet := make(map[string]bool)
+	users := make([]string, 0, len(ratings))
+	for u, row := range ratings {
+		users = append(users, u)
+		for i := range row {
+			itemSet[i] = true
+		}
+	}
+	items := make([]string, 0, len(itemSet))
+	for i := range itemSet {
+		items = append(items, i)
+	}
+	sort.Strings(users)
+	sort.Strings(items)
+
+	if len(users) == 0 || len(items) == 0 {
+		return map[string][]float64{}, map[string][]float64{}
+	}
+
+	itemRatings := make(map[string]map[string]float64, len(items))
+	for u, row := range ratings {
+		for i, r := range row {
+			if itemRatings[i] == nil {
+				itemRatings[i] = make(map[string]float64)
+			}
+			itemRatings[i][u] = r
+		}
+	}
+
+	rng := rand.New(rand.NewSource(seed))
+	X := initFactors(users, cfg.factors, rng)
+	Y := initFactors(items, cfg.factors, rng)
+
+	for iter := 0; iter < cfg.iterations; iter++ {
+		YtY := gramMatrix(Y, items, cfg.factors)
+		for _, u := range users {
+			X[u] = solveFactor(YtY, Y, ratings[u], cfg)
+		}
+
+		XtX := gramMatrix(X, users, cfg.factors)
+		for _, i := range items {
+			Y[i] = solveFactor(XtX, X, itemRatings[i], cfg)
+		}
+	}
+
+	return X, Y
+}
+
+func initFactors(ids []string, k int, rng *rand.Rand) map[string][]float64 {
+	factors := make(map[string][]float64, len(ids))
+	for _, id := range ids {
+		v := make([]float64, k)
+		for d := range v {
+			v[d] = rng.NormFloat64() * 0.1
+		}
+		factors[id] = v
+	}
+	return factors
+}
+
+// gramMatrix computes F'F (k x k), summing outer(F[id], F[id]) over ids.
+func gramMatrix(F map[string][]float64, ids []string, k int) [][]float64 {
+	g := newMatrix(k, k)
+	for _, id := range ids {
+		v := F[id]
+		for a := 0; a < k; a++ {
+			for b := 0; b < k; b++ {
+				g[a][b] += v[a] * v[b]
+			}
+		}
+	}
+	return g
+}
+
+// solveFactor solves the closed-form ALS update for one row of the matrix
+// (a user against item factors F, or an item against user factors F),
+// given the precomputed gram matrix FtF = F'F and the row's sparse
+// ratings. It builds A = F'F + F'(C-I)F + lambda*I and b = F'Cp in a
+// single pass over row's nonzero entries, then solves Ax = b.
+func solveFactor(FtF [][]float64, F map[string][]float64, row map[string]float64, cfg alsConfig) []float64 {
+	k := cfg.factors
+	A := cloneMatrix(FtF)
+	for d := 0; d < k; d++ {
+		A[d][d] += cfg.lambda
+	}
+	b := make([]float64, k)
+
+	for id, r := range row {
+		if r <= 0 {
+			continue
+		}
+		v := F[id]
+		c := 1 + cfg.alpha*r
+		for a := 0; a < k; a++ {
+			b[a] += c * v[a]
+			for bi := 0; bi < k; bi++ {
+				A[a][bi] += (c - 1) * v[a] * v[bi]
+			}
+		}
+	}
+
+	return solveLinearSystem(A, b)
+}
+
+func newMatrix(rows, cols int) [][]float64 {
+	m := make([][]float64, rows)
+	for i := range m {
+		m[i] = make([]float64, cols)
+	}
+	return m
+}
+
+func cloneMatrix(m [][]float64) [][]float64 {
+	out := make([][]float64, len(m))
+	for i, row := range m {
+		out[i] = append([]float64(nil), row...)
+	}
+	return out
+}
+
+// solveLinearSystem solves the small, square system Ax = b by Gaussian
+// elimination with partial pivoting. A and b are left untouched; a
+// near-singular pivot column (possible for a brand-new user/item with a
+// single interaction) leaves the corresponding entry of x at 0 rather than
+// blowing up.
+func solveLinearSystem(A [][]float64, b []float64) []float64 {
+	n := len(b)
+	aug := make([][]float64, n)
+	for i := range aug {
+		aug[i] = append(append([]float64(nil), A[i]...), b[i])
+	}
+
+	for col := 0; col < n; col++ {
+		pivot := col
+		for r := col + 1; r < n; r++ {
+			if math.Abs(aug[r][col]) > math.Abs(aug[pivot][col]) {
+				pivot = r
+			}
+		}
+		aug[col], aug[pivot] = aug[pivot], aug[col]
+
+		pivotVal := aug[col][col]
+		if math.Abs(pivotVal) < 1e-12 {
+			continue
+		}
+		for r := col + 1; r < n; r++ {
+			factor := aug[r][col] / pivotVal
+			for c := col; c <= n; c++ {
+				aug[r][c] -= factor * aug[col][c]
+			}
+		}
+	}
+
+	x := make([]float64, n)
+	for row := n - 1; row >= 0; row-- {
+		sum := aug[row][n]
+		for c := row + 1; c < n; c++ {
+			sum -= aug[row][c] * x[c]
+		}
+		if math.Abs(aug[row][row]) < 1e-12 {
+			continue
+		}
+		x[row] = sum / aug[row][row]
+	}
+	return x
+}
@@ -0,0 +1,91 @@
+package algorithms
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"log"
+	"time"
+)
+
+// loadFactors restores a previously persisted ALS model from
+// recommendation_factors (see internal/database/migrations), so the
+// service doesn't serve cold, mock-only recommendations immediately after
+// a restart. It's best-effort: any failure just leaves the model
+// untrained until the next retrain.
+func loadFactors(ctx context.Context, db *sql.DB) (userFactors, itemFactors map[string][]float64) {
+	rows, err := db.QueryContext(ctx, `SELECT kind, entity_id, factors FROM recommendation_factors`)
+	if err != nil {
+		log.Printf("recommendation-engine: failed to load ALS factors: %v", err)
+		return nil, nil
+	}
+	defer rows.Close()
+
+	userFactors = make(map[string][]float64)
+	itemFactors = make(map[string][]float64)
+	for rows.Next() {
+		var kind, id string
+		var blob []byte
+		if err := rows.Scan(&kind, &id, &blob); err != nil {
+			log.Printf("recommendation-engine: failed to scan ALS factor row: %v", err)
+			continue
+		}
+		var v []float64
+		if err := json.Unmarshal(blob, &v); err != nil {
+			log.Printf("recommendation-engine: failed to decode ALS factors for %s %s: %v", kind, id, err)
+			continue
+		}
+		switch kind {
+		case "user":
+			userFactors[id] = v
+		case "item":
+			itemFactors[id] = v
+		}
+	}
+	if err := rows.Err(); err != nil {
+		log.Printf("recommendation-engine: error reading ALS factors: %v", err)
+	}
+	return userFactors, itemFactors
+}
+
+// persistFactors replaces the contents of recommendation_factors with
+// userFactors and itemFactors inside one transaction. Retraining always
+// produces a complete pair of factor matrices, so a wholesale
+// delete-then-insert keeps the table free of factors for users/items that
+// dropped out of the training set, without needing to diff against what
+// was there before.
+func persistFactors(ctx context.Context, db *sql.DB, userFactors, itemFactors map[string][]float64) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM recommendation_factors`); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	insert := func(kind, id string, v []float64) error {
+		blob, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		_, err = tx.ExecContext(ctx,
+			`INSERT INTO recommendation_factors (kind, entity_id, factors, updated_at) VALUES (?, ?, ?, ?)`,
+			kind, id, blob, now,
+		)
+		return err
+	}
+	for id, v := range userFactors {
+		if err := insert("user", id, v); err != nil {
+			return err
+		}
+	}
+	for id, v := range itemFactors {
+		if err := insert("item", id, v); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
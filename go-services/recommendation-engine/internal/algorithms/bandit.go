@@ -0,0 +1,317 @@
+package algorithms
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"log"
+	"math"
+	"os"
+	"recommendation-engine/internal/models"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	defaultBanditAlpha         = 1.0
+	defaultBanditFlushInterval = 5 * time.Minute
+	impressionRetention        = 30 * time.Minute // how long a never-fed-back impression is kept before eviction
+)
+
+// banditConfig tunes LinUCB's exploration/exploitation tradeoff and how
+// often accumulated arm updates are flushed to MySQL.
+type banditConfig struct {
+	alpha         float64       // exploration coefficient; higher favors uncertain arms
+	flushInterval time.Duration
+}
+
+// loadBanditConfig reads RECOMMENDATION_BANDIT_ALPHA and
+// RECOMMENDATION_BANDIT_FLUSH_INTERVAL_SECONDS, falling back to the
+// defaultBandit* constants for anything unset or invalid.
+func loadBanditConfig() banditConfig {
+	cfg := banditConfig{alpha: defaultBanditAlpha, flushInterval: defaultBanditFlushInterval}
+	if v := os.Getenv("RECOMMENDATION_BANDIT_ALPHA"); v != "" {
+		if f, err := strconv.ParseFloat(v, 64); err == nil && f >= 0 {
+			cfg.alpha = f
+		}
+	}
+	if v := os.Getenv("RECOMMENDATION_BANDIT_FLUSH_INTERVAL_SECONDS"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			cfg.flushInterval = time.Duration(n) * time.Second
+		}
+	}
+	return cfg
+}
+
+// arm is one item's LinUCB sufficient statistics: A = I_d + Σ x·xᵀ and
+// b = Σ r·x, from which the ridge-regression reward estimate θ = A⁻¹b is
+// derived. AInv is maintained alongside A via Sherman–Morrison so scoring
+// never needs to invert a matrix on the hot path.
+type arm struct {
+	mu    sync.Mutex
+	A     [][]float64
+	AInv  [][]float64
+	b     []float64
+	dirty bool // updated since the last flush to MySQL
+}
+
+func newArm(d int) *arm {
+	A := newMatrix(d, d)
+	AInv := newMatrix(d, d)
+	for i := 0; i < d; i++ {
+		A[i][i] = 1
+		AInv[i][i] = 1
+	}
+	return &arm{A: A, AInv: AInv, b: make([]float64, d)}
+}
+
+// update folds one observed (context, reward) pair into the arm via the
+// Sherman–Morrison identity (A + xxᵀ)⁻¹ = A⁻¹ - (A⁻¹x)(xᵀA⁻¹)ᵀ/(1 + xᵀA⁻¹x),
+// which keeps the per-update cost at O(d^2) instead of re-solving Ax=b from
+// scratch.
+func (a *arm) update(x []float64, reward float64) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	Ainvx := matVec(a.AInv, x)
+	denom := 1 + dot(x, Ainvx)
+	for i := range a.AInv {
+		for j := range a.AInv[i] {
+			a.AInv[i][j] -= (Ainvx[i] * Ainvx[j]) / denom
+		}
+	}
+	for i := range a.A {
+		for j := range a.A[i] {
+			a.A[i][j] += x[i] * x[j]
+		}
+	}
+	for i := range a.b {
+		a.b[i] += reward * x[i]
+	}
+	a.dirty = true
+}
+
+// ucb returns LinUCB's score for context x: the estimated reward θᵀx plus
+// an exploration bonus alpha*sqrt(xᵀA⁻¹x) that shrinks as the arm
+// accumulates more observations.
+func (a *arm) ucb(x []float64, alpha float64) float64 {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	theta := matVec(a.AInv, a.b)
+	variance := dot(x, matVec(a.AInv, x))
+	if variance < 0 {
+		variance = 0 // clamp floating-point drift from the incremental AInv updates
+	}
+	return dot(theta, x) + alpha*math.Sqrt(variance)
+}
+
+// snapshot copies A and b for persistence, clearing dirty so the next
+// flush skips this arm unless it's updated again first.
+func (a *arm) snapshot() (A [][]float64, b []float64, wasDirty bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	wasDirty = a.dirty
+	a.dirty = false
+	return cloneMatrix(a.A), append([]float64(nil), a.b...), wasDirty
+}
+
+func matVec(m [][]float64, v []float64) []float64 {
+	out := make([]float64, len(m))
+	for i := range m {
+		out[i] = dot(m[i], v)
+	}
+	return out
+}
+
+// impression is the context a recommendation was scored with, kept around
+// just long enough for a matching Feedback call to attribute a reward to
+// the right arm.
+type impression struct {
+	itemID    string
+	context   []float64
+	createdAt time.Time
+}
+
+// BanditRanker re-ranks a hybrid recommender's top candidates with LinUCB,
+// treating each item as an independent arm whose context vector is the
+// user's and item's ALS factors concatenated with the candidate's hybrid
+// score (x = userFactor ⊕ itemFactor ⊕ hybridScore). Feedback closes the
+// loop: TrackInteraction-driven clicks/purchases map back to the impression
+// that produced them and fold the observed reward into that arm via
+// Sherman–Morrison, while a background loop periodically flushes dirty
+// arms' A/b to MySQL.
+type BanditRanker struct {
+	cfg banditConfig
+	dim int // 2*alsFactors + 1: user factor, item factor, hybrid score
+
+	mu   sync.Mutex
+	arms map[string]*arm
+
+	impressionsMu sync.Mutex
+	impressions   map[string]impression
+
+	db *sql.DB
+}
+
+// newBanditRanker creates a BanditRanker whose context vectors have
+// dimension 2*alsFactors+1, restoring any previously persisted arms from
+// MySQL if db is non-nil.
+func newBanditRanker(db *sql.DB, alsFactors int) *BanditRanker {
+	br := &BanditRanker{
+		cfg:         loadBanditConfig(),
+		dim:         2*alsFactors + 1,
+		arms:        make(map[string]*arm),
+		impressions: make(map[string]impression),
+		db:          db,
+	}
+	if db != nil {
+		if arms := loadArms(context.Background(), db, br.dim); len(arms) > 0 {
+			br.arms = arms
+		}
+	}
+	return br
+}
+
+func (br *BanditRanker) armFor(itemID string) *arm {
+	br.mu.Lock()
+	defer br.mu.Unlock()
+	a, ok := br.arms[itemID]
+	if !ok {
+		a = newArm(br.dim)
+		br.arms[itemID] = a
+	}
+	return a
+}
+
+// Rerank scores candidates (already ranked by hybrid score) with LinUCB and
+// returns them sorted by UCB score, descending. Each returned
+// recommendation carries a fresh ImpressionID recorded against the context
+// it was scored with, so a later Feedback call can attribute a reward to
+// the right arm; als supplies the user/item factor halves of the context.
+func (br *BanditRanker) Rerank(userID string, candidates []models.Recommendation, als *alsModel, alsFactors int) []models.Recommendation {
+	userVec := als.userFactorOrZero(userID, alsFactors)
+
+	type scored struct {
+		rec   models.Recommendation
+		ucb   float64
+		x     []float64
+	}
+	scoredRecs := make([]scored, len(candidates))
+	for i, rec := range candidates {
+		itemVec := als.itemFactorOrZero(rec.ItemID, alsFactors)
+		x := make([]float64, 0, br.dim)
+		x = append(x, userVec...)
+		x = append(x, itemVec...)
+		x = append(x, rec.Score)
+		scoredRecs[i] = scored{rec: rec, ucb: br.armFor(rec.ItemID).ucb(x, br.cfg.alpha), x: x}
+	}
+	sort.Slice(scoredRecs, func(i, j int) bool { return scoredRecs[i].ucb > scoredRecs[j].ucb })
+
+	out := make([]models.Recommendation, len(scoredRecs))
+	for i, s := range scoredRecs {
+		rec := s.rec
+		rec.Score = s.ucb
+		rec.Reason = "Explore/exploit: " + rec.Reason
+		rec.ImpressionID = br.recordImpression(s.rec.ItemID, s.x)
+		out[i] = rec
+	}
+	return out
+}
+
+func (br *BanditRanker) recordImpression(itemID string, x []float64) string {
+	id := generateImpressionID()
+
+	br.impressionsMu.Lock()
+	br.impressions[id] = impression{itemID: itemID, context: x, createdAt: time.Now()}
+	br.evictStaleImpressionsLocked()
+	br.impressionsMu.Unlock()
+
+	return id
+}
+
+// evictStaleImpressionsLocked drops impressions nobody gave feedback on
+// within impressionRetention, so an abandoned session's impressions don't
+// accumulate forever. Callers must hold br.impressionsMu.
+func (br *BanditRanker) evictStaleImpressionsLocked() {
+	cutoff := time.Now().Add(-impressionRetention)
+	for id, imp := range br.impressions {
+		if imp.createdAt.Before(cutoff) {
+			delete(br.impressions, id)
+		}
+	}
+}
+
+// Feedback maps impressionID back to the context it was scored with and
+// folds reward into that arm, marking it dirty for the next flush. It
+// returns false if impressionID is unknown (already fed back, evicted, or
+// never issued by this process).
+func (br *BanditRanker) Feedback(impressionID string, reward float64) bool {
+	br.impressionsMu.Lock()
+	imp, ok := br.impressions[impressionID]
+	if ok {
+		delete(br.impressions, impressionID)
+	}
+	br.impressionsMu.Unlock()
+	if !ok {
+		return false
+	}
+
+	br.armFor(imp.itemID).update(imp.context, reward)
+	return true
+}
+
+// RunFlushing periodically persists dirty arms' A/b to MySQL at
+// cfg.flushInterval. It runs until ctx is canceled. A nil db makes this a
+// no-op loop so callers don't need to special-case the no-persistence mode.
+func (br *BanditRanker) RunFlushing(ctx context.Context) {
+	if br.db == nil {
+		return
+	}
+
+	ticker := time.NewTicker(br.cfg.flushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := br.flush(ctx); err != nil {
+				log.Printf("recommendation-engine: failed to flush bandit arms: %v", err)
+			}
+		}
+	}
+}
+
+// flush persists every dirty arm's current A/b to bandit_arms.
+func (br *BanditRanker) flush(ctx context.Context) error {
+	br.mu.Lock()
+	arms := make(map[string]*arm, len(br.arms))
+	for id, a := range br.arms {
+		arms[id] = a
+	}
+	br.mu.Unlock()
+
+	dirty := make(map[string]armSnapshot, len(arms))
+	for itemID, a := range arms {
+		A, b, wasDirty := a.snapshot()
+		if !wasDirty {
+			continue
+		}
+		dirty[itemID] = armSnapshot{A: A, b: b}
+	}
+	if len(dirty) == 0 {
+		return nil
+	}
+	return persistArms(ctx, br.db, dirty)
+}
+
+func generateImpressionID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return "imp_" + hex.EncodeToString(b)
+}
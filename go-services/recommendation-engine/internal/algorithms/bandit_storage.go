@@ -0,0 +1,119 @@
+package algorithms
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"log"
+	"time"
+)
+
+// armSnapshot is one arm's A/b at the moment it was flushed, ready to
+// persist to bandit_arms.
+type armSnapshot struct {
+	A [][]float64
+	b []float64
+}
+
+// loadArms restores previously persisted LinUCB arms from bandit_arms (see
+// internal/database/migrations), so the bandit doesn't forget everything
+// it learned on every restart. It's best-effort: a row whose dimension
+// doesn't match dim (e.g. RECOMMENDATION_ALS_FACTORS changed since the
+// last run) is skipped rather than failing the whole load.
+func loadArms(ctx context.Context, db *sql.DB, dim int) map[string]*arm {
+	rows, err := db.QueryContext(ctx, `SELECT item_id, a_matrix, b_vector FROM bandit_arms`)
+	if err != nil {
+		log.Printf("recommendation-engine: failed to load bandit arms: %v", err)
+		return nil
+	}
+	defer rows.Close()
+
+	arms := make(map[string]*arm)
+	for rows.Next() {
+		var itemID string
+		var aBlob, bBlob []byte
+		if err := rows.Scan(&itemID, &aBlob, &bBlob); err != nil {
+			log.Printf("recommendation-engine: failed to scan bandit arm row: %v", err)
+			continue
+		}
+		var A [][]float64
+		var b []float64
+		if err := json.Unmarshal(aBlob, &A); err != nil {
+			log.Printf("recommendation-engine: failed to decode bandit arm A matrix for %s: %v", itemID, err)
+			continue
+		}
+		if err := json.Unmarshal(bBlob, &b); err != nil {
+			log.Printf("recommendation-engine: failed to decode bandit arm b vector for %s: %v", itemID, err)
+			continue
+		}
+		if len(b) != dim || len(A) != dim {
+			log.Printf("recommendation-engine: skipping bandit arm %s: persisted dimension %d != configured dimension %d", itemID, len(b), dim)
+			continue
+		}
+
+		a := newArm(dim)
+		a.A = A
+		a.b = b
+		AInv, err := invertMatrix(A)
+		if err != nil {
+			log.Printf("recommendation-engine: skipping bandit arm %s: %v", itemID, err)
+			continue
+		}
+		a.AInv = AInv
+		arms[itemID] = a
+	}
+	if err := rows.Err(); err != nil {
+		log.Printf("recommendation-engine: error reading bandit arms: %v", err)
+	}
+	return arms
+}
+
+// invertMatrix inverts A by solving AX = I one column at a time with the
+// existing Gaussian-elimination solver, since A is only ever inverted here
+// on load (the hot path maintains AInv incrementally via Sherman-Morrison).
+func invertMatrix(A [][]float64) ([][]float64, error) {
+	n := len(A)
+	inv := newMatrix(n, n)
+	for col := 0; col < n; col++ {
+		e := make([]float64, n)
+		e[col] = 1
+		x := solveLinearSystem(A, e)
+		for row := 0; row < n; row++ {
+			inv[row][col] = x[row]
+		}
+	}
+	return inv, nil
+}
+
+// persistArms upserts each dirty arm's current A/b into bandit_arms.
+// Unlike ALS's wholesale retrain-and-replace, bandit arms update
+// incrementally per interaction, so a flush only ever touches the arms
+// that changed since the last one.
+func persistArms(ctx context.Context, db *sql.DB, dirty map[string]armSnapshot) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	for itemID, snap := range dirty {
+		aBlob, err := json.Marshal(snap.A)
+		if err != nil {
+			return err
+		}
+		bBlob, err := json.Marshal(snap.b)
+		if err != nil {
+			return err
+		}
+		_, err = tx.ExecContext(ctx,
+			`INSERT INTO bandit_arms (item_id, a_matrix, b_vector, updated_at) VALUES (?, ?, ?, ?)
+			 ON DUPLICATE KEY UPDATE a_matrix = VALUES(a_matrix), b_vector = VALUES(b_vector), updated_at = VALUES(updated_at)`,
+			itemID, aBlob, bBlob, now,
+		)
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,817 @@
+package algorithms
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"math"
+	"os"
+	"recommendation-engine/internal/models"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// actionWeight is the base implicit-feedback weight a single interaction
+// contributes before decay. Unrecognized actions fall back to defaultWeight.
+var actionWeight = map[string]float64{
+	"view":     1.0,
+	"like":     2.0,
+	"purchase": 5.0,
+}
+
+const defaultActionWeight = 1.0
+
+const defaultDecayHalfLifeHours = 24 * 7
+
+// decayHalfLife is how long it takes an interaction's weight to fall to
+// half its value, configurable via RECOMMENDATION_DECAY_HALF_LIFE_HOURS so
+// deployments can tune how quickly stale behavior stops influencing
+// similarity scores.
+func decayHalfLife() time.Duration {
+	hours := defaultDecayHalfLifeHours
+	if raw := os.Getenv("RECOMMENDATION_DECAY_HALF_LIFE_HOURS"); raw != "" {
+		if parsed, err := strconv.Atoi(raw); err == nil && parsed > 0 {
+			hours = parsed
+		}
+	}
+	return time.Duration(hours) * time.Hour
+}
+
+// cell is one entry of the user-item interaction matrix: a weight anchored
+// at the time it was last touched, so its current value can be recovered
+// at read time via decayedWeight instead of needing a background sweep.
+type cell struct {
+	weight    float64
+	updatedAt time.Time
+}
+
+func (c *cell) decayedWeight(now time.Time, halfLife time.Duration) float64 {
+	elapsed := now.Sub(c.updatedAt)
+	if elapsed <= 0 {
+		return c.weight
+	}
+	return c.weight * math.Exp2(-elapsed.Hours()/halfLife.Hours())
+}
+
+// maxRecentPerUser bounds the per-user interaction log kept for Explain.
+const maxRecentPerUser = 50
+
+// CollaborativeFilter implements collaborative filtering recommendation.
+//
+// Beyond its original mock-data GenerateRecommendations/FindSimilarItems,
+// it maintains a decayed user-item interaction matrix fed by Ingest, plus
+// an item-item similarity cache that Ingest updates incrementally —
+// recomputing only the rows touched by the new interaction — instead of
+// rebuilding it from scratch on every call. On top of that, it trains an
+// implicit-feedback ALS model (see als.go) from the same interactions, and
+// precomputes a nightly top-K item-item neighbor cache from that model's
+// item factors (see similarity_cache.go). GenerateRecommendations and
+// FindSimilarItems prefer, in order: the neighbor cache/ALS factors, the
+// real-time decayed-matrix similarity, popularity (optionally within the
+// requested item's type), and finally mock data while everything else is
+// still cold.
+type CollaborativeFilter struct {
+	mu sync.Mutex
+
+	halfLife time.Duration
+
+	// userItems[userID][itemID] and its transpose itemUsers[itemID][userID]
+	// point at the same *cell, so decaying or updating one keeps both in
+	// sync.
+	userItems map[string]map[string]*cell
+	itemUsers map[string]map[string]*cell
+
+	// itemSimilarity[itemA][itemB] is the cosine similarity between itemA
+	// and itemB's current decayed user-weight vectors.
+	itemSimilarity map[string]map[string]float64
+
+	// itemType remembers the most recently seen ItemType for an item, so
+	// FindSimilarItems can report it without re-deriving it from scratch.
+	itemType map[string]string
+
+	// recent is a bounded, most-recent-first interaction log per user, kept
+	// for Explain; scoring itself never reads it.
+	recent map[string][]models.UserInteraction
+
+	// counts[userID][itemID] is the undecayed, cumulative implicit-feedback
+	// weight r_ui that ALS training turns into confidence c_ui = 1 + alpha*r_ui.
+	// Unlike userItems' cells, it never decays: ALS wants a stable signal of
+	// how much a user has engaged with an item, not a real-time-weighted one.
+	counts map[string]map[string]float64
+
+	als    *alsModel
+	alsCfg alsConfig
+	db     *sql.DB // may be nil; ALS still trains in-memory, just isn't persisted
+
+	// neighbors is the nightly-precomputed item-item top-K similarity cache
+	// (see similarity_cache.go); FindSimilarItems prefers it over both the
+	// ALS factors and the real-time decayed matrix when it has an entry for
+	// the requested item.
+	neighbors *NeighborCache
+}
+
+func newCollaborativeFilter(db *sql.DB) *CollaborativeFilter {
+	cf := &CollaborativeFilter{
+		halfLife:       decayHalfLife(),
+		userItems:      make(map[string]map[string]*cell),
+		itemUsers:      make(map[string]map[string]*cell),
+		itemSimilarity: make(map[string]map[string]float64),
+		itemType:       make(map[string]string),
+		recent:         make(map[string][]models.UserInteraction),
+		counts:         make(map[string]map[string]float64),
+		als:            newALSModel(),
+		alsCfg:         loadALSConfig(),
+		db:             db,
+		neighbors:      newNeighborCache(db),
+	}
+
+	if db != nil {
+		if userFactors, itemFactors := loadFactors(context.Background(), db); len(userFactors) > 0 || len(itemFactors) > 0 {
+			cf.als.set(userFactors, itemFactors)
+		}
+	}
+
+	return cf
+}
+
+// Ingest folds interaction into the user-item matrix, decaying the
+// existing weight between this user and item before adding the new
+// interaction's contribution, then incrementally recomputes the
+// similarity between this item and every other item that shares a user
+// with it.
+func (cf *CollaborativeFilter) Ingest(interaction models.UserInteraction) {
+	cf.mu.Lock()
+	defer cf.mu.Unlock()
+
+	now := interaction.Timestamp
+	if now.IsZero() {
+		now = time.Now()
+	}
+
+	weight := actionWeight[interaction.Action]
+	if weight == 0 {
+		weight = defaultActionWeight
+	}
+
+	c := cf.cellFor(interaction.UserID, interaction.ItemID)
+	c.weight = c.decayedWeight(now, cf.halfLife) + weight
+	c.updatedAt = now
+
+	if cf.counts[interaction.UserID] == nil {
+		cf.counts[interaction.UserID] = make(map[string]float64)
+	}
+	cf.counts[interaction.UserID][interaction.ItemID] += weight
+
+	if interaction.ItemType != "" {
+		cf.itemType[interaction.ItemID] = interaction.ItemType
+	}
+
+	cf.recordRecent(interaction)
+	cf.recomputeSimilarityFor(interaction.ItemID, now)
+}
+
+// RegisterItem records itemID's type ahead of any interaction, e.g. from a
+// ListingCreated event, so cold-start scoring and FindSimilarItems report
+// an accurate type even before a user interaction would otherwise have
+// seeded it.
+func (cf *CollaborativeFilter) RegisterItem(itemID, itemType string) {
+	cf.mu.Lock()
+	defer cf.mu.Unlock()
+	if itemType != "" {
+		cf.itemType[itemID] = itemType
+	}
+}
+
+func (cf *CollaborativeFilter) cellFor(userID, itemID string) *cell {
+	if cf.userItems[userID] == nil {
+		cf.userItems[userID] = make(map[string]*cell)
+	}
+	if c := cf.userItems[userID][itemID]; c != nil {
+		return c
+	}
+
+	c := &cell{}
+	cf.userItems[userID][itemID] = c
+	if cf.itemUsers[itemID] == nil {
+		cf.itemUsers[itemID] = make(map[string]*cell)
+	}
+	cf.itemUsers[itemID][userID] = c
+	return c
+}
+
+func (cf *CollaborativeFilter) recordRecent(interaction models.UserInteraction) {
+	log := append([]models.UserInteraction{interaction}, cf.recent[interaction.UserID]...)
+	if len(log) > maxRecentPerUser {
+		log = log[:maxRecentPerUser]
+	}
+	cf.recent[interaction.UserID] = log
+}
+
+// recomputeSimilarityFor updates itemSimilarity between itemID and every
+// other item that shares at least one user with it, using the matrix's
+// current decayed weights. Callers must hold cf.mu.
+func (cf *CollaborativeFilter) recomputeSimilarityFor(itemID string, now time.Time) {
+	users := cf.itemUsers[itemID]
+	if len(users) == 0 {
+		return
+	}
+
+	vecA := cf.itemVector(itemID, now)
+
+	candidates := make(map[string]bool)
+	for userID := range users {
+		for otherItem := range cf.userItems[userID] {
+			if otherItem != itemID {
+				candidates[otherItem] = true
+			}
+		}
+	}
+
+	for otherItem := range candidates {
+		vecB := cf.itemVector(otherItem, now)
+		cf.setSimilarity(itemID, otherItem, sparseCosineSimilarity(vecA, vecB))
+	}
+}
+
+// itemVector returns itemID's current decayed weight per user, keyed by
+// user ID rather than a fixed-size slice since the matrix is sparse.
+func (cf *CollaborativeFilter) itemVector(itemID string, now time.Time) map[string]float64 {
+	users := cf.itemUsers[itemID]
+	vec := make(map[string]float64, len(users))
+	for userID, c := range users {
+		vec[userID] = c.decayedWeight(now, cf.halfLife)
+	}
+	return vec
+}
+
+func (cf *CollaborativeFilter) setSimilarity(a, b string, sim float64) {
+	if cf.itemSimilarity[a] == nil {
+		cf.itemSimilarity[a] = make(map[string]float64)
+	}
+	cf.itemSimilarity[a][b] = sim
+	if cf.itemSimilarity[b] == nil {
+		cf.itemSimilarity[b] = make(map[string]float64)
+	}
+	cf.itemSimilarity[b][a] = sim
+}
+
+// sparseCosineSimilarity is CosineSimilarity for vectors keyed by a shared
+// dimension (here, user ID) instead of parallel slices, since the
+// user-item matrix is sparse.
+func sparseCosineSimilarity(a, b map[string]float64) float64 {
+	var dot, normA, normB float64
+	for k, va := range a {
+		normA += va * va
+		if vb, ok := b[k]; ok {
+			dot += va * vb
+		}
+	}
+	for _, vb := range b {
+		normB += vb * vb
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
+
+// Explain returns the user's own interactions that contribute to itemID's
+// similarity score (ranked by contribution = similarity * decayed weight),
+// and the other users whose interactions with itemID itself carry the
+// most weight.
+func (cf *CollaborativeFilter) Explain(userID, itemID string, topK int) ([]models.InteractionExplanation, []models.NeighborUser) {
+	cf.mu.Lock()
+	defer cf.mu.Unlock()
+
+	now := time.Now()
+	sims := cf.itemSimilarity[itemID]
+
+	var topInteractions []models.InteractionExplanation
+	for otherItem, c := range cf.userItems[userID] {
+		sim := sims[otherItem]
+		if otherItem == itemID {
+			sim = 1
+		}
+		if sim <= 0 {
+			continue
+		}
+		weight := c.decayedWeight(now, cf.halfLife)
+		topInteractions = append(topInteractions, models.InteractionExplanation{
+			ItemID:       otherItem,
+			Weight:       weight,
+			Similarity:   sim,
+			Contribution: sim * weight,
+		})
+	}
+	sort.Slice(topInteractions, func(i, j int) bool {
+		return topInteractions[i].Contribution > topInteractions[j].Contribution
+	})
+	if topK > 0 && topK < len(topInteractions) {
+		topInteractions = topInteractions[:topK]
+	}
+
+	var neighbors []models.NeighborUser
+	for otherUser, c := range cf.itemUsers[itemID] {
+		if otherUser == userID {
+			continue
+		}
+		neighbors = append(neighbors, models.NeighborUser{
+			UserID: otherUser,
+			Weight: c.decayedWeight(now, cf.halfLife),
+		})
+	}
+	sort.Slice(neighbors, func(i, j int) bool { return neighbors[i].Weight > neighbors[j].Weight })
+	if topK > 0 && topK < len(neighbors) {
+		neighbors = neighbors[:topK]
+	}
+
+	return topInteractions, neighbors
+}
+
+// GenerateRecommendations generates personalized recommendations based on
+// user behavior. Once ALS has trained factors for userID, it ranks every
+// item the user hasn't already interacted with by predicted preference
+// (factor dot product) and rewrites each Reason to name the interacted item
+// it's closest to in latent-factor space. A brand-new user with no trained
+// factors instead falls back to popularity across all items, and finally to
+// mock data if the catalog has seen no interactions at all yet.
+func (cf *CollaborativeFilter) GenerateRecommendations(userID string, limit int) []models.Recommendation {
+	exclude := cf.interactedItems(userID)
+	if recs := cf.als.recommend(userID, exclude, cf.itemTypeSnapshot(), limit); len(recs) > 0 {
+		cf.annotateReasons(userID, recs)
+		return recs
+	}
+
+	if recs := cf.popularItems(exclude, "", limit); len(recs) > 0 {
+		return recs
+	}
+
+	mockRecommendations := mockCollaborativeRecommendations()
+	sort.Slice(mockRecommendations, func(i, j int) bool {
+		return mockRecommendations[i].Score > mockRecommendations[j].Score
+	})
+	if limit > 0 && limit < len(mockRecommendations) {
+		mockRecommendations = mockRecommendations[:limit]
+	}
+	return mockRecommendations
+}
+
+// FindSimilarItems finds items similar to the given item. It prefers the
+// nightly-precomputed neighbor cache (see similarity_cache.go), then falls
+// back to live cosine similarity over ALS item factors, then the
+// decayed-interaction-matrix similarity Ingest maintains in real time, then
+// popularity among items of the same type, and finally mock data for a
+// brand-new catalog.
+func (cf *CollaborativeFilter) FindSimilarItems(itemID string, limit int) []models.Recommendation {
+	if recs, ok := cf.neighbors.Get(itemID, limit); ok {
+		return recs
+	}
+	if recs := cf.als.similarItems(itemID, cf.itemTypeSnapshot(), limit); len(recs) > 0 {
+		return recs
+	}
+	if recs := cf.findSimilarFromMatrix(itemID, limit); len(recs) > 0 {
+		return recs
+	}
+	if recs := cf.popularItems(map[string]bool{itemID: true}, cf.itemTypeOf(itemID), limit); len(recs) > 0 {
+		return recs
+	}
+	return mockSimilarItems(limit)
+}
+
+// annotateReasons rewrites each candidate's Reason to name the interacted
+// item whose ALS item factors are most similar to it ("Because you liked
+// X"), so personalized recommendations explain themselves instead of all
+// repeating alsModel.recommend's generic reason. A user with no interactions
+// yet is left with that generic reason, since there's nothing to attribute
+// the recommendation to.
+func (cf *CollaborativeFilter) annotateReasons(userID string, recs []models.Recommendation) {
+	interacted := cf.interactedItems(userID)
+	if len(interacted) == 0 {
+		return
+	}
+	liked := make([]string, 0, len(interacted))
+	for itemID := range interacted {
+		liked = append(liked, itemID)
+	}
+	sort.Strings(liked)
+
+	k := cf.alsCfg.factors
+	for i := range recs {
+		target := cf.als.itemFactorOrZero(recs[i].ItemID, k)
+		bestItem, bestSim := "", -2.0
+		for _, likedItem := range liked {
+			sim := CosineSimilarity(target, cf.als.itemFactorOrZero(likedItem, k))
+			if sim > bestSim {
+				bestSim, bestItem = sim, likedItem
+			}
+		}
+		if bestItem != "" {
+			recs[i].Reason = "Because you liked " + bestItem
+		}
+	}
+}
+
+// popularItems ranks items by total cumulative (undecayed) interaction
+// weight across every user, the cold-start fallback once ALS and
+// real-time/cached similarity have nothing to offer. itemType, if
+// non-empty, restricts the ranking to items of that type.
+func (cf *CollaborativeFilter) popularItems(exclude map[string]bool, itemType string, limit int) []models.Recommendation {
+	cf.mu.Lock()
+	defer cf.mu.Unlock()
+
+	totals := make(map[string]float64)
+	for _, row := range cf.counts {
+		for itemID, r := range row {
+			totals[itemID] += r
+		}
+	}
+
+	recs := make([]models.Recommendation, 0, len(totals))
+	for itemID, total := range totals {
+		if exclude[itemID] {
+			continue
+		}
+		if itemType != "" && cf.itemType[itemID] != itemType {
+			continue
+		}
+		recs = append(recs, models.Recommendation{
+			ItemID:   itemID,
+			ItemType: cf.itemType[itemID],
+			Score:    total,
+			Reason:   "Popular among all users",
+		})
+	}
+	sort.Slice(recs, func(i, j int) bool { return recs[i].Score > recs[j].Score })
+	if limit > 0 && limit < len(recs) {
+		recs = recs[:limit]
+	}
+	return recs
+}
+
+// itemTypeOf returns itemID's known type, or "" if it hasn't been seen by
+// RegisterItem or an interaction yet.
+func (cf *CollaborativeFilter) itemTypeOf(itemID string) string {
+	cf.mu.Lock()
+	defer cf.mu.Unlock()
+	return cf.itemType[itemID]
+}
+
+// interactedItems returns the set of items userID has interacted with, so
+// GenerateRecommendations can exclude them from ALS's ranking.
+func (cf *CollaborativeFilter) interactedItems(userID string) map[string]bool {
+	cf.mu.Lock()
+	defer cf.mu.Unlock()
+
+	items := cf.userItems[userID]
+	exclude := make(map[string]bool, len(items))
+	for itemID := range items {
+		exclude[itemID] = true
+	}
+	return exclude
+}
+
+func (cf *CollaborativeFilter) itemTypeSnapshot() map[string]string {
+	cf.mu.Lock()
+	defer cf.mu.Unlock()
+
+	snapshot := make(map[string]string, len(cf.itemType))
+	for id, t := range cf.itemType {
+		snapshot[id] = t
+	}
+	return snapshot
+}
+
+// snapshotCounts copies the undecayed interaction-count matrix so training
+// can run against a stable view without holding cf.mu for the duration.
+func (cf *CollaborativeFilter) snapshotCounts() map[string]map[string]float64 {
+	cf.mu.Lock()
+	defer cf.mu.Unlock()
+
+	out := make(map[string]map[string]float64, len(cf.counts))
+	for u, row := range cf.counts {
+		copied := make(map[string]float64, len(row))
+		for i, r := range row {
+			copied[i] = r
+		}
+		out[u] = copied
+	}
+	return out
+}
+
+// RunRetraining periodically retrains the ALS model on the interactions
+// seen so far, at cf.alsCfg.retrainInterval. It runs until ctx is
+// canceled.
+func (cf *CollaborativeFilter) RunRetraining(ctx context.Context) {
+	ticker := time.NewTicker(cf.alsCfg.retrainInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			cf.retrain(ctx)
+		}
+	}
+}
+
+// retrain fits a fresh ALS model against the current interaction counts
+// and swaps it in, persisting the factors (see als_storage.go) if cf has
+// a database.
+func (cf *CollaborativeFilter) retrain(ctx context.Context) {
+	ratings := cf.snapshotCounts()
+	if len(ratings) == 0 {
+		return
+	}
+
+	userFactors, itemFactors := trainALS(ratings, cf.alsCfg, time.Now().UnixNano())
+	cf.als.set(userFactors, itemFactors)
+
+	if cf.db != nil {
+		if err := persistFactors(ctx, cf.db, userFactors, itemFactors); err != nil {
+			log.Printf("recommendation-engine: failed to persist ALS factors: %v", err)
+		}
+	}
+}
+
+// RunNeighborCacheRefresh runs the nightly item-item neighbor precomputation
+// (see similarity_cache.go) against the ALS model's current item factors
+// until ctx is canceled.
+func (cf *CollaborativeFilter) RunNeighborCacheRefresh(ctx context.Context) {
+	cf.neighbors.Run(ctx, func() (map[string][]float64, map[string]string) {
+		return cf.als.itemFactorsSnapshot(), cf.itemTypeSnapshot()
+	})
+}
+
+func (cf *CollaborativeFilter) findSimilarFromMatrix(itemID string, limit int) []models.Recommendation {
+	cf.mu.Lock()
+	defer cf.mu.Unlock()
+
+	sims := cf.itemSimilarity[itemID]
+	if len(sims) == 0 {
+		return nil
+	}
+
+	recs := make([]models.Recommendation, 0, len(sims))
+	for other, sim := range sims {
+		if sim <= 0 {
+			continue
+		}
+		recs = append(recs, models.Recommendation{
+			ItemID:   other,
+			ItemType: cf.itemType[other],
+			Score:    sim,
+			Reason:   "Similar interaction pattern to " + itemID,
+		})
+	}
+
+	sort.Slice(recs, func(i, j int) bool { return recs[i].Score > recs[j].Score })
+	if limit > 0 && limit < len(recs) {
+		recs = recs[:limit]
+	}
+	return recs
+}
+
+func mockCollaborativeRecommendations() []models.Recommendation {
+	return []models.Recommendation{
+		{
+			ItemID:      "rc_001",
+			ItemType:    "reasoning_chain",
+			Score:       0.95,
+			Reason:      "Based on your recent views",
+			Title:       "Advanced Multi-Agent Coordination",
+			Description: "A reasoning chain for complex task decomposition",
+		},
+		{
+			ItemID:      "wm_002",
+			ItemType:    "w_matrix",
+			Score:       0.89,
+			Reason:      "Popular among similar users",
+			Title:       "Semantic Search Optimization",
+			Description: "W-Matrix for enhanced vector search",
+		},
+		{
+			ItemID:      "rc_003",
+			ItemType:    "reasoning_chain",
+			Score:       0.85,
+			Reason:      "Trending in your category",
+			Title:       "Autonomous Decision Making",
+			Description: "Self-improving reasoning patterns",
+		},
+		{
+			ItemID:      "wm_004",
+			ItemType:    "w_matrix",
+			Score:       0.82,
+			Reason:      "Frequently purchased together",
+			Title:       "Context-Aware Embeddings",
+			Description: "Dynamic vector representations",
+		},
+		{
+			ItemID:      "rc_005",
+			ItemType:    "reasoning_chain",
+			Score:       0.78,
+			Reason:      "New release matching your interests",
+			Title:       "Hierarchical Planning System",
+			Description: "Multi-level goal decomposition",
+		},
+	}
+}
+
+func mockSimilarItems(limit int) []models.Recommendation {
+	mockSimilar := []models.Recommendation{
+		{
+			ItemID:      "rc_101",
+			ItemType:    "reasoning_chain",
+			Score:       0.92,
+			Reason:      "Similar reasoning patterns",
+			Title:       "Related Reasoning Chain A",
+			Description: "Uses similar cognitive strategies",
+		},
+		{
+			ItemID:      "rc_102",
+			ItemType:    "reasoning_chain",
+			Score:       0.87,
+			Reason:      "Same problem domain",
+			Title:       "Related Reasoning Chain B",
+			Description: "Addresses similar challenges",
+		},
+		{
+			ItemID:      "wm_103",
+			ItemType:    "w_matrix",
+			Score:       0.83,
+			Reason:      "Compatible architecture",
+			Title:       "Compatible W-Matrix",
+			Description: "Works well with this item",
+		},
+	}
+
+	sort.Slice(mockSimilar, func(i, j int) bool {
+		return mockSimilar[i].Score > mockSimilar[j].Score
+	})
+
+	if limit > 0 && limit < len(mockSimilar) {
+		mockSimilar = mockSimilar[:limit]
+	}
+
+	return mockSimilar
+}
+
+// ContentBasedFilter implements content-based filtering
+type ContentBasedFilter struct{}
+
+// GenerateRecommendations generates recommendations based on item features
+func (cbf *ContentBasedFilter) GenerateRecommendations(userID string, limit int) []models.Recommendation {
+	// Mock implementation
+	return []models.Recommendation{
+		{
+			ItemID:      "rc_201",
+			ItemType:    "reasoning_chain",
+			Score:       0.91,
+			Reason:      "Matches your preferred topics",
+			Title:       "Topic-Matched Reasoning",
+			Description: "Aligned with your interests",
+		},
+		{
+			ItemID:      "wm_202",
+			ItemType:    "w_matrix",
+			Score:       0.86,
+			Reason:      "Similar feature vectors",
+			Title:       "Feature-Matched W-Matrix",
+			Description: "High semantic similarity",
+		},
+	}
+}
+
+// HybridRecommender combines multiple recommendation strategies
+type HybridRecommender struct {
+	Collaborative *CollaborativeFilter
+	ContentBased  *ContentBasedFilter
+
+	// Bandit re-ranks GenerateRecommendations' candidate pool with LinUCB
+	// when explore=true is requested, trading some of the hybrid score's
+	// exploitation for online exploration of items this user's context
+	// hasn't been tried against yet.
+	Bandit *BanditRanker
+}
+
+// NewHybridRecommender creates a new hybrid recommender. db may be nil, in
+// which case the collaborative filter's ALS model still trains and serves
+// recommendations in memory but nothing is persisted across restarts.
+func NewHybridRecommender(db *sql.DB) *HybridRecommender {
+	cf := newCollaborativeFilter(db)
+	return &HybridRecommender{
+		Collaborative: cf,
+		ContentBased:  &ContentBasedFilter{},
+		Bandit:        newBanditRanker(db, cf.alsCfg.factors),
+	}
+}
+
+// IngestInteraction folds a tracked user interaction into the
+// collaborative filter's decayed interaction matrix and item-item
+// similarity cache. ContentBased has no interaction-driven state, so it is
+// untouched.
+func (hr *HybridRecommender) IngestInteraction(interaction models.UserInteraction) {
+	hr.Collaborative.Ingest(interaction)
+}
+
+// RegisterItem delegates to the collaborative filter so a newly listed
+// item's type is known before any interaction occurs (see
+// handlers.handleListingCreated).
+func (hr *HybridRecommender) RegisterItem(itemID, itemType string) {
+	hr.Collaborative.RegisterItem(itemID, itemType)
+}
+
+// Explain delegates to the collaborative filter, the only strategy with
+// interaction-driven state to explain.
+func (hr *HybridRecommender) Explain(userID, itemID string, topK int) ([]models.InteractionExplanation, []models.NeighborUser) {
+	return hr.Collaborative.Explain(userID, itemID, topK)
+}
+
+// GenerateRecommendations combines collaborative and content-based
+// recommendations. When explore is true, the combined candidate pool is
+// re-ranked by the LinUCB bandit instead of by raw hybrid score, trading
+// some exploitation of known-good items for exploration of ones this
+// user's context hasn't been tried against yet; each returned
+// recommendation then carries an ImpressionID that a later Feedback call
+// can attribute a reward to.
+func (hr *HybridRecommender) GenerateRecommendations(userID string, limit int, explore bool) models.RecommendationResponse {
+	poolLimit := limit
+	if explore {
+		poolLimit = limit * 3 // widen the pool so the bandit has room to reorder, not just re-score the same top-limit items
+	}
+
+	// Get recommendations from both strategies
+	collabRecs := hr.Collaborative.GenerateRecommendations(userID, poolLimit*2)
+	contentRecs := hr.ContentBased.GenerateRecommendations(userID, poolLimit*2)
+
+	// Combine and deduplicate
+	combined := make(map[string]models.Recommendation)
+	for _, rec := range collabRecs {
+		combined[rec.ItemID] = rec
+	}
+	for _, rec := range contentRecs {
+		if existing, exists := combined[rec.ItemID]; exists {
+			// Average the scores if item appears in both
+			rec.Score = (existing.Score + rec.Score) / 2
+		}
+		combined[rec.ItemID] = rec
+	}
+
+	// Convert map to slice
+	finalRecs := make([]models.Recommendation, 0, len(combined))
+	for _, rec := range combined {
+		finalRecs = append(finalRecs, rec)
+	}
+
+	// Sort by score
+	sort.Slice(finalRecs, func(i, j int) bool {
+		return finalRecs[i].Score > finalRecs[j].Score
+	})
+
+	if poolLimit > 0 && poolLimit < len(finalRecs) {
+		finalRecs = finalRecs[:poolLimit]
+	}
+
+	if explore {
+		finalRecs = hr.Bandit.Rerank(userID, finalRecs, hr.Collaborative.als, hr.Collaborative.alsCfg.factors)
+	}
+
+	if limit > 0 && limit < len(finalRecs) {
+		finalRecs = finalRecs[:limit]
+	}
+
+	return models.RecommendationResponse{
+		Recommendations: finalRecs,
+		GeneratedAt:     time.Now(),
+	}
+}
+
+// Feedback reports the observed reward for a recommendation previously
+// issued with explore=true, identified by the ImpressionID it carried. It
+// returns false if impressionID is unrecognized (unknown, already
+// reported, or evicted after impressionRetention).
+func (hr *HybridRecommender) Feedback(impressionID string, reward float64) bool {
+	return hr.Bandit.Feedback(impressionID, reward)
+}
+
+// CosineSimilarity calculates cosine similarity between two vectors
+func CosineSimilarity(a, b []float64) float64 {
+	if len(a) != len(b) {
+		return 0
+	}
+
+	var dotProduct, normA, normB float64
+	for i := range a {
+		dotProduct += a[i] * b[i]
+		normA += a[i] * a[i]
+		normB += b[i] * b[i]
+	}
+
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+
+	return dotProduct / (math.Sqrt(normA) * math.Sqrt(normB))
+}
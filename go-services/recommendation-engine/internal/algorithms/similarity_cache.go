@@ -0,0 +1,218 @@
+package algorithms
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"log"
+	"os"
+	"recommendation-engine/internal/models"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	defaultNeighborCacheTopK     = 20
+	defaultNeighborCacheInterval = 24 * time.Hour
+)
+
+// neighborCacheConfig tunes the nightly item-item neighbor precomputation.
+type neighborCacheConfig struct {
+	topK     int
+	interval time.Duration
+}
+
+// loadNeighborCacheConfig reads RECOMMENDATION_NEIGHBOR_TOPK and
+// RECOMMENDATION_NEIGHBOR_CACHE_INTERVAL_SECONDS, falling back to the
+// defaultNeighborCache* constants for anything unset or invalid.
+func loadNeighborCacheConfig() neighborCacheConfig {
+	cfg := neighborCacheConfig{topK: defaultNeighborCacheTopK, interval: defaultNeighborCacheInterval}
+	if v := os.Getenv("RECOMMENDATION_NEIGHBOR_TOPK"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			cfg.topK = n
+		}
+	}
+	if v := os.Getenv("RECOMMENDATION_NEIGHBOR_CACHE_INTERVAL_SECONDS"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			cfg.interval = time.Duration(n) * time.Second
+		}
+	}
+	return cfg
+}
+
+// NeighborCache holds the most recently precomputed top-K item-item
+// similarity neighbors, keyed by item ID. FindSimilarItems reads from it as
+// a fast path instead of recomputing cosine similarity against every other
+// item on each request; a background Run loop refreshes it nightly (see
+// neighborCacheConfig.interval), persisting the result to item_neighbors
+// (see als_storage.go's recommendation_factors for the same
+// precompute-and-persist shape applied to ALS factors) so a restart doesn't
+// serve an empty cache until the next nightly pass.
+type NeighborCache struct {
+	mu        sync.RWMutex
+	neighbors map[string][]models.Recommendation
+
+	cfg neighborCacheConfig
+	db  *sql.DB // may be nil; the cache still serves from memory, just isn't persisted
+}
+
+func newNeighborCache(db *sql.DB) *NeighborCache {
+	nc := &NeighborCache{
+		neighbors: make(map[string][]models.Recommendation),
+		cfg:       loadNeighborCacheConfig(),
+		db:        db,
+	}
+	if db != nil {
+		if neighbors := loadNeighbors(context.Background(), db); len(neighbors) > 0 {
+			nc.neighbors = neighbors
+		}
+	}
+	return nc
+}
+
+// Get returns itemID's precomputed neighbor list, or ok=false if it hasn't
+// been computed yet (e.g. a brand-new item, or before the first nightly
+// pass completes).
+func (nc *NeighborCache) Get(itemID string, limit int) (recs []models.Recommendation, ok bool) {
+	nc.mu.RLock()
+	defer nc.mu.RUnlock()
+
+	found, ok := nc.neighbors[itemID]
+	if !ok {
+		return nil, false
+	}
+	if limit > 0 && limit < len(found) {
+		return found[:limit], true
+	}
+	return found, true
+}
+
+// Run recomputes the neighbor cache from itemFactors immediately, then
+// again every cfg.interval until ctx is canceled.
+func (nc *NeighborCache) Run(ctx context.Context, itemFactorsOf func() (map[string][]float64, map[string]string)) {
+	nc.recompute(ctx, itemFactorsOf)
+
+	ticker := time.NewTicker(nc.cfg.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			nc.recompute(ctx, itemFactorsOf)
+		}
+	}
+}
+
+// recompute ranks every item's top-K cosine-similarity neighbors from the
+// current ALS item factors and swaps the cache wholesale, so readers never
+// see a partially-updated neighbor list.
+func (nc *NeighborCache) recompute(ctx context.Context, itemFactorsOf func() (map[string][]float64, map[string]string)) {
+	itemFactors, itemType := itemFactorsOf()
+	if len(itemFactors) == 0 {
+		return
+	}
+
+	ids := make([]string, 0, len(itemFactors))
+	for id := range itemFactors {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	neighbors := make(map[string][]models.Recommendation, len(ids))
+	for _, id := range ids {
+		target := itemFactors[id]
+		recs := make([]models.Recommendation, 0, len(ids)-1)
+		for _, other := range ids {
+			if other == id {
+				continue
+			}
+			recs = append(recs, models.Recommendation{
+				ItemID:   other,
+				ItemType: itemType[other],
+				Score:    CosineSimilarity(target, itemFactors[other]),
+				Reason:   "Similar latent factors to " + id,
+			})
+		}
+		sort.Slice(recs, func(i, j int) bool { return recs[i].Score > recs[j].Score })
+		if len(recs) > nc.cfg.topK {
+			recs = recs[:nc.cfg.topK]
+		}
+		neighbors[id] = recs
+	}
+
+	nc.mu.Lock()
+	nc.neighbors = neighbors
+	nc.mu.Unlock()
+
+	if nc.db != nil {
+		if err := persistNeighbors(ctx, nc.db, neighbors); err != nil {
+			log.Printf("recommendation-engine: failed to persist item neighbor cache: %v", err)
+		}
+	}
+}
+
+// loadNeighbors restores a previously persisted neighbor cache from
+// item_neighbors, so the service doesn't serve an empty cache immediately
+// after a restart. Best-effort: any failure just leaves the cache empty
+// until the next nightly recompute.
+func loadNeighbors(ctx context.Context, db *sql.DB) map[string][]models.Recommendation {
+	rows, err := db.QueryContext(ctx, `SELECT item_id, neighbors FROM item_neighbors`)
+	if err != nil {
+		log.Printf("recommendation-engine: failed to load item neighbor cache: %v", err)
+		return nil
+	}
+	defer rows.Close()
+
+	neighbors := make(map[string][]models.Recommendation)
+	for rows.Next() {
+		var itemID string
+		var blob []byte
+		if err := rows.Scan(&itemID, &blob); err != nil {
+			log.Printf("recommendation-engine: failed to scan item neighbor row: %v", err)
+			continue
+		}
+		var recs []models.Recommendation
+		if err := json.Unmarshal(blob, &recs); err != nil {
+			log.Printf("recommendation-engine: failed to decode item neighbors for %s: %v", itemID, err)
+			continue
+		}
+		neighbors[itemID] = recs
+	}
+	if err := rows.Err(); err != nil {
+		log.Printf("recommendation-engine: error reading item neighbor cache: %v", err)
+	}
+	return neighbors
+}
+
+// persistNeighbors replaces the contents of item_neighbors with neighbors
+// inside one transaction, the same wholesale delete-then-insert
+// persistFactors uses for recommendation_factors.
+func persistNeighbors(ctx context.Context, db *sql.DB, neighbors map[string][]models.Recommendation) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM item_neighbors`); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for itemID, recs := range neighbors {
+		blob, err := json.Marshal(recs)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx,
+			`INSERT INTO item_neighbors (item_id, neighbors, updated_at) VALUES (?, ?, ?)`,
+			itemID, blob, now,
+		); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
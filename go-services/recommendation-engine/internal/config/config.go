@@ -0,0 +1,83 @@
+package config
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"os"
+
+	"recommendation-engine/internal/database/migrations"
+	"recommendation-engine/internal/events"
+
+	_ "github.com/go-sql-driver/mysql"
+)
+
+type Config struct {
+	Port      string
+	DB        *sql.DB
+	APISecret string
+	Events    events.Broker
+}
+
+// Load builds the service config from the environment. If DATABASE_URL is
+// unset, the service runs without persistence (tracked interactions still
+// update the in-memory recommender, see handlers.NewHandler). If
+// DATABASE_URL is set, Load fails fast on a connection error instead of
+// falling back to mock data: a reachability or schema problem here should
+// stop the service from starting, not surface later as a failed query.
+//
+// Once connected, Load brings the schema up to date per MIGRATION_MODE
+// (auto, verify, or off; default auto) — see internal/database/migrations.
+func Load() (*Config, error) {
+	port := os.Getenv("PORT")
+	if port == "" {
+		port = "8085"
+	}
+
+	dbURL := os.Getenv("DATABASE_URL")
+	apiSecret := os.Getenv("API_SECRET")
+	if apiSecret == "" {
+		apiSecret = "default-secret-key"
+	}
+
+	mode, err := migrations.ParseMode(os.Getenv("MIGRATION_MODE"))
+	if err != nil {
+		return nil, err
+	}
+
+	var db *sql.DB
+	if dbURL != "" {
+		db, err = sql.Open("mysql", dbURL)
+		if err != nil {
+			return nil, fmt.Errorf("open database: %w", err)
+		}
+		if err := db.Ping(); err != nil {
+			return nil, fmt.Errorf("ping database: %w", err)
+		}
+
+		if err := migrations.Migrate(context.Background(), db, mode); err != nil {
+			return nil, fmt.Errorf("migrate database: %w", err)
+		}
+	} else {
+		log.Println("DATABASE_URL not set; running without persistence")
+	}
+
+	// EVENT_BROKER selects the event-bus implementation (kafka, nats, or
+	// unset/memory for an in-process broker with no cross-process
+	// delivery); see internal/events.
+	broker, err := events.New(events.BrokerConfig{
+		Kind: os.Getenv("EVENT_BROKER"),
+		URL:  os.Getenv("EVENT_BROKER_URL"),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("init event broker: %w", err)
+	}
+
+	return &Config{
+		Port:      port,
+		DB:        db,
+		APISecret: apiSecret,
+		Events:    broker,
+	}, nil
+}
@@ -0,0 +1,372 @@
+// Package migrations is a minimal, dependency-free SQL migration runner for
+// the recommendation-engine's MySQL schema. Migrations are plain
+// NNNN_description.up.sql / .down.sql files embedded into the binary so a
+// deployed service always carries the exact schema it expects, and applied
+// versions are tracked in a schema_migrations table so drift between the
+// embedded migrations and the live database is detectable at startup
+// instead of surfacing as a query-time "table doesn't exist" error.
+package migrations
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"embed"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//go:embed sql/*.sql
+var sqlFS embed.FS
+
+// Migration is one NNNN_description pair loaded from the embedded sql/
+// directory.
+type Migration struct {
+	Version  int
+	Name     string
+	Up       string
+	Down     string
+	Checksum string // sha256 of Up, used to detect drift against what was applied
+}
+
+var filenamePattern = regexp.MustCompile(`^(\d{4,})_(.+)\.(up|down)\.sql$`)
+
+// Load reads and parses every embedded .sql file into version-ordered
+// Migrations. It fails if an "up" file has no matching "down" file or vice
+// versa, since Down() needs both to be present to roll back cleanly.
+func Load() ([]Migration, error) {
+	entries, err := fs.ReadDir(sqlFS, "sql")
+	if err != nil {
+		return nil, fmt.Errorf("read embedded migrations: %w", err)
+	}
+
+	type halves struct {
+		name     string
+		up, down string
+		hasUp    bool
+		hasDown  bool
+	}
+	byVersion := make(map[int]*halves)
+
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		m := filenamePattern.FindStringSubmatch(entry.Name())
+		if m == nil {
+			return nil, fmt.Errorf("migrations: unexpected file %q, want NNNN_name.up.sql or .down.sql", entry.Name())
+		}
+		version, err := strconv.Atoi(m[1])
+		if err != nil {
+			return nil, fmt.Errorf("migrations: invalid version in %q: %w", entry.Name(), err)
+		}
+		contents, err := fs.ReadFile(sqlFS, path.Join("sql", entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("read %q: %w", entry.Name(), err)
+		}
+
+		h, ok := byVersion[version]
+		if !ok {
+			h = &halves{name: m[2]}
+			byVersion[version] = h
+		}
+		switch m[3] {
+		case "up":
+			h.up, h.hasUp = string(contents), true
+		case "down":
+			h.down, h.hasDown = string(contents), true
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for version, h := range byVersion {
+		if !h.hasUp {
+			return nil, fmt.Errorf("migrations: version %04d has a down file but no up file", version)
+		}
+		if !h.hasDown {
+			return nil, fmt.Errorf("migrations: version %04d has an up file but no down file", version)
+		}
+		sum := sha256.Sum256([]byte(h.up))
+		migrations = append(migrations, Migration{
+			Version:  version,
+			Name:     h.name,
+			Up:       h.up,
+			Down:     h.down,
+			Checksum: hex.EncodeToString(sum[:]),
+		})
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+
+	return migrations, nil
+}
+
+// Applied is one row of schema_migrations.
+type Applied struct {
+	Version   int
+	AppliedAt time.Time
+	Checksum  string
+}
+
+// StatusEntry describes one embedded migration's relationship to the
+// database: applied or pending, and whether its checksum still matches
+// what was recorded when it was applied.
+type StatusEntry struct {
+	Migration Migration
+	Applied   bool
+	AppliedAt time.Time
+	Drifted   bool // applied, but the embedded migration's checksum no longer matches
+}
+
+// Migrator applies and rolls back the embedded migrations against db,
+// tracking progress in a schema_migrations table.
+type Migrator struct {
+	db         *sql.DB
+	migrations []Migration
+}
+
+// NewMigrator loads the embedded migrations and binds them to db. It does
+// not touch the database until one of the Migrator's methods is called.
+func NewMigrator(db *sql.DB) (*Migrator, error) {
+	migrations, err := Load()
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{db: db, migrations: migrations}, nil
+}
+
+func (m *Migrator) ensureSchemaMigrationsTable(ctx context.Context) error {
+	_, err := m.db.ExecContext(ctx, `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version     BIGINT PRIMARY KEY,
+			applied_at  DATETIME NOT NULL,
+			checksum    VARCHAR(64) NOT NULL
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("create schema_migrations: %w", err)
+	}
+	return nil
+}
+
+func (m *Migrator) applied(ctx context.Context) (map[int]Applied, error) {
+	if err := m.ensureSchemaMigrationsTable(ctx); err != nil {
+		return nil, err
+	}
+	rows, err := m.db.QueryContext(ctx, `SELECT version, applied_at, checksum FROM schema_migrations`)
+	if err != nil {
+		return nil, fmt.Errorf("query schema_migrations: %w", err)
+	}
+	defer rows.Close()
+
+	applied := make(map[int]Applied)
+	for rows.Next() {
+		var a Applied
+		if err := rows.Scan(&a.Version, &a.AppliedAt, &a.Checksum); err != nil {
+			return nil, fmt.Errorf("scan schema_migrations row: %w", err)
+		}
+		applied[a.Version] = a
+	}
+	return applied, rows.Err()
+}
+
+// VerifyChecksums confirms every already-applied migration's embedded
+// checksum still matches what was recorded at apply time. A mismatch means
+// a migration file was edited after shipping, which would otherwise go
+// unnoticed until it produced a subtly different schema on the next deploy.
+func (m *Migrator) VerifyChecksums(ctx context.Context) error {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return err
+	}
+	var drifted []string
+	for _, mig := range m.migrations {
+		a, ok := applied[mig.Version]
+		if !ok {
+			continue
+		}
+		if a.Checksum != mig.Checksum {
+			drifted = append(drifted, fmt.Sprintf("%04d_%s (applied checksum %s, embedded %s)", mig.Version, mig.Name, a.Checksum[:8], mig.Checksum[:8]))
+		}
+	}
+	if len(drifted) > 0 {
+		return fmt.Errorf("migrations: checksum drift detected: %s", strings.Join(drifted, "; "))
+	}
+	return nil
+}
+
+// Pending returns the embedded migrations that have not yet been applied,
+// in version order.
+func (m *Migrator) Pending(ctx context.Context) ([]Migration, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var pending []Migration
+	for _, mig := range m.migrations {
+		if _, ok := applied[mig.Version]; !ok {
+			pending = append(pending, mig)
+		}
+	}
+	return pending, nil
+}
+
+// Status reports, for every embedded migration, whether it has been
+// applied and whether its checksum has drifted since then.
+func (m *Migrator) Status(ctx context.Context) ([]StatusEntry, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]StatusEntry, 0, len(m.migrations))
+	for _, mig := range m.migrations {
+		a, ok := applied[mig.Version]
+		entries = append(entries, StatusEntry{
+			Migration: mig,
+			Applied:   ok,
+			AppliedAt: a.AppliedAt,
+			Drifted:   ok && a.Checksum != mig.Checksum,
+		})
+	}
+	return entries, nil
+}
+
+// Latest migrates all the way to the newest embedded migration. Pass to Up.
+const Latest = -1
+
+// Up applies every pending migration up to and including targetVersion, in
+// version order, each inside its own transaction. Pass Latest to migrate
+// all the way to the newest embedded migration.
+func (m *Migrator) Up(ctx context.Context, targetVersion int) error {
+	if err := m.VerifyChecksums(ctx); err != nil {
+		return err
+	}
+	pending, err := m.Pending(ctx)
+	if err != nil {
+		return err
+	}
+	for _, mig := range pending {
+		if targetVersion != Latest && mig.Version > targetVersion {
+			break
+		}
+		if err := m.applyOne(ctx, mig); err != nil {
+			return fmt.Errorf("apply migration %04d_%s: %w", mig.Version, mig.Name, err)
+		}
+	}
+	return nil
+}
+
+func (m *Migrator) applyOne(ctx context.Context, mig Migration) error {
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, mig.Up); err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx,
+		`INSERT INTO schema_migrations (version, applied_at, checksum) VALUES (?, ?, ?)`,
+		mig.Version, time.Now(), mig.Checksum,
+	)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// Down rolls back the `steps` most-recently-applied migrations, most recent
+// first, running each one's Down script.
+func (m *Migrator) Down(ctx context.Context, steps int) error {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return err
+	}
+	byVersion := make(map[int]Migration, len(m.migrations))
+	for _, mig := range m.migrations {
+		byVersion[mig.Version] = mig
+	}
+
+	appliedVersions := make([]int, 0, len(applied))
+	for v := range applied {
+		appliedVersions = append(appliedVersions, v)
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(appliedVersions)))
+
+	if steps > len(appliedVersions) {
+		steps = len(appliedVersions)
+	}
+	for i := 0; i < steps; i++ {
+		version := appliedVersions[i]
+		mig, ok := byVersion[version]
+		if !ok {
+			return fmt.Errorf("migrations: applied version %04d has no embedded migration to roll back with (force a known version first)", version)
+		}
+		if err := m.revertOne(ctx, mig); err != nil {
+			return fmt.Errorf("revert migration %04d_%s: %w", mig.Version, mig.Name, err)
+		}
+	}
+	return nil
+}
+
+func (m *Migrator) revertOne(ctx context.Context, mig Migration) error {
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, mig.Down); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM schema_migrations WHERE version = ?`, mig.Version); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// Force records targetVersion as applied (with its embedded checksum, and
+// without running its Up script) and discards any schema_migrations rows
+// for versions above it. It's an operator escape hatch for after a manual
+// schema fix or a migration that failed partway through applying.
+func (m *Migrator) Force(ctx context.Context, targetVersion int) error {
+	if err := m.ensureSchemaMigrationsTable(ctx); err != nil {
+		return err
+	}
+
+	var target *Migration
+	for i := range m.migrations {
+		if m.migrations[i].Version == targetVersion {
+			target = &m.migrations[i]
+			break
+		}
+	}
+	if target == nil {
+		return fmt.Errorf("migrations: no embedded migration with version %04d", targetVersion)
+	}
+
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM schema_migrations WHERE version > ?`, targetVersion); err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO schema_migrations (version, applied_at, checksum) VALUES (?, ?, ?)
+		ON DUPLICATE KEY UPDATE applied_at = VALUES(applied_at), checksum = VALUES(checksum)
+	`, target.Version, time.Now(), target.Checksum)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,70 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// Mode controls what Migrate does at startup.
+type Mode string
+
+const (
+	// ModeAuto applies all pending migrations up to Latest. The default.
+	ModeAuto Mode = "auto"
+	// ModeVerify checks the schema against the embedded migrations
+	// (checksums of what's applied, plus whether anything is pending) but
+	// never runs SQL. Intended for environments where migrations are
+	// applied out-of-band (e.g. a deploy-pipeline step) and the service
+	// should simply refuse to start against a schema it doesn't expect.
+	ModeVerify Mode = "verify"
+	// ModeOff skips schema verification entirely.
+	ModeOff Mode = "off"
+)
+
+// ParseMode maps a MIGRATION_MODE env value to a Mode, defaulting to
+// ModeAuto for an empty string and rejecting anything unrecognized so a
+// typo'd env var fails at startup rather than silently behaving like "off".
+func ParseMode(s string) (Mode, error) {
+	switch Mode(s) {
+	case "":
+		return ModeAuto, nil
+	case ModeAuto, ModeVerify, ModeOff:
+		return Mode(s), nil
+	default:
+		return "", fmt.Errorf("migrations: unknown MIGRATION_MODE %q (want auto, verify, or off)", s)
+	}
+}
+
+// Migrate brings db's schema in line with the embedded migrations
+// according to mode. It's meant to be called once, right after a
+// successful Ping, before the service starts serving requests.
+func Migrate(ctx context.Context, db *sql.DB, mode Mode) error {
+	if mode == ModeOff {
+		return nil
+	}
+
+	migrator, err := NewMigrator(db)
+	if err != nil {
+		return err
+	}
+
+	switch mode {
+	case ModeAuto:
+		return migrator.Up(ctx, Latest)
+	case ModeVerify:
+		if err := migrator.VerifyChecksums(ctx); err != nil {
+			return err
+		}
+		pending, err := migrator.Pending(ctx)
+		if err != nil {
+			return err
+		}
+		if len(pending) > 0 {
+			return fmt.Errorf("migrations: %d migration(s) pending and MIGRATION_MODE=verify does not apply them (run cmd/migrate up, or set MIGRATION_MODE=auto)", len(pending))
+		}
+		return nil
+	default:
+		return fmt.Errorf("migrations: unknown mode %q", mode)
+	}
+}
@@ -0,0 +1,89 @@
+// Package events implements a typed publish/subscribe event bus for
+// cross-service notifications: interactions tracked here, listings
+// created or purchased in the marketplace, and vectors stored in
+// vector-operations. Brokers are pluggable (see Broker) so the same
+// producer/consumer code runs against Kafka, NATS JetStream, or an
+// in-memory implementation used for local development and when no
+// external broker is configured.
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+// Type identifies the shape of an event's Payload.
+type Type string
+
+const (
+	TypeInteractionRecorded Type = "InteractionRecorded"
+	TypeListingCreated      Type = "ListingCreated"
+	TypeListingPurchased    Type = "ListingPurchased"
+	TypeVectorStored        Type = "VectorStored"
+)
+
+// Topic names events are published and subscribed under. Each has a
+// paired dead-letter topic (see DLQTopic) that a handler's permanent
+// failures are routed to instead of being retried forever.
+const (
+	TopicInteractionRecorded = "interaction.recorded"
+	TopicListingCreated      = "listing.created"
+	TopicListingPurchased    = "listing.purchased"
+	TopicVectorStored        = "vector.stored"
+)
+
+// DLQTopic returns the dead-letter topic a delivery that exhausted
+// maxDeliveryAttempts on topic is routed to.
+func DLQTopic(topic string) string {
+	return topic + ".dlq"
+}
+
+// Event is the envelope every Broker implementation publishes and
+// delivers. Key is an idempotency key: at-least-once delivery means a
+// subscriber may see the same Key redelivered, so Broker implementations
+// dedupe on it within dedupeWindow rather than requiring every handler to
+// do so itself.
+type Event struct {
+	Type       Type            `json:"type"`
+	Key        string          `json:"key"`
+	Payload    json.RawMessage `json:"payload"`
+	ProducedAt time.Time       `json:"produced_at"`
+	// Attempt is the redelivery count, incremented each time a broker
+	// retries a failed handler; on the copy routed to a DLQ it reflects
+	// the attempt that finally gave up.
+	Attempt int `json:"attempt,omitempty"`
+}
+
+// New builds an Event of type typ, marshaling payload and tagging it with
+// idempotencyKey.
+func New(typ Type, idempotencyKey string, payload interface{}) (Event, error) {
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		return Event{}, err
+	}
+	return Event{
+		Type:       typ,
+		Key:        idempotencyKey,
+		Payload:    raw,
+		ProducedAt: time.Now(),
+	}, nil
+}
+
+// Handler processes one delivered event. Returning an error causes the
+// broker to retry delivery up to maxDeliveryAttempts times before routing
+// the event to DLQTopic(topic) instead of retrying forever.
+type Handler func(ctx context.Context, evt Event) error
+
+// Broker publishes events to, and delivers them from, topics. Publish may
+// be called before any Subscribe exists for that topic.
+type Broker interface {
+	Publish(ctx context.Context, topic string, evt Event) error
+	// Subscribe registers handler to receive events published to topic,
+	// dispatching on a background goroutine until ctx is canceled. group
+	// identifies this consumer's durable position on topic, so several
+	// independent consumers (e.g. "recommendation-engine", "vector-indexer")
+	// can each track their own delivery cursor on the same topic.
+	Subscribe(ctx context.Context, topic, group string, handler Handler) error
+	Close() error
+}
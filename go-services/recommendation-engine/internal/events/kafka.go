@@ -0,0 +1,128 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+
+	kafka "github.com/segmentio/kafka-go"
+)
+
+// KafkaBroker publishes/subscribes via segmentio/kafka-go: one Writer per
+// published topic, and one consumer-group Reader per Subscribe. A
+// message's offset is only committed after its handler succeeds (or the
+// event has been dead-lettered), giving at-least-once delivery.
+type KafkaBroker struct {
+	brokers []string
+
+	mu      sync.Mutex
+	writers map[string]*kafka.Writer
+
+	dedup *deduper
+}
+
+// NewKafkaBroker connects to the comma-separated broker addresses in
+// brokerURLs (e.g. "kafka-0:9092,kafka-1:9092").
+func NewKafkaBroker(brokerURLs string) *KafkaBroker {
+	return &KafkaBroker{
+		brokers: strings.Split(brokerURLs, ","),
+		writers: make(map[string]*kafka.Writer),
+		dedup:   newDeduper(),
+	}
+}
+
+func (b *KafkaBroker) writerFor(topic string) *kafka.Writer {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	w, ok := b.writers[topic]
+	if !ok {
+		w = &kafka.Writer{
+			Addr: kafka.TCP(b.brokers...),
+			Topic: topic,
+			// Key-hashed so events sharing an idempotency key land on the
+			// same partition, preserving per-key ordering.
+			Balancer:     &kafka.Hash{},
+			RequiredAcks: kafka.RequireAll,
+		}
+		b.writers[topic] = w
+	}
+	return w
+}
+
+func (b *KafkaBroker) Publish(ctx context.Context, topic string, evt Event) error {
+	payload, err := json.Marshal(evt)
+	if err != nil {
+		return fmt.Errorf("marshal event: %w", err)
+	}
+	if err := b.writerFor(topic).WriteMessages(ctx, kafka.Message{Key: []byte(evt.Key), Value: payload}); err != nil {
+		return fmt.Errorf("publish to %s: %w", topic, err)
+	}
+	publishedTotal.WithLabelValues(topic).Inc()
+	return nil
+}
+
+func (b *KafkaBroker) Subscribe(ctx context.Context, topic, group string, handler Handler) error {
+	reader := kafka.NewReader(kafka.ReaderConfig{
+		Brokers: b.brokers,
+		Topic:   topic,
+		GroupID: group,
+	})
+
+	go b.consume(ctx, reader, topic, group, handler)
+	return nil
+}
+
+func (b *KafkaBroker) consume(ctx context.Context, reader *kafka.Reader, topic, group string, handler Handler) {
+	defer reader.Close()
+
+	for {
+		msg, err := reader.FetchMessage(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			log.Printf("events: kafka fetch from %s/%s failed: %v", topic, group, err)
+			continue
+		}
+
+		consumerLag.WithLabelValues(topic, group).Set(float64(reader.Lag()))
+		b.handle(ctx, msg, reader, topic, group, handler)
+	}
+}
+
+func (b *KafkaBroker) handle(ctx context.Context, msg kafka.Message, reader *kafka.Reader, topic, group string, handler Handler) {
+	var evt Event
+	if err := json.Unmarshal(msg.Value, &evt); err != nil {
+		log.Printf("events: failed to decode message from %s: %v", topic, err)
+	} else if evt.Key == "" || !b.dedup.seenRecently(group, evt.Key) {
+		if err := withRetry(ctx, topic, group, evt, handler); err != nil {
+			deadLetteredTotal.WithLabelValues(topic, group).Inc()
+			dlqEvt := evt
+			dlqEvt.Attempt++
+			if perr := b.Publish(ctx, DLQTopic(topic), dlqEvt); perr != nil {
+				log.Printf("events: failed to dead-letter event from %s: %v", topic, perr)
+			}
+		} else if evt.Key != "" {
+			b.dedup.mark(group, evt.Key)
+		}
+	}
+
+	if err := reader.CommitMessages(ctx, msg); err != nil {
+		log.Printf("events: failed to commit offset on %s/%s: %v", topic, group, err)
+	}
+}
+
+func (b *KafkaBroker) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	var firstErr error
+	for _, w := range b.writers {
+		if err := w.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
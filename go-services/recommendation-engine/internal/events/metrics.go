@@ -0,0 +1,36 @@
+package events
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// These are shared by every Broker implementation so operators see one
+// consistent set of event-bus metrics regardless of which broker a
+// service is configured with.
+var (
+	publishedTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "events_published_total",
+		Help: "Events successfully published, by topic.",
+	}, []string{"topic"})
+
+	consumedTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "events_consumed_total",
+		Help: "Events successfully handled, by topic and consumer group.",
+	}, []string{"topic", "group"})
+
+	consumeErrorsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "events_consume_errors_total",
+		Help: "Handler invocations that returned an error, by topic and consumer group.",
+	}, []string{"topic", "group"})
+
+	deadLetteredTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "events_dead_lettered_total",
+		Help: "Events routed to a dead-letter topic after exhausting delivery attempts.",
+	}, []string{"topic", "group"})
+
+	consumerLag = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "events_consumer_lag",
+		Help: "Most recently observed broker-reported lag (messages behind the topic head), by topic and consumer group.",
+	}, []string{"topic", "group"})
+)
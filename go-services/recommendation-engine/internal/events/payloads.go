@@ -0,0 +1,42 @@
+package events
+
+import "time"
+
+// InteractionRecordedPayload is the payload of an InteractionRecorded
+// event: a user's tracked interaction with an item, as recorded by
+// POST /api/v1/recommendations/track.
+type InteractionRecordedPayload struct {
+	UserID    string    `json:"user_id"`
+	ItemID    string    `json:"item_id"`
+	ItemType  string    `json:"item_type"`
+	Action    string    `json:"action"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// ListingCreatedPayload is the payload of a ListingCreated event: a new
+// marketplace listing that downstream services (vector indexing, this
+// service's cold-start item registration) should pick up.
+type ListingCreatedPayload struct {
+	ItemID    string    `json:"item_id"`
+	ItemType  string    `json:"item_type"` // "reasoning_chain" or "w_matrix"
+	CreatorID string    `json:"creator_id,omitempty"`
+	Title     string    `json:"title,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ListingPurchasedPayload is the payload of a ListingPurchased event.
+type ListingPurchasedPayload struct {
+	ItemID      string    `json:"item_id"`
+	BuyerID     string    `json:"buyer_id"`
+	Price       float64   `json:"price"`
+	PurchasedAt time.Time `json:"purchased_at"`
+}
+
+// VectorStoredPayload is the payload of a VectorStored event: a new or
+// updated embedding that search/indexing consumers should pick up.
+type VectorStoredPayload struct {
+	VectorID  string    `json:"vector_id"`
+	ItemType  string    `json:"item_type,omitempty"`
+	Dimension int       `json:"dimension"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,306 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"recommendation-engine/internal/algorithms"
+	"recommendation-engine/internal/events"
+	"recommendation-engine/internal/models"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// eventConsumerGroup identifies this service's durable position on topics
+// it subscribes to (see internal/events.Broker.Subscribe).
+const eventConsumerGroup = "recommendation-engine"
+
+type Handler struct {
+	Recommender *algorithms.HybridRecommender
+	DB          *sql.DB
+	Events      events.Broker
+}
+
+// NewHandler creates a Handler backed by a fresh HybridRecommender. db may
+// be nil, in which case tracked interactions still update the recommender
+// but are not persisted, matching this service's no-DATABASE_URL mode (see
+// config.Load). broker may not be nil; pass events.NewMemoryBroker() for a
+// no-op, no-cross-process-delivery default.
+func NewHandler(db *sql.DB, broker events.Broker) *Handler {
+	return &Handler{
+		Recommender: algorithms.NewHybridRecommender(db),
+		DB:          db,
+		Events:      broker,
+	}
+}
+
+// GetRecommendations godoc
+// @Summary Get personalized recommendations
+// @Description Get personalized recommendations for a user
+// @Tags recommendations
+// @Accept json
+// @Produce json
+// @Param user_id query string true "User ID"
+// @Param limit query int false "Number of recommendations" default(10)
+// @Param type query string false "Filter by type (reasoning_chain or w_matrix)"
+// @Param explore query bool false "Re-rank with the LinUCB bandit for online exploration instead of raw hybrid score"
+// @Success 200 {object} models.RecommendationResponse
+// @Router /api/v1/recommendations [get]
+// @Security ApiKeyAuth
+func (h *Handler) GetRecommendations(c *gin.Context) {
+	userID := c.Query("user_id")
+	if userID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "user_id is required"})
+		return
+	}
+
+	limitStr := c.DefaultQuery("limit", "10")
+	limit, err := strconv.Atoi(limitStr)
+	if err != nil || limit < 1 {
+		limit = 10
+	}
+
+	itemType := c.Query("type")
+	explore := c.Query("explore") == "true"
+
+	response := h.Recommender.GenerateRecommendations(userID, limit, explore)
+
+	// Filter by type if specified
+	if itemType != "" {
+		filtered := []models.Recommendation{}
+		for _, rec := range response.Recommendations {
+			if rec.ItemType == itemType {
+				filtered = append(filtered, rec)
+			}
+		}
+		response.Recommendations = filtered
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// GetSimilarItems godoc
+// @Summary Get similar items
+// @Description Find items similar to the given item
+// @Tags recommendations
+// @Accept json
+// @Produce json
+// @Param item_id query string true "Item ID"
+// @Param limit query int false "Number of similar items" default(5)
+// @Success 200 {object} models.RecommendationResponse
+// @Router /api/v1/recommendations/similar [get]
+// @Security ApiKeyAuth
+func (h *Handler) GetSimilarItems(c *gin.Context) {
+	itemID := c.Query("item_id")
+	if itemID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "item_id is required"})
+		return
+	}
+
+	limitStr := c.DefaultQuery("limit", "5")
+	limit, err := strconv.Atoi(limitStr)
+	if err != nil || limit < 1 {
+		limit = 5
+	}
+
+	similar := h.Recommender.Collaborative.FindSimilarItems(itemID, limit)
+
+	c.JSON(http.StatusOK, models.RecommendationResponse{
+		Recommendations: similar,
+	})
+}
+
+// TrackInteraction godoc
+// @Summary Track user interaction
+// @Description Record a user's interaction with an item for future recommendations
+// @Tags recommendations
+// @Accept json
+// @Produce json
+// @Param interaction body models.UserInteraction true "User interaction data"
+// @Success 200 {object} map[string]string
+// @Router /api/v1/recommendations/track [post]
+// @Security ApiKeyAuth
+func (h *Handler) TrackInteraction(c *gin.Context) {
+	var interaction models.UserInteraction
+	if err := c.ShouldBindJSON(&interaction); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	h.trackInteraction(interaction)
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Interaction tracked successfully",
+		"user_id": interaction.UserID,
+		"item_id": interaction.ItemID,
+	})
+}
+
+// BatchTrackInteractions godoc
+// @Summary Bulk-track user interactions
+// @Description Replay a batch of interactions, e.g. to backfill the recommender after an outage
+// @Tags recommendations
+// @Accept json
+// @Produce json
+// @Param request body models.BatchTrackRequest true "Interactions to replay"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/recommendations/batch-track [post]
+// @Security ApiKeyAuth
+func (h *Handler) BatchTrackInteractions(c *gin.Context) {
+	var req models.BatchTrackRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	for _, interaction := range req.Interactions {
+		h.trackInteraction(interaction)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Interactions tracked successfully",
+		"count":   len(req.Interactions),
+	})
+}
+
+// trackInteraction persists interaction to user_interactions (best-effort:
+// a DB failure is logged but does not fail the request), folds it into the
+// recommender's interaction matrix, and publishes an InteractionRecorded
+// event (also best-effort) so other services, e.g. the marketplace's
+// trending-items view, can react without polling this service.
+func (h *Handler) trackInteraction(interaction models.UserInteraction) {
+	if interaction.Timestamp.IsZero() {
+		interaction.Timestamp = time.Now()
+	}
+
+	if h.DB != nil {
+		_, err := h.DB.Exec(
+			`INSERT INTO user_interactions (user_id, item_id, item_type, action, created_at) VALUES (?, ?, ?, ?, ?)`,
+			interaction.UserID, interaction.ItemID, interaction.ItemType, interaction.Action, interaction.Timestamp,
+		)
+		if err != nil {
+			log.Printf("recommendation-engine: failed to persist interaction for user %s: %v", interaction.UserID, err)
+		}
+	}
+
+	h.Recommender.IngestInteraction(interaction)
+	h.publishInteractionRecorded(interaction)
+}
+
+// publishInteractionRecorded is best-effort: a broker outage shouldn't
+// fail a track request whose interaction has already been ingested above.
+func (h *Handler) publishInteractionRecorded(interaction models.UserInteraction) {
+	key := fmt.Sprintf("interaction:%s:%s:%d", interaction.UserID, interaction.ItemID, interaction.Timestamp.UnixNano())
+	evt, err := events.New(events.TypeInteractionRecorded, key, events.InteractionRecordedPayload{
+		UserID:    interaction.UserID,
+		ItemID:    interaction.ItemID,
+		ItemType:  interaction.ItemType,
+		Action:    interaction.Action,
+		Timestamp: interaction.Timestamp,
+	})
+	if err != nil {
+		log.Printf("recommendation-engine: failed to build InteractionRecorded event: %v", err)
+		return
+	}
+	if err := h.Events.Publish(context.Background(), events.TopicInteractionRecorded, evt); err != nil {
+		log.Printf("recommendation-engine: failed to publish InteractionRecorded event: %v", err)
+	}
+}
+
+// SubscribeEvents wires this handler's consumption of cross-service
+// events: new marketplace listings are registered with the recommender
+// ahead of any interaction, so cold-start scoring already knows their
+// item type. Call once at startup; delivery itself continues on a
+// background goroutine until ctx is canceled.
+func (h *Handler) SubscribeEvents(ctx context.Context) error {
+	return h.Events.Subscribe(ctx, events.TopicListingCreated, eventConsumerGroup, h.handleListingCreated)
+}
+
+func (h *Handler) handleListingCreated(ctx context.Context, evt events.Event) error {
+	var payload events.ListingCreatedPayload
+	if err := json.Unmarshal(evt.Payload, &payload); err != nil {
+		return fmt.Errorf("decode ListingCreated payload: %w", err)
+	}
+	h.Recommender.RegisterItem(payload.ItemID, payload.ItemType)
+	return nil
+}
+
+// ExplainRecommendation godoc
+// @Summary Explain a recommendation
+// @Description Return the user's top interactions and the neighbor users that drove item_id's score for user_id
+// @Tags recommendations
+// @Produce json
+// @Param user_id query string true "User ID"
+// @Param item_id query string true "Item ID"
+// @Param limit query int false "Number of interactions/neighbors to return" default(5)
+// @Success 200 {object} models.ExplainResponse
+// @Router /api/v1/recommendations/explain [get]
+// @Security ApiKeyAuth
+func (h *Handler) ExplainRecommendation(c *gin.Context) {
+	userID := c.Query("user_id")
+	itemID := c.Query("item_id")
+	if userID == "" || itemID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "user_id and item_id are required"})
+		return
+	}
+
+	limitStr := c.DefaultQuery("limit", "5")
+	limit, err := strconv.Atoi(limitStr)
+	if err != nil || limit < 1 {
+		limit = 5
+	}
+
+	topInteractions, neighbors := h.Recommender.Explain(userID, itemID, limit)
+
+	c.JSON(http.StatusOK, models.ExplainResponse{
+		UserID:          userID,
+		ItemID:          itemID,
+		TopInteractions: topInteractions,
+		NeighborUsers:   neighbors,
+	})
+}
+
+// RecommendationFeedback godoc
+// @Summary Report recommendation feedback
+// @Description Report the observed reward for a recommendation previously issued with explore=true, identified by its impression_id, so the bandit ranker can learn from it
+// @Tags recommendations
+// @Accept json
+// @Produce json
+// @Param request body models.FeedbackRequest true "Feedback for an impression"
+// @Success 200 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/recommendations/feedback [post]
+// @Security ApiKeyAuth
+func (h *Handler) RecommendationFeedback(c *gin.Context) {
+	var req models.FeedbackRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if !h.Recommender.Feedback(req.ImpressionID, req.Reward) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "unknown or expired impression_id"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Feedback recorded successfully"})
+}
+
+// HealthCheck godoc
+// @Summary Health check
+// @Description Check if the service is running
+// @Tags health
+// @Produce json
+// @Success 200 {object} map[string]string
+// @Router /health [get]
+func (h *Handler) HealthCheck(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"service": "recommendation-engine",
+		"status":  "healthy",
+	})
+}
@@ -10,13 +10,26 @@ type Recommendation struct {
 	Reason      string  `json:"reason"`
 	Title       string  `json:"title,omitempty"`
 	Description string  `json:"description,omitempty"`
+	// ImpressionID is set when the recommendation was ranked by the
+	// explore=true bandit path; pass it back in FeedbackRequest to report
+	// whether the user acted on it.
+	ImpressionID string `json:"impression_id,omitempty"`
 }
 
 // RecommendationRequest represents a request for recommendations
 type RecommendationRequest struct {
-	UserID string `json:"user_id"`
-	Limit  int    `json:"limit"`
-	Type   string `json:"type,omitempty"` // Filter by type
+	UserID  string `json:"user_id"`
+	Limit   int    `json:"limit"`
+	Type    string `json:"type,omitempty"` // Filter by type
+	Explore bool   `json:"explore,omitempty"`
+}
+
+// FeedbackRequest is the body of POST /api/v1/recommendations/feedback: the
+// observed reward for a single recommendation previously issued with
+// explore=true, identified by the ImpressionID it carried.
+type FeedbackRequest struct {
+	ImpressionID string  `json:"impression_id" binding:"required"`
+	Reward       float64 `json:"reward"`
 }
 
 // RecommendationResponse contains the list of recommendations
@@ -39,3 +52,36 @@ type SimilarItemsRequest struct {
 	ItemID string `json:"item_id"`
 	Limit  int    `json:"limit"`
 }
+
+// BatchTrackRequest is the body of POST /api/v1/recommendations/batch-track:
+// a bulk replay of interactions, e.g. to backfill the recommender after an
+// outage or migrate history from another system.
+type BatchTrackRequest struct {
+	Interactions []UserInteraction `json:"interactions" binding:"required"`
+}
+
+// InteractionExplanation is one of the user's own interactions that
+// contributed to a recommended item's score: how similar the interacted
+// item is to the one being explained, how much decayed weight the
+// interaction still carries, and their product (its actual contribution).
+type InteractionExplanation struct {
+	ItemID       string  `json:"item_id"`
+	Weight       float64 `json:"weight"`
+	Similarity   float64 `json:"similarity"`
+	Contribution float64 `json:"contribution"`
+}
+
+// NeighborUser is another user whose own interaction with the explained
+// item contributed to that item's collaborative-filtering signal.
+type NeighborUser struct {
+	UserID string  `json:"user_id"`
+	Weight float64 `json:"weight"`
+}
+
+// ExplainResponse is returned by GET /api/v1/recommendations/explain.
+type ExplainResponse struct {
+	UserID          string                   `json:"user_id"`
+	ItemID          string                   `json:"item_id"`
+	TopInteractions []InteractionExplanation `json:"top_interactions"`
+	NeighborUsers   []NeighborUser           `json:"neighbor_users"`
+}
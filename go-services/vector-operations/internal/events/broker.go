@@ -0,0 +1,32 @@
+package events
+
+import "fmt"
+
+// BrokerConfig selects and configures a Broker implementation, read from
+// the environment by config.Load (see EVENT_BROKER, EVENT_BROKER_URL).
+type BrokerConfig struct {
+	Kind string // "kafka", "nats", or "" / "memory" (default)
+	URL  string // comma-separated broker addresses for kafka, a single server URL for nats
+}
+
+// New builds the Broker cfg.Kind selects, defaulting to an in-memory
+// broker when Kind is unset so the service still runs — with no
+// cross-process event delivery — without an external broker configured.
+func New(cfg BrokerConfig) (Broker, error) {
+	switch cfg.Kind {
+	case "", "memory":
+		return NewMemoryBroker(), nil
+	case "kafka":
+		if cfg.URL == "" {
+			return nil, fmt.Errorf("EVENT_BROKER=kafka requires EVENT_BROKER_URL")
+		}
+		return NewKafkaBroker(cfg.URL), nil
+	case "nats":
+		if cfg.URL == "" {
+			return nil, fmt.Errorf("EVENT_BROKER=nats requires EVENT_BROKER_URL")
+		}
+		return NewNATSBroker(cfg.URL)
+	default:
+		return nil, fmt.Errorf("unknown EVENT_BROKER %q", cfg.Kind)
+	}
+}
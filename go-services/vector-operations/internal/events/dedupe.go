@@ -0,0 +1,57 @@
+package events
+
+import (
+	"sync"
+	"time"
+)
+
+// dedupeWindow bounds how long a delivered key is remembered per consumer
+// group, trading memory for protection against duplicate at-least-once
+// redelivery; a redelivery older than this is processed again rather than
+// suppressed.
+const dedupeWindow = 10 * time.Minute
+
+// deduper tracks recently-delivered idempotency keys per consumer group so
+// a Broker can skip a handler call for a key that group has already
+// successfully processed.
+type deduper struct {
+	mu   sync.Mutex
+	seen map[string]map[string]time.Time // group -> key -> seenAt
+}
+
+func newDeduper() *deduper {
+	return &deduper{seen: make(map[string]map[string]time.Time)}
+}
+
+// seenRecently reports whether key was already marked for group within
+// dedupeWindow, evicting group's stale entries as a side effect.
+func (d *deduper) seenRecently(group, key string) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	keys := d.keysLocked(group)
+	cutoff := time.Now().Add(-dedupeWindow)
+	for k, at := range keys {
+		if at.Before(cutoff) {
+			delete(keys, k)
+		}
+	}
+
+	at, ok := keys[key]
+	return ok && at.After(cutoff)
+}
+
+func (d *deduper) mark(group, key string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.keysLocked(group)[key] = time.Now()
+}
+
+func (d *deduper) keysLocked(group string) map[string]time.Time {
+	keys := d.seen[group]
+	if keys == nil {
+		keys = make(map[string]time.Time)
+		d.seen[group] = keys
+	}
+	return keys
+}
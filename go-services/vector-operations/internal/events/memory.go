@@ -0,0 +1,118 @@
+package events
+
+import (
+	"context"
+	"sync"
+)
+
+// memorySubBuffer bounds how many undelivered events a single subscriber
+// channel holds before Publish starts dropping for it.
+const memorySubBuffer = 256
+
+// MemoryBroker is an in-process Broker: Publish fans out to every
+// Subscribe'd handler over a small buffered channel per (topic,
+// subscriber). It has no cross-process durability, so it's meant for
+// local development and tests, or as the default when no external broker
+// is configured via EVENT_BROKER.
+type MemoryBroker struct {
+	mu      sync.Mutex
+	subs    map[string][]*memorySub // topic -> subscribers
+	dedup   *deduper
+	closed  bool
+	closeCh chan struct{}
+}
+
+type memorySub struct {
+	group   string
+	handler Handler
+	ch      chan Event
+}
+
+func NewMemoryBroker() *MemoryBroker {
+	return &MemoryBroker{
+		subs:    make(map[string][]*memorySub),
+		dedup:   newDeduper(),
+		closeCh: make(chan struct{}),
+	}
+}
+
+func (b *MemoryBroker) Publish(ctx context.Context, topic string, evt Event) error {
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return errClosed
+	}
+	subs := append([]*memorySub(nil), b.subs[topic]...)
+	b.mu.Unlock()
+
+	for _, s := range subs {
+		select {
+		case s.ch <- evt:
+		default:
+			// Slow consumer: drop rather than block the publisher. An
+			// in-memory broker backing a dev/no-broker-configured
+			// deployment shouldn't apply backpressure to request
+			// handling; a real broker (Kafka/NATS) is what production
+			// relies on for durability.
+		}
+	}
+	publishedTotal.WithLabelValues(topic).Inc()
+	return nil
+}
+
+func (b *MemoryBroker) Subscribe(ctx context.Context, topic, group string, handler Handler) error {
+	sub := &memorySub{group: group, handler: handler, ch: make(chan Event, memorySubBuffer)}
+
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return errClosed
+	}
+	b.subs[topic] = append(b.subs[topic], sub)
+	b.mu.Unlock()
+
+	go b.dispatch(ctx, topic, sub)
+	return nil
+}
+
+func (b *MemoryBroker) dispatch(ctx context.Context, topic string, sub *memorySub) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-b.closeCh:
+			return
+		case evt := <-sub.ch:
+			b.deliver(ctx, topic, sub, evt)
+		}
+	}
+}
+
+func (b *MemoryBroker) deliver(ctx context.Context, topic string, sub *memorySub, evt Event) {
+	if evt.Key != "" && b.dedup.seenRecently(sub.group, evt.Key) {
+		return
+	}
+
+	if err := withRetry(ctx, topic, sub.group, evt, sub.handler); err != nil {
+		deadLetteredTotal.WithLabelValues(topic, sub.group).Inc()
+		dlqEvt := evt
+		dlqEvt.Attempt++
+		b.Publish(ctx, DLQTopic(topic), dlqEvt)
+		return
+	}
+
+	if evt.Key != "" {
+		b.dedup.mark(sub.group, evt.Key)
+	}
+}
+
+func (b *MemoryBroker) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return nil
+	}
+	b.closed = true
+	close(b.closeCh)
+	return nil
+}
@@ -0,0 +1,144 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/nats-io/nats.go"
+)
+
+// natsIdempotencyHeader carries Event.Key on the NATS message so it's
+// visible to broker tooling without decoding the JSON body.
+const natsIdempotencyHeader = "Idempotency-Key"
+
+// NATSBroker publishes/subscribes via NATS JetStream durable consumers.
+// Messages are explicitly Ack'd only after their handler succeeds (or the
+// event has been dead-lettered), giving at-least-once delivery.
+type NATSBroker struct {
+	nc *nats.Conn
+	js nats.JetStreamContext
+
+	dedup *deduper
+}
+
+// NewNATSBroker connects to the NATS server at url and opens a JetStream
+// context.
+func NewNATSBroker(url string) (*NATSBroker, error) {
+	nc, err := nats.Connect(url)
+	if err != nil {
+		return nil, fmt.Errorf("connect to NATS: %w", err)
+	}
+	js, err := nc.JetStream()
+	if err != nil {
+		nc.Close()
+		return nil, fmt.Errorf("open JetStream context: %w", err)
+	}
+	return &NATSBroker{nc: nc, js: js, dedup: newDeduper()}, nil
+}
+
+// ensureStream creates topic's backing JetStream stream on first use; a
+// stream that already exists is left as-is.
+func (b *NATSBroker) ensureStream(topic string) error {
+	if _, err := b.js.StreamInfo(natsStreamName(topic)); err == nil {
+		return nil
+	}
+	_, err := b.js.AddStream(&nats.StreamConfig{
+		Name:     natsStreamName(topic),
+		Subjects: []string{natsSubject(topic)},
+	})
+	return err
+}
+
+func (b *NATSBroker) Publish(ctx context.Context, topic string, evt Event) error {
+	if err := b.ensureStream(topic); err != nil {
+		return fmt.Errorf("ensure stream for %s: %w", topic, err)
+	}
+	payload, err := json.Marshal(evt)
+	if err != nil {
+		return fmt.Errorf("marshal event: %w", err)
+	}
+
+	msg := nats.NewMsg(natsSubject(topic))
+	msg.Data = payload
+	if evt.Key != "" {
+		msg.Header.Set(natsIdempotencyHeader, evt.Key)
+	}
+	if _, err := b.js.PublishMsg(msg, nats.Context(ctx)); err != nil {
+		return fmt.Errorf("publish to %s: %w", topic, err)
+	}
+	publishedTotal.WithLabelValues(topic).Inc()
+	return nil
+}
+
+func (b *NATSBroker) Subscribe(ctx context.Context, topic, group string, handler Handler) error {
+	if err := b.ensureStream(topic); err != nil {
+		return fmt.Errorf("ensure stream for %s: %w", topic, err)
+	}
+
+	sub, err := b.js.PullSubscribe(natsSubject(topic), group, nats.ManualAck())
+	if err != nil {
+		return fmt.Errorf("subscribe to %s/%s: %w", topic, group, err)
+	}
+
+	go b.consume(ctx, sub, topic, group, handler)
+	return nil
+}
+
+func (b *NATSBroker) consume(ctx context.Context, sub *nats.Subscription, topic, group string, handler Handler) {
+	defer sub.Unsubscribe()
+
+	for ctx.Err() == nil {
+		msgs, err := sub.Fetch(1, nats.MaxWait(5*time.Second))
+		if err != nil {
+			if err != nats.ErrTimeout && ctx.Err() == nil {
+				log.Printf("events: NATS fetch from %s/%s failed: %v", topic, group, err)
+			}
+			continue
+		}
+		for _, msg := range msgs {
+			b.handle(ctx, msg, topic, group, handler)
+		}
+	}
+}
+
+func (b *NATSBroker) handle(ctx context.Context, msg *nats.Msg, topic, group string, handler Handler) {
+	defer msg.Ack()
+
+	if meta, err := msg.Metadata(); err == nil {
+		consumerLag.WithLabelValues(topic, group).Set(float64(meta.NumPending))
+	}
+
+	var evt Event
+	if err := json.Unmarshal(msg.Data, &evt); err != nil {
+		log.Printf("events: failed to decode NATS message from %s: %v", topic, err)
+		return
+	}
+	if evt.Key != "" && b.dedup.seenRecently(group, evt.Key) {
+		return
+	}
+
+	if err := withRetry(ctx, topic, group, evt, handler); err != nil {
+		deadLetteredTotal.WithLabelValues(topic, group).Inc()
+		dlqEvt := evt
+		dlqEvt.Attempt++
+		if perr := b.Publish(ctx, DLQTopic(topic), dlqEvt); perr != nil {
+			log.Printf("events: failed to dead-letter NATS event from %s: %v", topic, perr)
+		}
+		return
+	}
+	if evt.Key != "" {
+		b.dedup.mark(group, evt.Key)
+	}
+}
+
+func (b *NATSBroker) Close() error {
+	b.nc.Close()
+	return nil
+}
+
+func natsSubject(topic string) string    { return "events." + topic }
+func natsStreamName(topic string) string { return strings.ReplaceAll(topic, ".", "_") }
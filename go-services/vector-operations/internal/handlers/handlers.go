@@ -1,10 +1,17 @@
 package handlers
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
+	"log"
 	"net/http"
+	"os"
+	"strconv"
+	"sync"
 	"time"
+	"vector-operations/internal/events"
+	"vector-operations/internal/index"
 	"vector-operations/internal/models"
 	"vector-operations/internal/storage"
 
@@ -13,10 +20,166 @@ import (
 
 type Handler struct {
 	DB *sql.DB
+
+	index     index.Index
+	indexPath string
+
+	// namedMu/namedIndexes back the secondary embedding spaces named in
+	// models.Vector.Embeddings (e.g. "w_matrix", "reasoning_chain"), each
+	// its own sharded HNSW graph created lazily on first use.
+	namedMu      sync.Mutex
+	namedIndexes map[string]index.Index
+
+	bm25 *index.BM25
+
+	vecMu sync.RWMutex
+	vecs  map[string]models.Vector
+
+	events events.Broker
 }
 
 func NewHandler(db *sql.DB) *Handler {
-	return &Handler{DB: db}
+	broker, err := events.New(events.BrokerConfig{
+		Kind: os.Getenv("EVENT_BROKER"),
+		URL:  os.Getenv("EVENT_BROKER_URL"),
+	})
+	if err != nil {
+		log.Printf("failed to init event broker, falling back to in-memory: %v", err)
+		broker = events.NewMemoryBroker()
+	}
+
+	h := &Handler{
+		DB:           db,
+		index:        index.NewSharded(indexConfigFromEnv()),
+		indexPath:    envOr("HNSW_INDEX_PATH", "vectors.hnsw"),
+		namedIndexes: make(map[string]index.Index),
+		bm25:         index.NewBM25(),
+		vecs:         make(map[string]models.Vector),
+		events:       broker,
+	}
+
+	if f, err := os.Open(h.indexPath); err == nil {
+		defer f.Close()
+		if err := h.index.Load(f); err != nil {
+			log.Printf("failed to load persisted HNSW index from %s: %v", h.indexPath, err)
+		} else {
+			log.Printf("loaded HNSW index (%d vectors) from %s", h.index.Len(), h.indexPath)
+		}
+	}
+
+	h.rebuildFromDB()
+
+	return h
+}
+
+// publishVectorStored is best-effort: a broker outage shouldn't fail a
+// store request whose vector is already indexed.
+func (h *Handler) publishVectorStored(vec models.Vector) {
+	evt, err := events.New(events.TypeVectorStored, "vector:"+vec.ID, events.VectorStoredPayload{
+		VectorID:  vec.ID,
+		Dimension: vec.Dimension,
+		CreatedAt: vec.CreatedAt,
+	})
+	if err != nil {
+		log.Printf("failed to build VectorStored event for %s: %v", vec.ID, err)
+		return
+	}
+	if err := h.events.Publish(context.Background(), events.TopicVectorStored, evt); err != nil {
+		log.Printf("failed to publish VectorStored event for %s: %v", vec.ID, err)
+	}
+}
+
+// indexConfigFromEnv lets operators tune the graph without a rebuild-on-deploy.
+func indexConfigFromEnv() index.Config {
+	cfg := index.DefaultConfig()
+	if v, err := strconv.Atoi(os.Getenv("HNSW_M")); err == nil && v > 0 {
+		cfg.M = v
+	}
+	if v, err := strconv.Atoi(os.Getenv("HNSW_EF_CONSTRUCTION")); err == nil && v > 0 {
+		cfg.EfConstruction = v
+	}
+	if v, err := strconv.Atoi(os.Getenv("HNSW_EF_SEARCH")); err == nil && v > 0 {
+		cfg.EfSearch = v
+	}
+	return cfg
+}
+
+func envOr(key, fallback string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return fallback
+}
+
+// rebuildFromDB seeds the index and the in-memory vector cache from
+// persisted vectors so a fresh process without a saved graph still serves
+// accurate results.
+func (h *Handler) rebuildFromDB() {
+	if h.DB == nil {
+		return
+	}
+
+	rows, err := h.DB.Query(`SELECT id, name, description, embedding, dimension, creator_id, created_at FROM vectors`)
+	if err != nil {
+		log.Printf("failed to rebuild HNSW index from database: %v", err)
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var vec models.Vector
+		var embeddingJSON string
+		if err := rows.Scan(&vec.ID, &vec.Name, &vec.Description, &embeddingJSON, &vec.Dimension, &vec.CreatorID, &vec.CreatedAt); err != nil {
+			continue
+		}
+		if err := json.Unmarshal([]byte(embeddingJSON), &vec.Embedding); err != nil {
+			continue
+		}
+		h.indexVector(vec)
+	}
+}
+
+// indexVector inserts vec into the primary ANN index, every named secondary
+// embedding space it carries, the BM25 keyword index, and the metadata
+// cache used to hydrate search results back into full Vector records.
+func (h *Handler) indexVector(vec models.Vector) {
+	h.vecMu.Lock()
+	h.vecs[vec.ID] = vec
+	h.vecMu.Unlock()
+
+	if err := h.index.Insert(vec.ID, vec.Embedding); err != nil {
+		log.Printf("failed to insert vector %s into HNSW index: %v", vec.ID, err)
+	}
+	for name, emb := range vec.Embeddings {
+		if err := h.namedIndexFor(name).Insert(vec.ID, emb); err != nil {
+			log.Printf("failed to insert vector %s into %q HNSW index: %v", vec.ID, name, err)
+		}
+	}
+	h.bm25.Index(vec.ID, vec.Name+" "+vec.Description)
+}
+
+// namedIndexFor returns the sharded HNSW index backing the secondary
+// embedding space name, creating it on first use.
+func (h *Handler) namedIndexFor(name string) index.Index {
+	h.namedMu.Lock()
+	defer h.namedMu.Unlock()
+	idx, ok := h.namedIndexes[name]
+	if !ok {
+		idx = index.NewSharded(indexConfigFromEnv())
+		h.namedIndexes[name] = idx
+	}
+	return idx
+}
+
+// Close persists the HNSW graph to disk so a restart doesn't require a full
+// rebuild. Call from the service's shutdown path.
+func (h *Handler) Close() error {
+	f, err := os.Create(h.indexPath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return h.index.Save(f)
 }
 
 // GetVectorStats godoc
@@ -60,7 +223,7 @@ func (h *Handler) GetVectorStats(c *gin.Context) {
 
 // SearchVectors godoc
 // @Summary Search for similar vectors
-// @Description Find vectors similar to the query vector using cosine similarity
+// @Description Find vectors similar to the query vector using an HNSW approximate nearest-neighbor index, overridable per-request via ef and exact
 // @Tags vectors
 // @Accept json
 // @Produce json
@@ -109,7 +272,13 @@ func (h *Handler) SearchVectors(c *gin.Context) {
 		return
 	}
 
-	// Query vectors from database
+	if !req.Exact && h.index.Len() > 0 {
+		c.JSON(http.StatusOK, h.searchIndex(req.QueryVector, req.TopK, req.Threshold, req.Ef))
+		return
+	}
+
+	// Fall back to a full scan, either because req.Exact asked for the
+	// brute-force baseline or the index has nothing indexed yet.
 	rows, err := h.DB.Query(`
 		SELECT id, name, description, embedding, dimension, creator_id, created_at
 		FROM vectors
@@ -143,6 +312,177 @@ func (h *Handler) SearchVectors(c *gin.Context) {
 	c.JSON(http.StatusOK, results)
 }
 
+// HybridSearch godoc
+// @Summary Hybrid vector + keyword search
+// @Description Runs dense KNN search over one or more named vector spaces (the primary embedding plus, e.g., w_matrix or reasoning_chain) and a BM25 keyword search over name+description in parallel, then fuses the ranked lists with Reciprocal Rank Fusion (k=60)
+// @Tags vectors
+// @Accept json
+// @Produce json
+// @Param request body models.HybridSearchRequest true "Hybrid search request"
+// @Success 200 {array} models.HybridSearchResult
+// @Failure 400 {object} map[string]string
+// @Router /api/v1/vectors/hybrid-search [post]
+func (h *Handler) HybridSearch(c *gin.Context) {
+	var req models.HybridSearchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.Threshold == 0 {
+		req.Threshold = 0.7
+	}
+
+	type namedList struct {
+		source string
+		list   index.RankedList
+	}
+
+	resultsCh := make(chan namedList, len(req.TargetVectors)+1)
+	var wg sync.WaitGroup
+
+	for _, target := range req.TargetVectors {
+		target := target
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			source := target.Name
+			idx := h.index
+			if source == "" {
+				source = "embedding"
+			} else {
+				idx = h.namedIndexFor(source)
+			}
+
+			hits := idx.Search(target.QueryVector, req.TopK, req.Threshold)
+			ids := make([]string, len(hits))
+			for i, hit := range hits {
+				ids[i] = hit.ID
+			}
+			resultsCh <- namedList{source: source, list: index.RankedList{IDs: ids, Weight: target.Weight}}
+		}()
+	}
+
+	if req.TextQuery != "" {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			hits := h.bm25.Search(req.TextQuery, req.TopK)
+			ids := make([]string, len(hits))
+			for i, hit := range hits {
+				ids[i] = hit.ID
+			}
+			resultsCh <- namedList{source: "bm25", list: index.RankedList{IDs: ids, Weight: req.TextWeight}}
+		}()
+	}
+
+	wg.Wait()
+	close(resultsCh)
+
+	sources := make(map[string][]string) // vector ID -> which sources ranked it
+	lists := make([]index.RankedList, 0, len(req.TargetVectors)+1)
+	for nl := range resultsCh {
+		lists = append(lists, nl.list)
+		for _, id := range nl.list.IDs {
+			sources[id] = append(sources[id], nl.source)
+		}
+	}
+
+	fused := index.ReciprocalRankFusion(lists)
+	if len(fused) > req.TopK {
+		fused = fused[:req.TopK]
+	}
+
+	h.vecMu.RLock()
+	defer h.vecMu.RUnlock()
+
+	results := make([]models.HybridSearchResult, 0, len(fused))
+	for _, f := range fused {
+		vec, ok := h.vecs[f.ID]
+		if !ok {
+			continue
+		}
+		results = append(results, models.HybridSearchResult{
+			Vector:  vec,
+			Score:   f.Score,
+			Sources: sources[f.ID],
+		})
+	}
+	c.JSON(http.StatusOK, results)
+}
+
+// GetSimilarByID godoc
+// @Summary Find vectors similar to an already-stored vector
+// @Description Looks up the stored embedding for :id and returns its nearest neighbors, so callers (e.g. marketplace browse endpoints with a similar_to param) don't need to fetch the embedding themselves first
+// @Tags vectors
+// @Produce json
+// @Param id path string true "Vector ID"
+// @Param top_k query int false "Number of results" default(10)
+// @Param threshold query number false "Minimum similarity" default(0.7)
+// @Success 200 {array} models.VectorSearchResult
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/vectors/{id}/similar [get]
+func (h *Handler) GetSimilarByID(c *gin.Context) {
+	id := c.Param("id")
+
+	h.vecMu.RLock()
+	vec, ok := h.vecs[id]
+	h.vecMu.RUnlock()
+
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "vector not found: " + id})
+		return
+	}
+
+	topK := 10
+	if v, err := strconv.Atoi(c.Query("top_k")); err == nil && v > 0 {
+		topK = v
+	}
+	threshold := 0.7
+	if v, err := strconv.ParseFloat(c.Query("threshold"), 64); err == nil {
+		threshold = v
+	}
+
+	results := h.searchIndex(vec.Embedding, topK+1, threshold, 0)
+
+	filtered := make([]models.VectorSearchResult, 0, len(results))
+	for _, r := range results {
+		if r.Vector.ID == id {
+			continue
+		}
+		filtered = append(filtered, r)
+	}
+	if len(filtered) > topK {
+		filtered = filtered[:topK]
+	}
+
+	c.JSON(http.StatusOK, filtered)
+}
+
+// searchIndex queries the HNSW index and hydrates the returned IDs back
+// into full VectorSearchResult records from the metadata cache. ef<=0 uses
+// the index's configured beam width.
+func (h *Handler) searchIndex(query []float64, topK int, threshold float64, ef int) []models.VectorSearchResult {
+	hits := h.index.SearchEf(query, topK, threshold, ef)
+
+	h.vecMu.RLock()
+	defer h.vecMu.RUnlock()
+
+	results := make([]models.VectorSearchResult, 0, len(hits))
+	for _, hit := range hits {
+		vec, ok := h.vecs[hit.ID]
+		if !ok {
+			continue
+		}
+		results = append(results, models.VectorSearchResult{
+			Vector:     vec,
+			Similarity: hit.Similarity,
+			Distance:   hit.Distance,
+		})
+	}
+	return results
+}
+
 // StoreVector godoc
 // @Summary Store a new vector
 // @Description Store a latent space vector
@@ -192,6 +532,9 @@ func (h *Handler) StoreVector(c *gin.Context) {
 	id, _ := result.LastInsertId()
 	vec.ID = string(rune(id))
 
+	h.indexVector(vec)
+	h.publishVectorStored(vec)
+
 	c.JSON(http.StatusCreated, vec)
 }
 
@@ -239,6 +582,8 @@ func (h *Handler) BatchStoreVectors(c *gin.Context) {
 			}
 		}
 
+		h.indexVector(vec)
+		h.publishVectorStored(vec)
 		stored++
 	}
 
@@ -0,0 +1,166 @@
+package index
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// ScoredDoc is a single hit from BM25, ranked by keyword relevance rather
+// than vector distance.
+type ScoredDoc struct {
+	ID    string
+	Score float64
+}
+
+// BM25 is a small in-memory inverted-index keyword search, used as the
+// sparse half of hybrid search alongside the dense HNSW graph. It indexes
+// whatever text callers give it (here, each vector's Name+Description) and
+// scores queries with Okapi BM25.
+type BM25 struct {
+	k1, b float64
+
+	mu       sync.RWMutex
+	postings map[string]map[string]int // term -> docID -> term frequency
+	docLen   map[string]int
+	totalLen int
+}
+
+// NewBM25 builds an empty BM25 index with the standard k1=1.2, b=0.75 defaults.
+func NewBM25() *BM25 {
+	return &BM25{
+		k1:       1.2,
+		b:        0.75,
+		postings: make(map[string]map[string]int),
+		docLen:   make(map[string]int),
+	}
+}
+
+// Index adds or replaces the document id's text. Calling it again for an
+// id that's already indexed removes the old postings first.
+func (b *BM25) Index(id, text string) {
+	terms := tokenize(text)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.remove(id)
+
+	termFreq := make(map[string]int, len(terms))
+	for _, t := range terms {
+		termFreq[t]++
+	}
+	for t, tf := range termFreq {
+		posting, ok := b.postings[t]
+		if !ok {
+			posting = make(map[string]int)
+			b.postings[t] = posting
+		}
+		posting[id] = tf
+	}
+	b.docLen[id] = len(terms)
+	b.totalLen += len(terms)
+}
+
+// Remove deletes id from the index.
+func (b *BM25) Remove(id string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.remove(id)
+}
+
+func (b *BM25) remove(id string) {
+	if oldLen, ok := b.docLen[id]; ok {
+		b.totalLen -= oldLen
+		delete(b.docLen, id)
+	}
+	for _, posting := range b.postings {
+		delete(posting, id)
+	}
+}
+
+// Search scores every document containing at least one query term and
+// returns the topK, highest score first.
+func (b *BM25) Search(query string, topK int) []ScoredDoc {
+	queryTerms := tokenize(query)
+	if len(queryTerms) == 0 {
+		return nil
+	}
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	corpusSize := len(b.docLen)
+	if corpusSize == 0 {
+		return nil
+	}
+	avgDocLen := float64(b.totalLen) / float64(corpusSize)
+
+	scores := make(map[string]float64)
+	for _, term := range dedupe(queryTerms) {
+		posting, ok := b.postings[term]
+		if !ok {
+			continue
+		}
+		idf := bm25IDF(corpusSize, len(posting))
+		for id, tf := range posting {
+			docLen := float64(b.docLen[id])
+			numerator := float64(tf) * (b.k1 + 1)
+			denominator := float64(tf) + b.k1*(1-b.b+b.b*docLen/avgDocLen)
+			scores[id] += idf * (numerator / denominator)
+		}
+	}
+
+	results := make([]ScoredDoc, 0, len(scores))
+	for id, score := range scores {
+		results = append(results, ScoredDoc{ID: id, Score: score})
+	}
+	sort.Slice(results, func(i, j int) bool { return results[i].Score > results[j].Score })
+	if len(results) > topK {
+		results = results[:topK]
+	}
+	return results
+}
+
+func dedupe(terms []string) []string {
+	seen := make(map[string]bool, len(terms))
+	out := make([]string, 0, len(terms))
+	for _, t := range terms {
+		if !seen[t] {
+			seen[t] = true
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
+// tokenize lowercases text and splits it into words of letters/digits,
+// dropping anything 2 characters or shorter as too common to be useful.
+func tokenize(text string) []string {
+	var words []string
+	var cur strings.Builder
+	flush := func() {
+		if cur.Len() > 2 {
+			words = append(words, strings.ToLower(cur.String()))
+		}
+		cur.Reset()
+	}
+	for _, r := range text {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			cur.WriteRune(r)
+		} else {
+			flush()
+		}
+	}
+	flush()
+	return words
+}
+
+func bm25IDF(corpusSize, df int) float64 {
+	if corpusSize == 0 {
+		return 1
+	}
+	return math.Log((float64(corpusSize-df)+0.5)/(float64(df)+0.5) + 1)
+}
@@ -0,0 +1,39 @@
+package index
+
+import "sort"
+
+// rrfK is the rank-offset constant from the original Reciprocal Rank Fusion
+// paper (Cormack et al.), chosen so that a handful of low-ranked hits don't
+// swamp a single top-ranked one.
+const rrfK = 60
+
+// RankedList is one source's ranked results, most relevant first, with a
+// weight controlling how much it contributes to the fused score.
+type RankedList struct {
+	IDs    []string
+	Weight float64 // defaults to 1 if <= 0
+}
+
+// ReciprocalRankFusion merges any number of ranked ID lists into a single
+// ranking via score(d) = Σ_r weight_r * 1/(k + rank_r(d)), descending. Lists
+// contribute nothing for IDs they don't contain, so a document only needs to
+// appear in one source's results to be fused in.
+func ReciprocalRankFusion(lists []RankedList) []ScoredDoc {
+	scores := make(map[string]float64)
+	for _, list := range lists {
+		weight := list.Weight
+		if weight <= 0 {
+			weight = 1
+		}
+		for rank, id := range list.IDs {
+			scores[id] += weight / float64(rrfK+rank+1)
+		}
+	}
+
+	fused := make([]ScoredDoc, 0, len(scores))
+	for id, score := range scores {
+		fused = append(fused, ScoredDoc{ID: id, Score: score})
+	}
+	sort.Slice(fused, func(i, j int) bool { return fused[i].Score > fused[j].Score })
+	return fused
+}
@@ -0,0 +1,567 @@
+// Package index provides an approximate nearest-neighbor index for vector
+// search, so the vectors service doesn't have to fall back to a linear scan
+// once the corpus grows past a few tens of thousands of embeddings.
+package index
+
+import (
+	"bufio"
+	"encoding/gob"
+	"io"
+	"math"
+	"math/rand"
+	"sort"
+	"sync"
+)
+
+// Metric selects the distance function used to build and query the graph.
+type Metric int
+
+const (
+	Cosine Metric = iota
+	L2
+)
+
+// Config controls the HNSW graph shape and search quality/speed tradeoff.
+type Config struct {
+	M              int // max neighbors per node above layer 0
+	EfConstruction int // beam width used while inserting
+	EfSearch       int // beam width used while searching
+	Metric         Metric
+}
+
+// DefaultConfig returns reasonable defaults for marketplace-sized corpora.
+func DefaultConfig() Config {
+	return Config{
+		M:              16,
+		EfConstruction: 200,
+		EfSearch:       64,
+		Metric:         Cosine,
+	}
+}
+
+// Result is a single neighbor returned from a search.
+type Result struct {
+	ID         string
+	Similarity float64
+	Distance   float64
+}
+
+// Index is the interface the vectors handlers depend on, so the in-memory
+// HNSW graph here can later be swapped for an external vector database.
+type Index interface {
+	Insert(id string, vector []float64) error
+	Remove(id string) bool
+	Search(query []float64, topK int, threshold float64) []Result
+	// SearchEf is Search with the beam width overridden to ef (<=0 uses the
+	// index's configured EfSearch), so a single query can trade recall for
+	// latency without reconfiguring the whole index.
+	SearchEf(query []float64, topK int, threshold float64, ef int) []Result
+	Len() int
+	Save(w io.Writer) error
+	Load(r io.Reader) error
+}
+
+type node struct {
+	id        string
+	vector    []float64
+	norm      float64
+	level     int
+	neighbors [][]string // neighbors[layer] = neighbor IDs, replaced wholesale on update
+}
+
+// HNSW is an in-process Hierarchical Navigable Small World graph.
+type HNSW struct {
+	cfg Config
+
+	mu         sync.RWMutex
+	nodes      map[string]*node
+	entryPoint string
+	maxLevel   int
+	rng        *rand.Rand
+}
+
+// New creates an empty HNSW index using cfg (zero-value fields fall back to
+// DefaultConfig's values).
+func New(cfg Config) *HNSW {
+	d := DefaultConfig()
+	if cfg.M <= 0 {
+		cfg.M = d.M
+	}
+	if cfg.EfConstruction <= 0 {
+		cfg.EfConstruction = d.EfConstruction
+	}
+	if cfg.EfSearch <= 0 {
+		cfg.EfSearch = d.EfSearch
+	}
+	return &HNSW{
+		cfg:   cfg,
+		nodes: make(map[string]*node),
+		rng:   rand.New(rand.NewSource(42)),
+	}
+}
+
+func (h *HNSW) mMax(layer int) int {
+	if layer == 0 {
+		return h.cfg.M * 2
+	}
+	return h.cfg.M
+}
+
+func (h *HNSW) distance(a, b []float64) float64 {
+	switch h.cfg.Metric {
+	case L2:
+		return euclidean(a, b)
+	default:
+		return 1 - cosine(a, b)
+	}
+}
+
+func cosine(a, b []float64) float64 {
+	if len(a) != len(b) {
+		return 0
+	}
+	var dot, na, nb float64
+	for i := range a {
+		dot += a[i] * b[i]
+		na += a[i] * a[i]
+		nb += b[i] * b[i]
+	}
+	if na == 0 || nb == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(na) * math.Sqrt(nb))
+}
+
+func euclidean(a, b []float64) float64 {
+	if len(a) != len(b) {
+		return math.MaxFloat64
+	}
+	var sum float64
+	for i := range a {
+		d := a[i] - b[i]
+		sum += d * d
+	}
+	return math.Sqrt(sum)
+}
+
+// randomLevel draws a level from an exponential distribution with
+// parameter 1/ln(M), the standard HNSW level assignment.
+func (h *HNSW) randomLevel() int {
+	mL := 1.0 / math.Log(float64(h.cfg.M))
+	h.mu.Lock()
+	u := h.rng.Float64()
+	h.mu.Unlock()
+	if u <= 0 {
+		u = 1e-12
+	}
+	return int(math.Floor(-math.Log(u) * mL))
+}
+
+type candidate struct {
+	id   string
+	dist float64
+}
+
+// Insert adds or replaces a vector in the index.
+func (h *HNSW) Insert(id string, vector []float64) error {
+	h.mu.Lock()
+	if _, exists := h.nodes[id]; exists {
+		delete(h.nodes, id)
+	}
+	level := h.randomLevel()
+	n := &node{
+		id:        id,
+		vector:    vector,
+		level:     level,
+		neighbors: make([][]string, level+1),
+	}
+	h.nodes[id] = n
+
+	if h.entryPoint == "" {
+		h.entryPoint = id
+		h.maxLevel = level
+		h.mu.Unlock()
+		return nil
+	}
+	entryPoint := h.entryPoint
+	maxLevel := h.maxLevel
+	h.mu.Unlock()
+
+	h.mu.RLock()
+	entryNode, ok := h.nodes[entryPoint]
+	h.mu.RUnlock()
+	if !ok {
+		// entryPoint was concurrently removed between releasing the lock
+		// above and this read; fall back to making the new node the entry
+		// point, same as the first insert into an empty index.
+		h.mu.Lock()
+		h.maxLevel = level
+		h.entryPoint = id
+		h.mu.Unlock()
+		return nil
+	}
+
+	cur := entryPoint
+	curDist := h.distance(vector, entryNode.vector)
+
+	// Greedy descent from the top layer down to level+1, keeping only the
+	// single best candidate found at each layer as the entry to the next.
+	for layer := maxLevel; layer > level; layer-- {
+		cur, curDist = h.greedySearchLayer(vector, cur, curDist, layer)
+	}
+
+	// From level down to 0, beam search for efConstruction candidates,
+	// select M neighbors with the heuristic selector, and link bidirectionally.
+	for layer := min(level, maxLevel); layer >= 0; layer-- {
+		candidates := h.searchLayer(vector, cur, h.cfg.EfConstruction, layer)
+		selected := h.selectNeighborsHeuristic(vector, candidates, h.mMax(layer))
+
+		h.mu.Lock()
+		n.neighbors[layer] = selected
+		for _, nb := range selected {
+			nbNode, ok := h.nodes[nb]
+			if !ok {
+				continue
+			}
+			nbNeighbors := append(append([]string{}, nbNode.neighbors[layer]...), id)
+			if len(nbNeighbors) > h.mMax(layer) {
+				nbCandidates := make([]candidate, 0, len(nbNeighbors))
+				for _, c := range nbNeighbors {
+					if cn, ok := h.nodes[c]; ok {
+						nbCandidates = append(nbCandidates, candidate{c, h.distance(nbNode.vector, cn.vector)})
+					}
+				}
+				nbNeighbors = h.selectNeighborsHeuristic(nbNode.vector, nbCandidates, h.mMax(layer))
+			}
+			nbNode.neighbors[layer] = nbNeighbors
+		}
+		h.mu.Unlock()
+
+		if len(candidates) > 0 {
+			cur = candidates[0].id
+		}
+	}
+
+	h.mu.Lock()
+	if level > h.maxLevel {
+		h.maxLevel = level
+		h.entryPoint = id
+	}
+	h.mu.Unlock()
+
+	return nil
+}
+
+// greedySearchLayer walks toward the nearest node to query within a single
+// layer, starting from (fromID, fromDist).
+func (h *HNSW) greedySearchLayer(query []float64, fromID string, fromDist float64, layer int) (string, float64) {
+	improved := true
+	cur, curDist := fromID, fromDist
+	for improved {
+		improved = false
+		h.mu.RLock()
+		n, ok := h.nodes[cur]
+		h.mu.RUnlock()
+		if !ok || layer >= len(n.neighbors) {
+			break
+		}
+		for _, nb := range n.neighbors[layer] {
+			h.mu.RLock()
+			nbNode, ok := h.nodes[nb]
+			h.mu.RUnlock()
+			if !ok {
+				continue
+			}
+			d := h.distance(query, nbNode.vector)
+			if d < curDist {
+				cur, curDist = nb, d
+				improved = true
+			}
+		}
+	}
+	return cur, curDist
+}
+
+// searchLayer runs a beam search of the given width within a single layer,
+// returning candidates sorted by ascending distance.
+func (h *HNSW) searchLayer(query []float64, entry string, ef int, layer int) []candidate {
+	h.mu.RLock()
+	entryNode, ok := h.nodes[entry]
+	h.mu.RUnlock()
+	if !ok {
+		return nil
+	}
+
+	visited := map[string]bool{entry: true}
+	entryDist := h.distance(query, entryNode.vector)
+	candidates := []candidate{{entry, entryDist}}
+	best := []candidate{{entry, entryDist}}
+
+	for len(candidates) > 0 {
+		sort.Slice(candidates, func(i, j int) bool { return candidates[i].dist < candidates[j].dist })
+		c := candidates[0]
+		candidates = candidates[1:]
+
+		sort.Slice(best, func(i, j int) bool { return best[i].dist < best[j].dist })
+		if len(best) >= ef && c.dist > best[len(best)-1].dist {
+			break
+		}
+
+		h.mu.RLock()
+		cNode, ok := h.nodes[c.id]
+		h.mu.RUnlock()
+		if !ok || layer >= len(cNode.neighbors) {
+			continue
+		}
+
+		for _, nb := range cNode.neighbors[layer] {
+			if visited[nb] {
+				continue
+			}
+			visited[nb] = true
+
+			h.mu.RLock()
+			nbNode, ok := h.nodes[nb]
+			h.mu.RUnlock()
+			if !ok {
+				continue
+			}
+
+			d := h.distance(query, nbNode.vector)
+			sort.Slice(best, func(i, j int) bool { return best[i].dist < best[j].dist })
+			if len(best) < ef || d < best[len(best)-1].dist {
+				candidates = append(candidates, candidate{nb, d})
+				best = append(best, candidate{nb, d})
+				if len(best) > ef {
+					sort.Slice(best, func(i, j int) bool { return best[i].dist < best[j].dist })
+					best = best[:ef]
+				}
+			}
+		}
+	}
+
+	sort.Slice(best, func(i, j int) bool { return best[i].dist < best[j].dist })
+	return best
+}
+
+// selectNeighborsHeuristic keeps a candidate only if it is closer to the
+// query than it is to every already-selected neighbor, which diversifies
+// the resulting connections instead of just taking the M closest points.
+func (h *HNSW) selectNeighborsHeuristic(query []float64, candidates []candidate, m int) []string {
+	sort.Slice(candidates, func(i, j int) bool { return candidates[i].dist < candidates[j].dist })
+
+	selected := make([]candidate, 0, m)
+	for _, c := range candidates {
+		if len(selected) >= m {
+			break
+		}
+		cNode, ok := h.nodes[c.id]
+		if !ok {
+			continue
+		}
+		good := true
+		for _, s := range selected {
+			sNode, ok := h.nodes[s.id]
+			if !ok {
+				continue
+			}
+			if h.distance(cNode.vector, sNode.vector) < c.dist {
+				good = false
+				break
+			}
+		}
+		if good {
+			selected = append(selected, c)
+		}
+	}
+
+	ids := make([]string, len(selected))
+	for i, s := range selected {
+		ids[i] = s.id
+	}
+	return ids
+}
+
+// Remove deletes a vector from the index. Neighbor lists referencing it are
+// left to self-heal on next Insert's pruning pass.
+func (h *HNSW) Remove(id string) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if _, ok := h.nodes[id]; !ok {
+		return false
+	}
+	delete(h.nodes, id)
+	if h.entryPoint == id {
+		h.entryPoint = ""
+		h.maxLevel = 0
+		for otherID, n := range h.nodes {
+			h.entryPoint = otherID
+			h.maxLevel = n.level
+			break
+		}
+	}
+	return true
+}
+
+// Search finds the topK nearest neighbors to query with similarity >=
+// threshold, using the index's configured EfSearch beam width.
+func (h *HNSW) Search(query []float64, topK int, threshold float64) []Result {
+	return h.SearchEf(query, topK, threshold, h.cfg.EfSearch)
+}
+
+// SearchEf is Search with the beam width overridden to ef (<=0 falls back
+// to the index's configured EfSearch).
+func (h *HNSW) SearchEf(query []float64, topK int, threshold float64, ef int) []Result {
+	if ef <= 0 {
+		ef = h.cfg.EfSearch
+	}
+
+	h.mu.RLock()
+	entryPoint := h.entryPoint
+	maxLevel := h.maxLevel
+	small := len(h.nodes) < 1000
+	h.mu.RUnlock()
+
+	if entryPoint == "" {
+		return nil
+	}
+
+	// Exact fallback keeps recall perfect for small corpora where the graph
+	// overhead isn't worth it.
+	if small {
+		return h.bruteForce(query, topK, threshold)
+	}
+
+	h.mu.RLock()
+	entryNode := h.nodes[entryPoint]
+	h.mu.RUnlock()
+	cur, curDist := entryPoint, h.distance(query, entryNode.vector)
+
+	for layer := maxLevel; layer > 0; layer-- {
+		cur, curDist = h.greedySearchLayer(query, cur, curDist, layer)
+	}
+
+	candidates := h.searchLayer(query, cur, ef, 0)
+	if len(candidates) > topK {
+		candidates = candidates[:topK]
+	}
+
+	results := make([]Result, 0, len(candidates))
+	for _, c := range candidates {
+		similarity := 1 - c.dist
+		if h.cfg.Metric == L2 {
+			similarity = 1 / (1 + c.dist)
+		}
+		if similarity < threshold {
+			continue
+		}
+		results = append(results, Result{ID: c.id, Similarity: similarity, Distance: c.dist})
+	}
+	return results
+}
+
+func (h *HNSW) bruteForce(query []float64, topK int, threshold float64) []Result {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	results := make([]Result, 0, len(h.nodes))
+	for id, n := range h.nodes {
+		d := h.distance(query, n.vector)
+		similarity := 1 - d
+		if h.cfg.Metric == L2 {
+			similarity = 1 / (1 + d)
+		}
+		if similarity < threshold {
+			continue
+		}
+		results = append(results, Result{ID: id, Similarity: similarity, Distance: d})
+	}
+	sort.Slice(results, func(i, j int) bool { return results[i].Similarity > results[j].Similarity })
+	if len(results) > topK {
+		results = results[:topK]
+	}
+	return results
+}
+
+// Len returns the number of vectors currently indexed.
+func (h *HNSW) Len() int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.nodes)
+}
+
+// persistedNode/persistedGraph are the on-disk representations used by
+// Save/Load so the graph survives a restart without a full rebuild.
+type persistedNode struct {
+	ID        string
+	Vector    []float64
+	Level     int
+	Neighbors [][]string
+}
+
+type persistedGraph struct {
+	Config     Config
+	EntryPoint string
+	MaxLevel   int
+	Nodes      []persistedNode
+}
+
+// Save serializes the graph (levels, neighbor lists, entry point) so Load
+// can restore it without replaying every insert.
+func (h *HNSW) Save(w io.Writer) error {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	g := persistedGraph{
+		Config:     h.cfg,
+		EntryPoint: h.entryPoint,
+		MaxLevel:   h.maxLevel,
+		Nodes:      make([]persistedNode, 0, len(h.nodes)),
+	}
+	for _, n := range h.nodes {
+		g.Nodes = append(g.Nodes, persistedNode{
+			ID:        n.id,
+			Vector:    n.vector,
+			Level:     n.level,
+			Neighbors: n.neighbors,
+		})
+	}
+
+	bw := bufio.NewWriter(w)
+	if err := gob.NewEncoder(bw).Encode(g); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+// Load restores a graph previously written by Save.
+func (h *HNSW) Load(r io.Reader) error {
+	var g persistedGraph
+	if err := gob.NewDecoder(bufio.NewReader(r)).Decode(&g); err != nil {
+		return err
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.cfg = g.Config
+	h.entryPoint = g.EntryPoint
+	h.maxLevel = g.MaxLevel
+	h.nodes = make(map[string]*node, len(g.Nodes))
+	for _, pn := range g.Nodes {
+		h.nodes[pn.ID] = &node{
+			id:        pn.ID,
+			vector:    pn.Vector,
+			level:     pn.Level,
+			neighbors: pn.Neighbors,
+		}
+	}
+	return nil
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,100 @@
+package index
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+)
+
+// benchCorpusSize is kept above the small-corpus threshold in SearchEf
+// (currently 1000) so these benchmarks actually exercise the graph search
+// path instead of silently falling back to bruteForce.
+const benchCorpusSize = 5000
+
+const benchVectorDim = 64
+
+// benchCorpus builds a deterministic corpus of random unit-ish vectors and
+// an index populated with them, so every sub-benchmark searches the same
+// data regardless of M/EfConstruction.
+func benchCorpus(b *testing.B, cfg Config) (*HNSW, [][]float64) {
+	b.Helper()
+	rng := rand.New(rand.NewSource(1))
+	vectors := make([][]float64, benchCorpusSize)
+	idx := New(cfg)
+	for i := range vectors {
+		v := make([]float64, benchVectorDim)
+		for d := range v {
+			v[d] = rng.Float64()*2 - 1
+		}
+		vectors[i] = v
+		if err := idx.Insert(fmt.Sprintf("vec-%d", i), v); err != nil {
+			b.Fatalf("insert vec-%d: %v", i, err)
+		}
+	}
+	return idx, vectors
+}
+
+// recallAt10 measures SearchEf's agreement with the exact bruteForce
+// baseline across numQueries random query vectors, returning the fraction
+// of bruteForce's top-10 IDs that also appear in SearchEf's top-10.
+func recallAt10(idx *HNSW, vectors [][]float64, ef, numQueries int) float64 {
+	rng := rand.New(rand.NewSource(2))
+	var totalRecall float64
+	for q := 0; q < numQueries; q++ {
+		query := vectors[rng.Intn(len(vectors))]
+
+		truth := idx.bruteForce(query, 10, -1)
+		got := idx.SearchEf(query, 10, -1, ef)
+
+		inTruth := make(map[string]bool, len(truth))
+		for _, r := range truth {
+			inTruth[r.ID] = true
+		}
+		var hits int
+		for _, r := range got {
+			if inTruth[r.ID] {
+				hits++
+			}
+		}
+		if len(truth) > 0 {
+			totalRecall += float64(hits) / float64(len(truth))
+		}
+	}
+	return totalRecall / float64(numQueries)
+}
+
+// BenchmarkSearchRecallVsLatency reports recall@10 and per-search latency
+// for the approximate HNSW path at a range of ef values, so a change to the
+// default EfSearch (or to M/EfConstruction in DefaultConfig) can be judged
+// against both axes instead of just one.
+func BenchmarkSearchRecallVsLatency(b *testing.B) {
+	cfg := DefaultConfig()
+	idx, vectors := benchCorpus(b, cfg)
+
+	for _, ef := range []int{16, 32, 64, 128, 256} {
+		ef := ef
+		b.Run(fmt.Sprintf("ef=%d", ef), func(b *testing.B) {
+			b.ReportMetric(recallAt10(idx, vectors, ef, 50)*100, "recall@10,%")
+
+			rng := rand.New(rand.NewSource(3))
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				query := vectors[rng.Intn(len(vectors))]
+				idx.SearchEf(query, 10, -1, ef)
+			}
+		})
+	}
+}
+
+// BenchmarkBruteForceSearch is the exact ?exact=true fallback's latency at
+// the same corpus size, as the baseline BenchmarkSearchRecallVsLatency's ef
+// sweep is traded against.
+func BenchmarkBruteForceSearch(b *testing.B) {
+	idx, vectors := benchCorpus(b, DefaultConfig())
+	rng := rand.New(rand.NewSource(3))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		query := vectors[rng.Intn(len(vectors))]
+		idx.bruteForce(query, 10, -1)
+	}
+}
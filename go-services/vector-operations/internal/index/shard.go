@@ -0,0 +1,148 @@
+package index
+
+import (
+	"bytes"
+	"encoding/gob"
+	"io"
+	"sync"
+)
+
+// Sharded is an Index that keeps one HNSW graph per embedding dimension.
+// Cosine/L2 distance isn't meaningful between vectors of different
+// dimension, so a single global graph would either reject cross-dimension
+// edges at insert time or silently produce garbage distances; sharding by
+// dimension sidesteps the question entirely and lets each graph's
+// Insert/Search stay O(log n) within its own corpus.
+type Sharded struct {
+	cfg Config
+
+	mu     sync.RWMutex
+	shards map[int]*HNSW
+	dimOf  map[string]int // id -> dimension, so Remove can find the right shard
+}
+
+// NewSharded creates an empty Sharded index. Shards are created lazily,
+// one per distinct vector dimension seen by Insert, each using cfg.
+func NewSharded(cfg Config) *Sharded {
+	return &Sharded{
+		cfg:    cfg,
+		shards: make(map[int]*HNSW),
+		dimOf:  make(map[string]int),
+	}
+}
+
+func (s *Sharded) shardFor(dim int) *HNSW {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sh, ok := s.shards[dim]
+	if !ok {
+		sh = New(s.cfg)
+		s.shards[dim] = sh
+	}
+	return sh
+}
+
+// Insert routes vector to the shard matching its dimension, creating that
+// shard on first use.
+func (s *Sharded) Insert(id string, vector []float64) error {
+	dim := len(vector)
+	s.mu.Lock()
+	s.dimOf[id] = dim
+	s.mu.Unlock()
+	return s.shardFor(dim).Insert(id, vector)
+}
+
+// Remove deletes id from whichever shard it was inserted into.
+func (s *Sharded) Remove(id string) bool {
+	s.mu.Lock()
+	dim, ok := s.dimOf[id]
+	if ok {
+		delete(s.dimOf, id)
+	}
+	s.mu.Unlock()
+	if !ok {
+		return false
+	}
+	return s.shardFor(dim).Remove(id)
+}
+
+// Search runs against the shard matching query's dimension, returning no
+// results if that shard doesn't exist (nothing of that dimension has been
+// indexed yet).
+func (s *Sharded) Search(query []float64, topK int, threshold float64) []Result {
+	return s.SearchEf(query, topK, threshold, 0)
+}
+
+// SearchEf is Search with the beam width overridden to ef (<=0 uses each
+// shard's configured EfSearch).
+func (s *Sharded) SearchEf(query []float64, topK int, threshold float64, ef int) []Result {
+	s.mu.RLock()
+	sh, ok := s.shards[len(query)]
+	s.mu.RUnlock()
+	if !ok {
+		return nil
+	}
+	return sh.SearchEf(query, topK, threshold, ef)
+}
+
+// Len returns the total number of vectors indexed across every shard.
+func (s *Sharded) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	total := 0
+	for _, sh := range s.shards {
+		total += sh.Len()
+	}
+	return total
+}
+
+type persistedShardedGraph struct {
+	Shards map[int][]byte // dimension -> that shard's HNSW.Save() bytes
+}
+
+// Save serializes every shard (each via HNSW.Save) keyed by dimension.
+func (s *Sharded) Save(w io.Writer) error {
+	s.mu.RLock()
+	shards := make(map[int][]byte, len(s.shards))
+	for dim, sh := range s.shards {
+		var buf bytes.Buffer
+		if err := sh.Save(&buf); err != nil {
+			s.mu.RUnlock()
+			return err
+		}
+		shards[dim] = buf.Bytes()
+	}
+	s.mu.RUnlock()
+
+	return gob.NewEncoder(w).Encode(persistedShardedGraph{Shards: shards})
+}
+
+// Load restores shards previously written by Save, rebuilding dimOf from
+// each shard's contents.
+func (s *Sharded) Load(r io.Reader) error {
+	var g persistedShardedGraph
+	if err := gob.NewDecoder(r).Decode(&g); err != nil {
+		return err
+	}
+
+	shards := make(map[int]*HNSW, len(g.Shards))
+	dimOf := make(map[string]int)
+	for dim, blob := range g.Shards {
+		sh := New(s.cfg)
+		if err := sh.Load(bytes.NewReader(blob)); err != nil {
+			return err
+		}
+		shards[dim] = sh
+		sh.mu.RLock()
+		for id := range sh.nodes {
+			dimOf[id] = dim
+		}
+		sh.mu.RUnlock()
+	}
+
+	s.mu.Lock()
+	s.shards = shards
+	s.dimOf = dimOf
+	s.mu.Unlock()
+	return nil
+}
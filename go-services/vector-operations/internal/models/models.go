@@ -12,6 +12,10 @@ type Vector struct {
 	CreatorID   string    `json:"creator_id"`
 	CreatedAt   time.Time `json:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at"`
+	// Embeddings holds additional named vector spaces for this row (e.g.
+	// "w_matrix", "reasoning_chain"), beyond the primary Embedding, so one
+	// record can be searched across more than one embedding model at once.
+	Embeddings map[string][]float64 `json:"embeddings,omitempty"`
 }
 
 // VectorSearchRequest represents a similarity search request
@@ -19,6 +23,43 @@ type VectorSearchRequest struct {
 	QueryVector []float64 `json:"query_vector" binding:"required"`
 	TopK        int       `json:"top_k" binding:"required,min=1,max=100"`
 	Threshold   float64   `json:"threshold,omitempty"`
+	// Ef overrides the HNSW index's configured beam width for this query
+	// only (0 keeps the index's default). Higher values trade latency for
+	// recall.
+	Ef int `json:"ef,omitempty"`
+	// Exact bypasses the HNSW index entirely in favor of a brute-force scan,
+	// for correctness testing against the approximate path.
+	Exact bool `json:"exact,omitempty"`
+}
+
+// HybridVectorTarget is one named vector space to search as part of a hybrid
+// query: Name selects Vector.Embeddings[Name] ("" selects the primary
+// Embedding field), QueryVector is searched against it, and Weight scales
+// that source's contribution to the fused ranking (<=0 defaults to 1).
+type HybridVectorTarget struct {
+	Name        string    `json:"name"`
+	QueryVector []float64 `json:"query_vector" binding:"required"`
+	Weight      float64   `json:"weight,omitempty"`
+}
+
+// HybridSearchRequest runs dense KNN search over one or more named vector
+// spaces and a sparse BM25 keyword search over name+description in
+// parallel, then fuses the ranked lists with Reciprocal Rank Fusion.
+type HybridSearchRequest struct {
+	TextQuery     string               `json:"text_query"`
+	TextWeight    float64              `json:"text_weight,omitempty"`
+	TargetVectors []HybridVectorTarget `json:"target_vectors" binding:"required,min=1,dive"`
+	TopK          int                  `json:"top_k" binding:"required,min=1,max=100"`
+	Threshold     float64              `json:"threshold,omitempty"`
+}
+
+// HybridSearchResult is a fused hybrid-search hit, carrying the combined RRF
+// score and which sources (e.g. "bm25", "w_matrix") contributed to it so
+// callers can explain the ranking.
+type HybridSearchResult struct {
+	Vector  Vector   `json:"vector"`
+	Score   float64  `json:"score"`
+	Sources []string `json:"sources"`
 }
 
 // VectorSearchResult represents a search result
@@ -1,12 +1,15 @@
 package main
 
 import (
+	"context"
 	"log"
 	"os"
 
 	"github.com/awareness/w-matrix-marketplace/internal/database"
+	"github.com/awareness/w-matrix-marketplace/internal/events"
 	"github.com/awareness/w-matrix-marketplace/internal/handlers"
 	"github.com/awareness/w-matrix-marketplace/internal/middleware"
+	"github.com/awareness/w-matrix-marketplace/internal/search"
 	"github.com/awareness/w-matrix-marketplace/internal/storage"
 	"github.com/gin-gonic/gin"
 	swaggerFiles "github.com/swaggo/files"
@@ -46,6 +49,30 @@ func main() {
 		log.Printf("⚠️  S3 not configured: %v", err)
 	}
 
+	// Select the search engine for GET /api/v1/search (SEARCH_ENGINE=es|mysql,
+	// default mysql); falls back to MySQLEngine on any ES setup failure.
+	if err := search.Init(context.Background()); err != nil {
+		log.Printf("⚠️  %v", err)
+	}
+
+	// EVENT_BROKER selects the event-bus implementation (kafka, nats, or
+	// unset/memory for an in-process broker with no cross-process
+	// delivery); see internal/events.
+	broker, err := events.New(events.BrokerConfig{
+		Kind: os.Getenv("EVENT_BROKER"),
+		URL:  os.Getenv("EVENT_BROKER_URL"),
+	})
+	if err != nil {
+		log.Fatalf("Failed to init event broker: %v", err)
+	}
+	handlers.InitEvents(broker)
+
+	// Consume recommendation-engine InteractionRecorded events to drive
+	// GET /trending without this service needing its own tracking endpoint.
+	if err := broker.Subscribe(context.Background(), events.TopicInteractionRecorded, handlers.EventConsumerGroup, handlers.HandleInteractionRecorded); err != nil {
+		log.Printf("⚠️  Failed to subscribe to InteractionRecorded events: %v", err)
+	}
+
 	// Create Gin router
 	router := gin.Default()
 
@@ -67,7 +94,9 @@ func main() {
 	{
 		api.POST("/listings", handlers.CreateListing)
 		api.GET("/listings", handlers.BrowseListings)
+		api.GET("/search", handlers.SearchListings)
 		api.POST("/purchase", handlers.PurchaseListing)
+		api.GET("/trending", handlers.GetTrending)
 	}
 
 	// Start server
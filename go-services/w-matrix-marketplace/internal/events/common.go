@@ -0,0 +1,39 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+)
+
+var errClosed = errors.New("events: broker is closed")
+
+// maxDeliveryAttempts bounds how many times withRetry calls a handler
+// before giving up and letting the caller dead-letter the event.
+const maxDeliveryAttempts = 5
+
+// withRetry calls handler up to maxDeliveryAttempts times with a short
+// linear backoff between attempts, giving a transient failure (a momentary
+// DB blip inside the handler, say) a chance to clear before the event is
+// dead-lettered. It also records the consumed/consume-error counters so
+// every Broker implementation reports the same metrics.
+func withRetry(ctx context.Context, topic, group string, evt Event, handler Handler) error {
+	var err error
+	for attempt := 1; attempt <= maxDeliveryAttempts; attempt++ {
+		if err = handler(ctx, evt); err == nil {
+			consumedTotal.WithLabelValues(topic, group).Inc()
+			return nil
+		}
+		consumeErrorsTotal.WithLabelValues(topic, group).Inc()
+		log.Printf("events: handler for %s/%s failed (attempt %d/%d): %v", topic, group, attempt, maxDeliveryAttempts, err)
+		if attempt < maxDeliveryAttempts {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(time.Duration(attempt) * 200 * time.Millisecond):
+			}
+		}
+	}
+	return err
+}
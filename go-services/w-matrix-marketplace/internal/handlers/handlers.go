@@ -1,19 +1,60 @@
 package handlers
 
 import (
+	"context"
 	"crypto/rand"
 	"database/sql"
 	"encoding/hex"
+	"encoding/json"
 	"fmt"
+	"log"
 	"net/http"
+	"sort"
+	"strconv"
 	"time"
 
 	"github.com/awareness/w-matrix-marketplace/internal/database"
+	"github.com/awareness/w-matrix-marketplace/internal/events"
 	"github.com/awareness/w-matrix-marketplace/internal/models"
+	"github.com/awareness/w-matrix-marketplace/internal/search"
 	"github.com/awareness/w-matrix-marketplace/internal/storage"
+	"github.com/awareness/w-matrix-marketplace/internal/trending"
+	"github.com/awareness/w-matrix-marketplace/internal/vectorclient"
 	"github.com/gin-gonic/gin"
 )
 
+// EventConsumerGroup identifies this service's durable position on topics
+// it subscribes to (see internal/events.Broker.Subscribe).
+const EventConsumerGroup = "w-matrix-marketplace"
+
+// Events is the shared event broker CreateListing/PurchaseListing publish
+// to and HandleInteractionRecorded consumes through, set once at startup
+// via InitEvents.
+var Events events.Broker
+
+// Trending ranks listings by recent InteractionRecorded activity; see
+// HandleInteractionRecorded and GetTrending.
+var Trending = trending.New()
+
+// InitEvents wires the shared Broker instance other handlers in this
+// package publish to. Call once at startup before the router handles
+// requests.
+func InitEvents(broker events.Broker) {
+	Events = broker
+}
+
+// HandleInteractionRecorded folds a cross-service InteractionRecorded
+// event (e.g. from recommendation-engine's POST /track) into Trending, so
+// GET /trending reflects activity this service never saw a request for.
+func HandleInteractionRecorded(ctx context.Context, evt events.Event) error {
+	var payload events.InteractionRecordedPayload
+	if err := json.Unmarshal(evt.Payload, &payload); err != nil {
+		return fmt.Errorf("decode InteractionRecorded payload: %w", err)
+	}
+	Trending.Record(payload.ItemID)
+	return nil
+}
+
 // CreateListing godoc
 // @Summary Create a new W-Matrix listing
 // @Description Create a new W-Matrix alignment tool listing for trading
@@ -61,6 +102,8 @@ func CreateListing(c *gin.Context) {
 
 	listingID, _ := result.LastInsertId()
 
+	publishListingCreated(matrixID, userID, req.Title)
+
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
 		"data": gin.H{
@@ -70,6 +113,25 @@ func CreateListing(c *gin.Context) {
 	})
 }
 
+// publishListingCreated is best-effort: a broker outage shouldn't fail a
+// listing creation that's already committed to the database.
+func publishListingCreated(matrixID string, creatorID int, title string) {
+	evt, err := events.New(events.TypeListingCreated, "listing:"+matrixID, events.ListingCreatedPayload{
+		ItemID:    matrixID,
+		ItemType:  "w_matrix",
+		CreatorID: strconv.Itoa(creatorID),
+		Title:     title,
+		CreatedAt: time.Now(),
+	})
+	if err != nil {
+		log.Printf("w-matrix-marketplace: failed to build ListingCreated event: %v", err)
+		return
+	}
+	if err := Events.Publish(context.Background(), events.TopicListingCreated, evt); err != nil {
+		log.Printf("w-matrix-marketplace: failed to publish ListingCreated event: %v", err)
+	}
+}
+
 // BrowseListings godoc
 // @Summary Browse W-Matrix listings
 // @Description Get a list of W-Matrix listings with optional filtering and sorting
@@ -183,12 +245,121 @@ func BrowseListings(c *gin.Context) {
 		listings = append(listings, listing)
 	}
 
+	if similarTo := c.Query("similar_to"); similarTo != "" {
+		listings = rankBySimilarity(listings, similarTo)
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
 		"data":    listings,
 	})
 }
 
+// rankBySimilarity reorders listings by similarity to the listing whose
+// MatrixID is similarTo, using vector-operations' ANN index. Listings that
+// aren't in the similarity results keep their original relative order,
+// appended after the ranked ones, so a vector-operations outage degrades to
+// the existing sort instead of dropping results.
+func rankBySimilarity(listings []models.WMatrixListing, similarTo string) []models.WMatrixListing {
+	similarIDs, err := vectorclient.SimilarVectorIDs(similarTo, len(listings))
+	if err != nil {
+		return listings
+	}
+
+	rank := make(map[string]int, len(similarIDs))
+	for i, id := range similarIDs {
+		rank[id] = i
+	}
+
+	ranked := make([]models.WMatrixListing, 0, len(listings))
+	rest := make([]models.WMatrixListing, 0, len(listings))
+	for _, l := range listings {
+		if _, ok := rank[l.MatrixID]; ok {
+			ranked = append(ranked, l)
+		} else {
+			rest = append(rest, l)
+		}
+	}
+
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return rank[ranked[i].MatrixID] < rank[ranked[j].MatrixID]
+	})
+
+	return append(ranked, rest...)
+}
+
+// SearchListings godoc
+// @Summary Hybrid search over W-Matrix listings
+// @Description BM25 text search over title/description, optionally fused with kNN similarity over an embedding, backed by Elasticsearch/OpenSearch (falls back to MySQL LIKE when SEARCH_ENGINE=mysql or the search engine is unavailable)
+// @Tags listings
+// @Accept json
+// @Produce json
+// @Param q query string false "Free-text query matched against title/description"
+// @Param embedding query string false "Base64-encoded little-endian float32 query vector"
+// @Param source_model query string false "Filter by source model"
+// @Param target_model query string false "Filter by target model"
+// @Param min_price query number false "Minimum price filter"
+// @Param max_price query number false "Maximum price filter"
+// @Param sort_by query string false "Sort by: newest, price_asc, price_desc, rating"
+// @Param k query int false "Number of results" default(20)
+// @Success 200 {object} map[string]interface{} "Search results"
+// @Failure 400 {object} map[string]interface{} "Invalid request"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security ApiKeyAuth
+// @Router /search [get]
+func SearchListings(c *gin.Context) {
+	var req models.SearchListingsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	var embedding []float32
+	if req.Embedding != "" {
+		var err error
+		embedding, err = search.DecodeEmbedding(req.Embedding)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success": false,
+				"error":   "invalid embedding: " + err.Error(),
+			})
+			return
+		}
+	}
+
+	sreq := search.Request{
+		Query:     req.Q,
+		Embedding: embedding,
+		MinPrice:  req.MinPrice,
+		MaxPrice:  req.MaxPrice,
+		SortBy:    req.SortBy,
+		Limit:     req.K,
+	}
+	if req.SourceModel != nil {
+		sreq.SourceModel = *req.SourceModel
+	}
+	if req.TargetModel != nil {
+		sreq.TargetModel = *req.TargetModel
+	}
+
+	docs, err := search.Default.Search(c.Request.Context(), sreq)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "search failed: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    docs,
+	})
+}
+
 // PurchaseListing godoc
 // @Summary Purchase a W-Matrix
 // @Description Purchase access to a W-Matrix and get a 7-day download URL
@@ -277,6 +448,8 @@ func PurchaseListing(c *gin.Context) {
 	`
 	database.DB.Exec(updateQuery, price, req.ListingID)
 
+	publishListingPurchased(matrixID, userID, price)
+
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
 		"data": gin.H{
@@ -287,6 +460,44 @@ func PurchaseListing(c *gin.Context) {
 	})
 }
 
+// publishListingPurchased is best-effort: a broker outage shouldn't fail a
+// purchase that's already committed to the database.
+func publishListingPurchased(matrixID string, buyerID int, price float64) {
+	evt, err := events.New(events.TypeListingPurchased, fmt.Sprintf("purchase:%s:%d", matrixID, buyerID), events.ListingPurchasedPayload{
+		ItemID:      matrixID,
+		BuyerID:     strconv.Itoa(buyerID),
+		Price:       price,
+		PurchasedAt: time.Now(),
+	})
+	if err != nil {
+		log.Printf("w-matrix-marketplace: failed to build ListingPurchased event: %v", err)
+		return
+	}
+	if err := Events.Publish(context.Background(), events.TopicListingPurchased, evt); err != nil {
+		log.Printf("w-matrix-marketplace: failed to publish ListingPurchased event: %v", err)
+	}
+}
+
+// GetTrending godoc
+// @Summary Trending listings
+// @Description Return the most active listings by recent tracked interactions, decayed over time so stale bursts fall off
+// @Tags listings
+// @Produce json
+// @Param limit query int false "Number of items" default(10)
+// @Success 200 {object} map[string]interface{}
+// @Router /trending [get]
+func GetTrending(c *gin.Context) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if err != nil || limit < 1 {
+		limit = 10
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    Trending.Top(limit),
+	})
+}
+
 // generateMatrixID generates a unique matrix ID
 func generateMatrixID() string {
 	b := make([]byte, 16)
@@ -10,7 +10,9 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-// APIKeyAuth middleware validates API keys
+// APIKeyAuth middleware validates API keys, enforces their per-key token-
+// bucket rate limit and monthly quota, and makes their granted scopes
+// available to RequireScope.
 func APIKeyAuth() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
@@ -82,7 +84,40 @@ func APIKeyAuth() gin.HandlerFunc {
 			return
 		}
 
+		keyHash := hashAPIKey(apiKey)
+		limits, err := getKeyLimits(apiKey, keyHash)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"success": false,
+				"error":   "Database error",
+			})
+			c.Abort()
+			return
+		}
+
+		setRateLimitHeaders(c, limits)
+		if !limits.limiter.Allow() {
+			c.JSON(http.StatusTooManyRequests, gin.H{
+				"success": false,
+				"error":   "Rate limit exceeded",
+			})
+			c.Abort()
+			return
+		}
+		if !checkAndIncrementQuota(keyHash, limits.quotaMonthly) {
+			c.JSON(http.StatusTooManyRequests, gin.H{
+				"success": false,
+				"error":   "Monthly quota exceeded",
+			})
+			c.Abort()
+			return
+		}
+
+		// Batch the last_used_at write instead of doing it inline per request
+		flusher.touch(apiKey)
+
 		c.Set("user_id", userID)
+		c.Set("scopes", limits.scopes)
 		c.Next()
 	}
 }
@@ -78,6 +78,18 @@ type BrowseListingsRequest struct {
 	Offset      int      `form:"offset" binding:"min=0"`
 }
 
+// SearchListingsRequest represents query parameters for GET /api/v1/search
+type SearchListingsRequest struct {
+	Q           string   `form:"q"`
+	Embedding   string   `form:"embedding"` // base64 little-endian float32 array
+	SourceModel *string  `form:"source_model"`
+	TargetModel *string  `form:"target_model"`
+	MinPrice    *float64 `form:"min_price"`
+	MaxPrice    *float64 `form:"max_price"`
+	SortBy      string   `form:"sort_by"`
+	K           int      `form:"k"`
+}
+
 // APIResponse represents a standard API response
 type APIResponse struct {
 	Success bool        `json:"success"`
@@ -0,0 +1,21 @@
+// Package search provides an Elasticsearch/OpenSearch-backed index over
+// W-Matrix listings, so BrowseListings can run one BM25+kNN query instead of
+// the MySQL LIKE/range scan in handlers.BrowseListings.
+package search
+
+// ListingDocument is the shape indexed for each wMatrixListings row. Field
+// names are snake_case to match the mapping in mapping.go.
+type ListingDocument struct {
+	ID            string    `json:"id"`
+	Title         string    `json:"title"`
+	Description   string    `json:"description"`
+	SourceModel   string    `json:"source_model"`
+	TargetModel   string    `json:"target_model"`
+	CreatorID     string    `json:"creator_id"`
+	MatrixID      string    `json:"matrix_id"`
+	Price         float64   `json:"price"`
+	AlignmentLoss float64   `json:"alignment_loss"`
+	Rating        float64   `json:"rating"`
+	Status        string    `json:"status"`
+	Embedding     []float32 `json:"embedding,omitempty"`
+}
@@ -0,0 +1,27 @@
+package search
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"math"
+)
+
+// DecodeEmbedding decodes a base64-encoded little-endian float32 array, the
+// format the `embedding` query parameter of GET /api/v1/search accepts.
+func DecodeEmbedding(b64 string) ([]float32, error) {
+	raw, err := base64.StdEncoding.DecodeString(b64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid base64 embedding: %w", err)
+	}
+	if len(raw)%4 != 0 {
+		return nil, fmt.Errorf("embedding byte length %d is not a multiple of 4", len(raw))
+	}
+
+	vec := make([]float32, len(raw)/4)
+	for i := range vec {
+		bits := binary.LittleEndian.Uint32(raw[i*4 : i*4+4])
+		vec[i] = math.Float32frombits(bits)
+	}
+	return vec, nil
+}
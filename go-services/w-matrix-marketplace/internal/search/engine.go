@@ -0,0 +1,252 @@
+package search
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/elastic/go-elasticsearch/v8"
+	"github.com/elastic/go-elasticsearch/v8/esapi"
+)
+
+// Request is the parsed form of a GET /api/v1/search query.
+type Request struct {
+	Query       string    // q: free-text BM25 query
+	Embedding   []float32 // decoded from a base64 float32 array, optional
+	SourceModel string
+	TargetModel string
+	MinPrice    *float64
+	MaxPrice    *float64
+	SortBy      string // newest, price_asc, price_desc, rating
+	Limit       int
+}
+
+// Engine is implemented by every search backend BrowseListings/SearchListings
+// can use. MySQLEngine wraps the existing LIKE/range query so the handler
+// keeps working when Elasticsearch is unavailable or SEARCH_ENGINE=mysql.
+type Engine interface {
+	Search(ctx context.Context, req Request) ([]ListingDocument, error)
+}
+
+// ESEngine is the Elasticsearch/OpenSearch-backed Engine. It combines a
+// multi_match BM25 clause over title/description with a knn clause over
+// Embedding, fused by Elasticsearch's native rrf retriever.
+type ESEngine struct {
+	es   *elasticsearch.Client
+	name string
+}
+
+// NewESEngine connects to the given ES/OpenSearch addresses and binds to
+// indexName (DefaultIndexName if empty).
+func NewESEngine(addresses []string, indexName string) (*ESEngine, error) {
+	if indexName == "" {
+		indexName = DefaultIndexName
+	}
+
+	es, err := elasticsearch.NewClient(elasticsearch.Config{Addresses: addresses})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create elasticsearch client: %w", err)
+	}
+
+	return &ESEngine{es: es, name: indexName}, nil
+}
+
+// EnsureMapping creates the index with its mapping if it does not already exist.
+func (e *ESEngine) EnsureMapping(ctx context.Context) error {
+	exists, err := esapi.IndicesExistsRequest{Index: []string{e.name}}.Do(ctx, e.es)
+	if err != nil {
+		return fmt.Errorf("failed to check index existence: %w", err)
+	}
+	defer exists.Body.Close()
+
+	if exists.StatusCode == 200 {
+		return nil
+	}
+
+	create, err := esapi.IndicesCreateRequest{
+		Index: e.name,
+		Body:  strings.NewReader(mapping()),
+	}.Do(ctx, e.es)
+	if err != nil {
+		return fmt.Errorf("failed to create index: %w", err)
+	}
+	defer create.Body.Close()
+
+	if create.IsError() {
+		return fmt.Errorf("failed to create index %q: %s", e.name, create.String())
+	}
+	return nil
+}
+
+// BulkIndex upserts many documents in a single request using the ES bulk API.
+func (e *ESEngine) BulkIndex(ctx context.Context, docs []ListingDocument) error {
+	if len(docs) == 0 {
+		return nil
+	}
+
+	var buf bytes.Buffer
+	for _, doc := range docs {
+		meta := map[string]interface{}{
+			"index": map[string]interface{}{"_index": e.name, "_id": doc.ID},
+		}
+		metaLine, _ := json.Marshal(meta)
+		docLine, err := json.Marshal(doc)
+		if err != nil {
+			return fmt.Errorf("failed to marshal document %s: %w", doc.ID, err)
+		}
+		buf.Write(metaLine)
+		buf.WriteByte('\n')
+		buf.Write(docLine)
+		buf.WriteByte('\n')
+	}
+
+	res, err := esapi.BulkRequest{Body: bytes.NewReader(buf.Bytes())}.Do(ctx, e.es)
+	if err != nil {
+		return fmt.Errorf("bulk index request failed: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.IsError() {
+		return fmt.Errorf("bulk index request failed: %s", res.String())
+	}
+	return nil
+}
+
+// Search runs the hybrid BM25+kNN query and returns the matching listings.
+func (e *ESEngine) Search(ctx context.Context, req Request) ([]ListingDocument, error) {
+	body, err := json.Marshal(e.buildQuery(req))
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal search query: %w", err)
+	}
+
+	res, err := esapi.SearchRequest{
+		Index: []string{e.name},
+		Body:  bytes.NewReader(body),
+	}.Do(ctx, e.es)
+	if err != nil {
+		return nil, fmt.Errorf("search request failed: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.IsError() {
+		return nil, fmt.Errorf("search request failed: %s", res.String())
+	}
+
+	var parsed struct {
+		Hits struct {
+			Hits []struct {
+				Source ListingDocument `json:"_source"`
+			} `json:"hits"`
+		} `json:"hits"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&parsed); err != nil {
+		return nil, fmt.Errorf("failed to decode search response: %w", err)
+	}
+
+	docs := make([]ListingDocument, 0, len(parsed.Hits.Hits))
+	for _, hit := range parsed.Hits.Hits {
+		docs = append(docs, hit.Source)
+	}
+	return docs, nil
+}
+
+// buildQuery assembles the filter clauses shared by both retrievers, then
+// either a plain bool query (no embedding) or an rrf retriever that fuses
+// BM25 and knn rankings (embedding present).
+func (e *ESEngine) buildQuery(req Request) map[string]interface{} {
+	filters := []map[string]interface{}{
+		{"term": map[string]interface{}{"status": "active"}},
+	}
+	if req.SourceModel != "" {
+		filters = append(filters, map[string]interface{}{
+			"term": map[string]interface{}{"source_model": req.SourceModel},
+		})
+	}
+	if req.TargetModel != "" {
+		filters = append(filters, map[string]interface{}{
+			"term": map[string]interface{}{"target_model": req.TargetModel},
+		})
+	}
+	if req.MinPrice != nil || req.MaxPrice != nil {
+		priceRange := map[string]interface{}{}
+		if req.MinPrice != nil {
+			priceRange["gte"] = *req.MinPrice
+		}
+		if req.MaxPrice != nil {
+			priceRange["lte"] = *req.MaxPrice
+		}
+		filters = append(filters, map[string]interface{}{
+			"range": map[string]interface{}{"price": priceRange},
+		})
+	}
+
+	must := []map[string]interface{}{}
+	if req.Query != "" {
+		must = append(must, map[string]interface{}{
+			"multi_match": map[string]interface{}{
+				"query":  req.Query,
+				"fields": []string{"title^2", "description"},
+			},
+		})
+	} else {
+		must = append(must, map[string]interface{}{"match_all": map[string]interface{}{}})
+	}
+
+	size := limitOrDefault(req.Limit)
+	bm25Query := map[string]interface{}{
+		"bool": map[string]interface{}{
+			"must":   must,
+			"filter": filters,
+		},
+	}
+
+	if len(req.Embedding) == 0 {
+		return map[string]interface{}{
+			"size":  size,
+			"sort":  sortClause(req.SortBy),
+			"query": bm25Query,
+		}
+	}
+
+	// Fuse BM25 and kNN rankings with Elasticsearch's native rrf retriever
+	// rather than hand-rolling reciprocal rank fusion client-side.
+	return map[string]interface{}{
+		"retriever": map[string]interface{}{
+			"rrf": map[string]interface{}{
+				"retrievers": []map[string]interface{}{
+					{"standard": map[string]interface{}{"query": bm25Query}},
+					{"knn": map[string]interface{}{
+						"field":          "embedding",
+						"query_vector":   req.Embedding,
+						"k":              size,
+						"num_candidates": size * 10,
+						"filter":         map[string]interface{}{"bool": map[string]interface{}{"filter": filters}},
+					}},
+				},
+			},
+		},
+		"size": size,
+	}
+}
+
+func sortClause(sortBy string) []map[string]interface{} {
+	switch sortBy {
+	case "price_asc":
+		return []map[string]interface{}{{"price": "asc"}}
+	case "price_desc":
+		return []map[string]interface{}{{"price": "desc"}}
+	case "rating":
+		return []map[string]interface{}{{"rating": "desc"}}
+	default:
+		return []map[string]interface{}{{"_score": "desc"}}
+	}
+}
+
+func limitOrDefault(limit int) int {
+	if limit > 0 {
+		return limit
+	}
+	return 20
+}
@@ -0,0 +1,93 @@
+package search
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/awareness/w-matrix-marketplace/internal/database"
+)
+
+// Indexer periodically scans wMatrixListings for rows updated since its last
+// checkpoint and bulk-upserts them into an ESEngine. There is no
+// change-data-capture table, so a periodic scan on updatedAt is the cheapest
+// way to keep the index fresh without touching every write path in
+// handlers.CreateListing/PurchaseListing.
+type Indexer struct {
+	engine   *ESEngine
+	interval time.Duration
+	since    time.Time
+}
+
+// NewIndexer builds an Indexer that re-scans every interval, starting from
+// the epoch so the first run indexes the full table.
+func NewIndexer(engine *ESEngine, interval time.Duration) *Indexer {
+	return &Indexer{engine: engine, interval: interval, since: time.Unix(0, 0)}
+}
+
+// Run scans once immediately, then on every tick, until ctx is cancelled.
+func (ix *Indexer) Run(ctx context.Context) {
+	ix.scanOnce(ctx)
+
+	ticker := time.NewTicker(ix.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			ix.scanOnce(ctx)
+		}
+	}
+}
+
+func (ix *Indexer) scanOnce(ctx context.Context) {
+	rows, err := database.DB.QueryContext(ctx, `
+		SELECT id, title, description, sourceModel, targetModel, creatorId,
+		       matrixId, price, alignmentLoss, averageRating, status, updatedAt
+		FROM wMatrixListings
+		WHERE updatedAt > ?
+		ORDER BY updatedAt ASC
+	`, ix.since)
+	if err != nil {
+		log.Printf("search: checkpoint scan failed: %v", err)
+		return
+	}
+	defer rows.Close()
+
+	var docs []ListingDocument
+	maxSeen := ix.since
+	for rows.Next() {
+		var d ListingDocument
+		var id, creatorID int
+		var rating sql.NullFloat64
+		var updatedAt time.Time
+		if err := rows.Scan(&id, &d.Title, &d.Description, &d.SourceModel, &d.TargetModel,
+			&creatorID, &d.MatrixID, &d.Price, &d.AlignmentLoss, &rating, &d.Status, &updatedAt); err != nil {
+			continue
+		}
+		d.ID = fmt.Sprintf("%d", id)
+		d.CreatorID = fmt.Sprintf("%d", creatorID)
+		if rating.Valid {
+			d.Rating = rating.Float64
+		}
+		docs = append(docs, d)
+		if updatedAt.After(maxSeen) {
+			maxSeen = updatedAt
+		}
+	}
+
+	if len(docs) == 0 {
+		return
+	}
+
+	if err := ix.engine.BulkIndex(ctx, docs); err != nil {
+		log.Printf("search: bulk index failed: %v", err)
+		return
+	}
+	ix.since = maxSeen
+	log.Printf("search: indexed %d listings updated since checkpoint", len(docs))
+}
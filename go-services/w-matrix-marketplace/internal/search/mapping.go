@@ -0,0 +1,45 @@
+package search
+
+import "fmt"
+
+// EmbeddingDims is the size of the dense_vector field used for kNN
+// rescoring. It must match whatever embedding model produced the
+// KV-cache/W-matrix vectors stored in vector-operations.
+const EmbeddingDims = 768
+
+// DefaultIndexName is the index this package manages.
+const DefaultIndexName = "wmatrix-listings"
+
+// mapping returns the ES/OpenSearch index mapping: BM25 text fields for
+// title/description, keyword fields for exact filters, numeric ranges for
+// price/alignment_loss/rating, and a dense_vector field for hnsw kNN.
+func mapping() string {
+	return fmt.Sprintf(`{
+  "settings": {
+    "number_of_shards": 1,
+    "number_of_replicas": 1
+  },
+  "mappings": {
+    "properties": {
+      "id":             {"type": "keyword"},
+      "title":          {"type": "text"},
+      "description":    {"type": "text", "analyzer": "standard"},
+      "source_model":   {"type": "keyword"},
+      "target_model":   {"type": "keyword"},
+      "creator_id":     {"type": "keyword"},
+      "matrix_id":      {"type": "keyword"},
+      "price":          {"type": "double"},
+      "alignment_loss": {"type": "double"},
+      "rating":         {"type": "double"},
+      "status":         {"type": "keyword"},
+      "embedding": {
+        "type": "dense_vector",
+        "dims": %d,
+        "index": true,
+        "similarity": "cosine",
+        "index_options": {"type": "hnsw"}
+      }
+    }
+  }
+}`, EmbeddingDims)
+}
@@ -0,0 +1,83 @@
+package search
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/awareness/w-matrix-marketplace/internal/database"
+)
+
+// MySQLEngine is the fallback Engine used when Elasticsearch is unavailable
+// or SEARCH_ENGINE=mysql: the same LIKE/range query BrowseListings ran
+// before this package existed, with req.Query matched against title/
+// description via LIKE instead of BM25.
+type MySQLEngine struct{}
+
+func (MySQLEngine) Search(ctx context.Context, req Request) ([]ListingDocument, error) {
+	query := `SELECT id, title, description, sourceModel, targetModel, creatorId,
+	          matrixId, price, alignmentLoss, averageRating, status
+	          FROM wMatrixListings WHERE status = 'active'`
+
+	var args []interface{}
+
+	if req.Query != "" {
+		query += " AND (title LIKE ? OR description LIKE ?)"
+		like := "%" + req.Query + "%"
+		args = append(args, like, like)
+	}
+	if req.SourceModel != "" {
+		query += " AND sourceModel = ?"
+		args = append(args, req.SourceModel)
+	}
+	if req.TargetModel != "" {
+		query += " AND targetModel = ?"
+		args = append(args, req.TargetModel)
+	}
+	if req.MinPrice != nil {
+		query += " AND price >= ?"
+		args = append(args, *req.MinPrice)
+	}
+	if req.MaxPrice != nil {
+		query += " AND price <= ?"
+		args = append(args, *req.MaxPrice)
+	}
+
+	switch req.SortBy {
+	case "price_asc":
+		query += " ORDER BY price ASC"
+	case "price_desc":
+		query += " ORDER BY price DESC"
+	case "rating":
+		query += " ORDER BY averageRating DESC"
+	default:
+		query += " ORDER BY createdAt DESC"
+	}
+
+	query += " LIMIT ?"
+	args = append(args, limitOrDefault(req.Limit))
+
+	rows, err := database.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("mysql search query failed: %w", err)
+	}
+	defer rows.Close()
+
+	var docs []ListingDocument
+	for rows.Next() {
+		var d ListingDocument
+		var id, creatorID int
+		var rating sql.NullFloat64
+		if err := rows.Scan(&id, &d.Title, &d.Description, &d.SourceModel, &d.TargetModel,
+			&creatorID, &d.MatrixID, &d.Price, &d.AlignmentLoss, &rating, &d.Status); err != nil {
+			continue
+		}
+		d.ID = fmt.Sprintf("%d", id)
+		d.CreatorID = fmt.Sprintf("%d", creatorID)
+		if rating.Valid {
+			d.Rating = rating.Float64
+		}
+		docs = append(docs, d)
+	}
+	return docs, nil
+}
@@ -0,0 +1,48 @@
+package search
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// Default is the Engine wired up by Init and used by handlers.SearchListings.
+// It defaults to MySQLEngine so the service works before Init is called.
+var Default Engine = MySQLEngine{}
+
+// Init selects the search engine from SEARCH_ENGINE (es|mysql, default
+// mysql), and for "es" connects to ELASTICSEARCH_URL, ensures the index
+// mapping exists, and starts the background Indexer that keeps it fresh.
+// On any ES setup failure it logs and leaves Default on MySQLEngine, so a
+// misconfigured or unreachable cluster degrades search instead of booting
+// the service.
+func Init(ctx context.Context) error {
+	engine := strings.ToLower(os.Getenv("SEARCH_ENGINE"))
+	if engine == "" {
+		engine = "mysql"
+	}
+
+	if engine != "es" {
+		Default = MySQLEngine{}
+		return nil
+	}
+
+	addr := os.Getenv("ELASTICSEARCH_URL")
+	if addr == "" {
+		addr = "http://localhost:9200"
+	}
+
+	es, err := NewESEngine([]string{addr}, "")
+	if err != nil {
+		return fmt.Errorf("search: failed to create elasticsearch engine, falling back to mysql: %w", err)
+	}
+	if err := es.EnsureMapping(ctx); err != nil {
+		return fmt.Errorf("search: failed to ensure index mapping, falling back to mysql: %w", err)
+	}
+
+	Default = es
+	go NewIndexer(es, 30*time.Second).Run(ctx)
+	return nil
+}
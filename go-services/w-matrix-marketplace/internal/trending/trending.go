@@ -0,0 +1,82 @@
+// Package trending keeps a decayed, in-memory interaction count per item
+// so the marketplace can rank "trending" listings from the
+// InteractionRecorded event stream instead of a database aggregation
+// query.
+package trending
+
+import (
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+// halfLife controls how fast an item's trending score decays, so
+// consistently-interacted-with items stay near the top without one old
+// burst of activity dominating forever.
+const halfLife = 6 * time.Hour
+
+type entry struct {
+	weight    float64
+	updatedAt time.Time
+}
+
+// Tracker accumulates a decayed weight per item, fed by Record, and ranks
+// them in Top.
+type Tracker struct {
+	mu      sync.Mutex
+	weights map[string]*entry
+}
+
+func New() *Tracker {
+	return &Tracker{weights: make(map[string]*entry)}
+}
+
+// Record folds one observed interaction on itemID into its decayed
+// weight.
+func (t *Tracker) Record(itemID string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := time.Now()
+	e, ok := t.weights[itemID]
+	if !ok {
+		e = &entry{}
+		t.weights[itemID] = e
+	}
+	e.weight = decayedWeight(e, now) + 1
+	e.updatedAt = now
+}
+
+func decayedWeight(e *entry, now time.Time) float64 {
+	if e.weight == 0 {
+		return 0
+	}
+	elapsed := now.Sub(e.updatedAt)
+	return e.weight * math.Pow(0.5, elapsed.Hours()/halfLife.Hours())
+}
+
+// Item is one entry in Top's ranking.
+type Item struct {
+	ItemID string  `json:"item_id"`
+	Score  float64 `json:"score"`
+}
+
+// Top returns up to limit items by current decayed weight, descending.
+// limit <= 0 returns every tracked item.
+func (t *Tracker) Top(limit int) []Item {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := time.Now()
+	items := make([]Item, 0, len(t.weights))
+	for id, e := range t.weights {
+		items = append(items, Item{ItemID: id, Score: decayedWeight(e, now)})
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].Score > items[j].Score })
+
+	if limit > 0 && limit < len(items) {
+		items = items[:limit]
+	}
+	return items
+}
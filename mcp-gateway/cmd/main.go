@@ -4,24 +4,75 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
+	"strings"
 	"syscall"
 	"time"
 
+	"github.com/awareness-market/mcp-gateway/internal/graphql"
+	"github.com/awareness-market/mcp-gateway/internal/grpcserver"
+	"github.com/awareness-market/mcp-gateway/internal/grpcserver/pb"
 	"github.com/awareness-market/mcp-gateway/internal/handler"
+	"github.com/awareness-market/mcp-gateway/internal/search"
 	"github.com/awareness-market/mcp-gateway/internal/service"
 	"github.com/awareness-market/mcp-gateway/pkg/client"
 	"github.com/gin-gonic/gin"
+	graphqlhandler "github.com/graphql-go/handler"
+	"google.golang.org/grpc"
 )
 
+// discoveryIndexInterval is how often the discovery-facing index crawl
+// re-runs, standing in for true event-tailing until Memory carries a
+// creation timestamp the indexer can filter on.
+const discoveryIndexInterval = 30 * time.Second
+
 func main() {
 	// Initialize services
 	apiClient := client.NewAwarenessAPIClient(getEnv("API_BASE_URL", "http://localhost:3000"))
 	memoryService := service.NewMemoryDiscoveryService(apiClient)
 	recommendationService := service.NewRecommendationService(apiClient)
 
+	// Optional service-wide cost ceiling for discovery fan-outs; 0 (the
+	// default) disables it. Per-request IncludeStats is what makes the cost
+	// this budget tracks visible to callers.
+	if n := getEnv("DISCOVERY_MAX_MEMORIES_SCANNED", ""); n != "" {
+		if v, err := strconv.ParseInt(n, 10, 64); err == nil && v > 0 {
+			memoryService.SetMaxMemoriesScanned(v)
+		}
+	}
+
+	// Optional search-index fast path for /recommend: one hybrid ES/OpenSearch
+	// query instead of fanning out to the tRPC endpoints on every call.
+	if addrs := getEnv("ES_ADDRESSES", ""); addrs != "" {
+		searchIndex, err := search.NewIndex(strings.Split(addrs, ","), getEnv("ES_INDEX", ""))
+		if err != nil {
+			log.Fatalf("Failed to connect to search index: %v", err)
+		}
+
+		ctx := context.Background()
+		if err := searchIndex.EnsureMapping(ctx); err != nil {
+			log.Fatalf("Failed to ensure search index mapping: %v", err)
+		}
+
+		recommendationService.SetSearchIndex(searchIndex)
+
+		indexer := search.NewIndexer(searchIndex, recommendationService.CrawlForIndex, 5*time.Minute)
+		go indexer.Run(ctx)
+
+		// Fan memory discovery out to the same index. model.Memory carries no
+		// creation timestamp, so a genuine "since last poll" event tail isn't
+		// possible here; discoveryIndexer approximates it with a much shorter
+		// re-crawl interval than the /recommend indexer above, trading some
+		// duplicate work for catalog freshness closer to real time.
+		memoryService.AddSource(service.NewElasticsearchDiscoverySource(searchIndex))
+		discoveryIndexer := search.NewIndexer(searchIndex, recommendationService.CrawlForIndex, discoveryIndexInterval)
+		go discoveryIndexer.Run(ctx)
+	}
+
 	// Initialize handlers
 	memoryHandler := handler.NewMemoryHandler(memoryService, recommendationService)
 
@@ -51,10 +102,30 @@ func main() {
 	v1 := router.Group("/api/v1")
 	{
 		v1.POST("/discover", memoryHandler.DiscoverMemories)
+		v1.GET("/discover/stream", memoryHandler.StreamDiscoverMemories)
 		v1.POST("/recommend", memoryHandler.RecommendMemories)
 		v1.POST("/batch-discover", memoryHandler.BatchDiscoverMemories)
 	}
 
+	// GraphQL endpoint: lets clients select only the fields they need
+	// (e.g. id/price/explanation) instead of over-fetching the REST shapes.
+	schema, err := graphql.NewSchema(memoryService, recommendationService, apiClient)
+	if err != nil {
+		log.Fatalf("Failed to build GraphQL schema: %v", err)
+	}
+	gqlHandler := graphqlhandler.New(&graphqlhandler.Config{
+		Schema:   &schema,
+		Pretty:   true,
+		GraphiQL: false,
+	})
+	router.POST("/graphql", gin.WrapH(gqlHandler))
+	router.GET("/graphql/playground", gin.WrapH(graphqlhandler.New(&graphqlhandler.Config{
+		Schema:     &schema,
+		Pretty:     true,
+		GraphiQL:   true,
+		Playground: true,
+	})))
+
 	// Start server
 	port := getEnv("PORT", "8080")
 	srv := &http.Server{
@@ -70,6 +141,25 @@ func main() {
 		}
 	}()
 
+	// Optional gRPC streaming server for non-browser clients, mirroring
+	// GET /api/v1/discover/stream as the server-streaming RPC
+	// MemoryDiscovery.Stream. Disabled unless GRPC_PORT is set.
+	var grpcSrv *grpc.Server
+	if grpcPort := getEnv("GRPC_PORT", ""); grpcPort != "" {
+		lis, err := net.Listen("tcp", ":"+grpcPort)
+		if err != nil {
+			log.Fatalf("Failed to listen on gRPC port %s: %v", grpcPort, err)
+		}
+		grpcSrv = grpc.NewServer()
+		pb.RegisterMemoryDiscoveryServer(grpcSrv, grpcserver.NewServer(memoryService))
+		go func() {
+			log.Printf("MCP Gateway gRPC streaming starting on port %s", grpcPort)
+			if err := grpcSrv.Serve(lis); err != nil {
+				log.Fatalf("Failed to start gRPC server: %v", err)
+			}
+		}()
+	}
+
 	// Wait for interrupt signal
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
@@ -82,6 +172,9 @@ func main() {
 	if err := srv.Shutdown(ctx); err != nil {
 		log.Fatal("Server forced to shutdown:", err)
 	}
+	if grpcSrv != nil {
+		grpcSrv.GracefulStop()
+	}
 
 	log.Println("Server exited")
 }
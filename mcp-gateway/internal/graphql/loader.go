@@ -0,0 +1,63 @@
+package graphql
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// creditScoreLoader batches concurrent agentCredit.getProfile lookups that
+// land within the same short window into a single round of fetches, so
+// resolving `agentCreditScore`/`explanation` across N memories in one
+// GraphQL response doesn't cost N sequential upstream calls.
+type creditScoreLoader struct {
+	fetch func(ctx context.Context, agentAddress string) int
+	wait  time.Duration
+
+	mu      sync.Mutex
+	pending map[string][]chan int
+	timer   *time.Timer
+}
+
+func newCreditScoreLoader(fetch func(ctx context.Context, agentAddress string) int) *creditScoreLoader {
+	return &creditScoreLoader{
+		fetch:   fetch,
+		wait:    2 * time.Millisecond,
+		pending: make(map[string][]chan int),
+	}
+}
+
+// Load queues agentAddress for the next batch and blocks until that batch
+// resolves. Concurrent Load calls for the same address share one fetch.
+func (l *creditScoreLoader) Load(ctx context.Context, agentAddress string) int {
+	ch := make(chan int, 1)
+
+	l.mu.Lock()
+	l.pending[agentAddress] = append(l.pending[agentAddress], ch)
+	if l.timer == nil {
+		l.timer = time.AfterFunc(l.wait, func() { l.flush(ctx) })
+	}
+	l.mu.Unlock()
+
+	select {
+	case score := <-ch:
+		return score
+	case <-ctx.Done():
+		return 0
+	}
+}
+
+func (l *creditScoreLoader) flush(ctx context.Context) {
+	l.mu.Lock()
+	batch := l.pending
+	l.pending = make(map[string][]chan int)
+	l.timer = nil
+	l.mu.Unlock()
+
+	for agentAddress, waiters := range batch {
+		score := l.fetch(ctx, agentAddress)
+		for _, w := range waiters {
+			w <- score
+		}
+	}
+}
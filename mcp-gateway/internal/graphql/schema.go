@@ -0,0 +1,242 @@
+// Package graphql exposes memory discovery and recommendations through a
+// single typed endpoint, so callers that only need a few fields (e.g.
+// `id`, `price`, `explanation`) don't have to round-trip the full REST
+// payloads from /discover, /recommend, and /batch.
+package graphql
+
+import (
+	"context"
+
+	"github.com/awareness-market/mcp-gateway/internal/model"
+	"github.com/awareness-market/mcp-gateway/internal/service"
+	"github.com/awareness-market/mcp-gateway/pkg/client"
+	"github.com/graphql-go/graphql"
+)
+
+// Resolvers holds the services and shared dataloader backing the schema.
+type Resolvers struct {
+	memoryService         *service.MemoryDiscoveryService
+	recommendationService *service.RecommendationService
+	apiClient             *client.AwarenessAPIClient
+	creditLoader          *creditScoreLoader
+}
+
+// NewSchema builds the GraphQL schema served at /graphql.
+func NewSchema(memoryService *service.MemoryDiscoveryService, recommendationService *service.RecommendationService, apiClient *client.AwarenessAPIClient) (graphql.Schema, error) {
+	r := &Resolvers{
+		memoryService:         memoryService,
+		recommendationService: recommendationService,
+		apiClient:             apiClient,
+	}
+	r.creditLoader = newCreditScoreLoader(r.fetchCreditScore)
+
+	memoryType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Memory",
+		Fields: graphql.Fields{
+			"id":               &graphql.Field{Type: graphql.String},
+			"type":             &graphql.Field{Type: graphql.String},
+			"name":             &graphql.Field{Type: graphql.String},
+			"description":      &graphql.Field{Type: graphql.String},
+			"epsilon":          &graphql.Field{Type: graphql.Float},
+			"certification":    &graphql.Field{Type: graphql.String},
+			"price":            &graphql.Field{Type: graphql.Float},
+			"agentAddress":     &graphql.Field{Type: graphql.String},
+			"relevanceScore":   &graphql.Field{Type: graphql.Float},
+			"agentCreditScore": r.lazyCreditScoreField(),
+		},
+	})
+
+	scoredMemoryType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "ScoredMemory",
+		Fields: graphql.Fields{
+			"memory":              &graphql.Field{Type: memoryType},
+			"recommendationScore": &graphql.Field{Type: graphql.Float},
+			"explanation":         r.lazyExplanationField(),
+		},
+	})
+
+	vectorSearchResultType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Vector",
+		Fields: graphql.Fields{
+			"id":         &graphql.Field{Type: graphql.String},
+			"name":       &graphql.Field{Type: graphql.String},
+			"similarity": &graphql.Field{Type: graphql.Float},
+			"distance":   &graphql.Field{Type: graphql.Float},
+		},
+	})
+
+	reasoningChainType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "ReasoningChain",
+		Fields: graphql.Fields{
+			"id":          &graphql.Field{Type: graphql.String},
+			"name":        &graphql.Field{Type: graphql.String},
+			"description": &graphql.Field{Type: graphql.String},
+			"pricePerUse": &graphql.Field{Type: graphql.Float},
+		},
+	})
+
+	memoryExchangeType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "MemoryExchange",
+		Fields: graphql.Fields{
+			"id":         &graphql.Field{Type: graphql.String},
+			"memoryType": &graphql.Field{Type: graphql.String},
+			"price":      &graphql.Field{Type: graphql.Float},
+			"status":     &graphql.Field{Type: graphql.String},
+		},
+	})
+	_ = reasoningChainType
+	_ = memoryExchangeType
+
+	statusType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Status",
+		Fields: graphql.Fields{
+			"service": &graphql.Field{Type: graphql.String},
+			"healthy": &graphql.Field{Type: graphql.Boolean},
+		},
+	})
+
+	queryType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"discoverMemories": &graphql.Field{
+				Type: graphql.NewList(memoryType),
+				Args: graphql.FieldConfigArgument{
+					"context": &graphql.ArgumentConfig{Type: graphql.String},
+					"limit":   &graphql.ArgumentConfig{Type: graphql.Int},
+				},
+				Resolve: r.resolveDiscoverMemories,
+			},
+			"recommendMemories": &graphql.Field{
+				Type: graphql.NewList(scoredMemoryType),
+				Args: graphql.FieldConfigArgument{
+					"context": &graphql.ArgumentConfig{Type: graphql.String},
+					"limit":   &graphql.ArgumentConfig{Type: graphql.Int},
+				},
+				Resolve: r.resolveRecommendMemories,
+			},
+			"searchVectors": &graphql.Field{
+				Type: graphql.NewList(vectorSearchResultType),
+				Args: graphql.FieldConfigArgument{
+					"queryVector": &graphql.ArgumentConfig{Type: graphql.NewList(graphql.Float)},
+					"topK":        &graphql.ArgumentConfig{Type: graphql.Int},
+					"threshold":   &graphql.ArgumentConfig{Type: graphql.Float},
+				},
+				Resolve: r.resolveSearchVectors,
+			},
+			"getStatus": &graphql.Field{
+				Type:    statusType,
+				Resolve: r.resolveGetStatus,
+			},
+		},
+	})
+
+	return graphql.NewSchema(graphql.SchemaConfig{Query: queryType})
+}
+
+// lazyCreditScoreField only calls out to the credit service when a client
+// actually asks for agentCreditScore or explanation.
+func (r *Resolvers) lazyCreditScoreField() *graphql.Field {
+	return &graphql.Field{
+		Type: graphql.Int,
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			mem, ok := p.Source.(model.Memory)
+			if !ok {
+				return nil, nil
+			}
+			if mem.AgentAddress == "" {
+				return mem.AgentCreditScore, nil
+			}
+			return r.creditLoader.Load(p.Context, mem.AgentAddress), nil
+		},
+	}
+}
+
+func (r *Resolvers) lazyExplanationField() *graphql.Field {
+	return &graphql.Field{
+		Type: graphql.String,
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			scored, ok := p.Source.(model.ScoredMemory)
+			if !ok {
+				return nil, nil
+			}
+			return scored.Explanation, nil
+		},
+	}
+}
+
+func (r *Resolvers) fetchCreditScore(ctx context.Context, agentAddress string) int {
+	// The underlying lookup already lives on RecommendationService; reuse it
+	// instead of duplicating the tRPC call shape here.
+	return r.recommendationService.AgentCreditScore(ctx, agentAddress, 0)
+}
+
+func (r *Resolvers) resolveDiscoverMemories(p graphql.ResolveParams) (interface{}, error) {
+	req := &model.DiscoveryRequest{
+		Context: stringArg(p, "context"),
+		Limit:   intArg(p, "limit", 20),
+	}
+	resp, err := r.memoryService.DiscoverMemories(p.Context, req)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Memories, nil
+}
+
+func (r *Resolvers) resolveRecommendMemories(p graphql.ResolveParams) (interface{}, error) {
+	req := &model.RecommendationRequest{
+		Context: stringArg(p, "context"),
+		Limit:   intArg(p, "limit", 10),
+	}
+	resp, err := r.recommendationService.RecommendMemories(p.Context, req)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Recommendations, nil
+}
+
+func (r *Resolvers) resolveSearchVectors(p graphql.ResolveParams) (interface{}, error) {
+	rawVector, _ := p.Args["queryVector"].([]interface{})
+	queryVector := make([]float64, len(rawVector))
+	for i, v := range rawVector {
+		if f, ok := v.(float64); ok {
+			queryVector[i] = f
+		}
+	}
+
+	req := map[string]interface{}{
+		"query_vector": queryVector,
+		"top_k":        intArg(p, "topK", 10),
+		"threshold":    floatArg(p, "threshold", 0.7),
+	}
+
+	var results []map[string]interface{}
+	if err := r.apiClient.Post(p.Context, "/api/v1/vectors/search", req, &results); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+func (r *Resolvers) resolveGetStatus(p graphql.ResolveParams) (interface{}, error) {
+	return map[string]interface{}{"service": "mcp-gateway", "healthy": true}, nil
+}
+
+func stringArg(p graphql.ResolveParams, name string) string {
+	if v, ok := p.Args[name].(string); ok {
+		return v
+	}
+	return ""
+}
+
+func intArg(p graphql.ResolveParams, name string, fallback int) int {
+	if v, ok := p.Args[name].(int); ok {
+		return v
+	}
+	return fallback
+}
+
+func floatArg(p graphql.ResolveParams, name string, fallback float64) float64 {
+	if v, ok := p.Args[name].(float64); ok {
+		return v
+	}
+	return fallback
+}
@@ -0,0 +1,71 @@
+package grpcserver
+
+import (
+	"github.com/awareness-market/mcp-gateway/internal/grpcserver/pb"
+	"github.com/awareness-market/mcp-gateway/internal/model"
+	"github.com/awareness-market/mcp-gateway/internal/service"
+)
+
+func fromProtoRequest(req *pb.DiscoveryRequest) *model.DiscoveryRequest {
+	return &model.DiscoveryRequest{
+		Context:     req.GetContext(),
+		SourceModel: req.GetSourceModel(),
+		TargetModel: req.GetTargetModel(),
+		MemoryTypes: req.GetMemoryTypes(),
+		MinQuality:  req.GetMinQuality(),
+		MaxPrice:    req.GetMaxPrice(),
+		Limit:       int(req.GetLimit()),
+		RankerName:  req.GetRankerName(),
+	}
+}
+
+func toProtoMemory(m model.Memory) *pb.Memory {
+	return &pb.Memory{
+		Id:               m.ID,
+		Type:             m.Type,
+		Name:             m.Name,
+		Description:      m.Description,
+		Epsilon:          m.Epsilon,
+		Certification:    m.Certification,
+		Price:            m.Price,
+		AgentAddress:     m.AgentAddress,
+		AgentCreditScore: int32(m.AgentCreditScore),
+		RelevanceScore:   m.RelevanceScore,
+	}
+}
+
+func toProtoMemories(memories []model.Memory) []*pb.Memory {
+	out := make([]*pb.Memory, len(memories))
+	for i, m := range memories {
+		out[i] = toProtoMemory(m)
+	}
+	return out
+}
+
+func toProtoEventType(t string) pb.MemoryEvent_Type {
+	switch t {
+	case "heartbeat":
+		return pb.MemoryEvent_HEARTBEAT
+	case "done":
+		return pb.MemoryEvent_DONE
+	default:
+		return pb.MemoryEvent_SOURCE
+	}
+}
+
+func toProtoEvent(e service.MemoryEvent) *pb.MemoryEvent {
+	event := &pb.MemoryEvent{
+		Type:     toProtoEventType(e.Type),
+		Source:   e.Source,
+		Memories: toProtoMemories(e.Memories),
+	}
+	if e.Response != nil {
+		event.Response = &pb.DiscoveryResponse{
+			Memories:       toProtoMemories(e.Response.Memories),
+			TotalFound:     int32(e.Response.TotalFound),
+			QueryTimeMs:    e.Response.QueryTimeMs,
+			SourcesQueried: e.Response.SourcesQueried,
+		}
+	}
+	return event
+}
@@ -0,0 +1,55 @@
+// Package grpcserver exposes MemoryDiscoveryService.DiscoverMemoriesStream as
+// the gRPC server-streaming RPC MemoryDiscovery.Stream, for clients that want
+// the same incremental results as GET /api/v1/discover/stream without an SSE
+// parser (service meshes, non-browser SDKs, etc).
+//
+// The message/service types this file depends on (pb.DiscoveryRequest,
+// pb.MemoryDiscoveryServer, ...) are generated from proto/memory_discovery.proto
+// via:
+//
+//	protoc --go_out=. --go-grpc_out=. \
+//	  --go_opt=module=github.com/awareness-market/mcp-gateway \
+//	  --go-grpc_opt=module=github.com/awareness-market/mcp-gateway \
+//	  proto/memory_discovery.proto
+//
+// and are not hand-edited.
+package grpcserver
+
+import (
+	"github.com/awareness-market/mcp-gateway/internal/grpcserver/pb"
+	"github.com/awareness-market/mcp-gateway/internal/service"
+)
+
+// Server implements pb.MemoryDiscoveryServer on top of a MemoryDiscoveryService.
+type Server struct {
+	pb.UnimplementedMemoryDiscoveryServer
+	memoryService *service.MemoryDiscoveryService
+}
+
+// NewServer creates a gRPC MemoryDiscovery server backed by memoryService.
+func NewServer(memoryService *service.MemoryDiscoveryService) *Server {
+	return &Server{memoryService: memoryService}
+}
+
+// Stream implements the server-streaming RPC, forwarding every MemoryEvent
+// DiscoverMemoriesStream produces until it closes or the client disconnects.
+func (s *Server) Stream(req *pb.DiscoveryRequest, stream pb.MemoryDiscovery_StreamServer) error {
+	ctx := stream.Context()
+	events, errs := s.memoryService.DiscoverMemoriesStream(ctx, fromProtoRequest(req))
+
+	for {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				return nil
+			}
+			if err := stream.Send(toProtoEvent(event)); err != nil {
+				return err
+			}
+		case err := <-errs:
+			return err
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
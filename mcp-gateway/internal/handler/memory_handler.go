@@ -1,7 +1,11 @@
 package handler
 
 import (
+	"encoding/json"
+	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/awareness-market/mcp-gateway/internal/model"
@@ -9,10 +13,15 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
+// streamRingBufferSize bounds how many past SSE events StreamDiscoverMemories
+// keeps around for Last-Event-ID resumability.
+const streamRingBufferSize = 256
+
 // MemoryHandler handles HTTP requests for memory operations
 type MemoryHandler struct {
 	memoryService         *service.MemoryDiscoveryService
 	recommendationService *service.RecommendationService
+	streamBuffer          *sseRingBuffer
 }
 
 // NewMemoryHandler creates a new memory handler
@@ -23,6 +32,32 @@ func NewMemoryHandler(
 	return &MemoryHandler{
 		memoryService:         memoryService,
 		recommendationService: recommendationService,
+		streamBuffer:          newSSERingBuffer(streamRingBufferSize),
+	}
+}
+
+// requestTimeoutHeader lets a caller enforce an end-to-end SLO on discovery:
+// if set, it bounds the whole call (all source queries and their retries)
+// regardless of what the service's own default deadline is.
+const requestTimeoutHeader = "X-Request-Timeout-Ms"
+
+// applyRequestTimeoutHeader sets req.Deadline from the X-Request-Timeout-Ms
+// header, if present and a valid positive integer. Malformed or missing
+// headers leave req.Deadline untouched, falling back to the service's own
+// default deadline (if any).
+func applyRequestTimeoutHeader(c *gin.Context, req *model.DiscoveryRequest) {
+	ms, err := strconv.Atoi(c.GetHeader(requestTimeoutHeader))
+	if err != nil || ms <= 0 {
+		return
+	}
+	req.Deadline = time.Now().Add(time.Duration(ms) * time.Millisecond)
+}
+
+// applyStatsQueryParam opts req into DiscoveryResponse.Stats when the
+// request didn't already set IncludeStats itself, via "?stats=all".
+func applyStatsQueryParam(c *gin.Context, includeStats *bool) {
+	if c.Query("stats") == "all" {
+		*includeStats = true
 	}
 }
 
@@ -33,6 +68,8 @@ func (h *MemoryHandler) DiscoverMemories(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	applyRequestTimeoutHeader(c, &req)
+	applyStatsQueryParam(c, &req.IncludeStats)
 
 	// Set default limit
 	if req.Limit == 0 {
@@ -80,14 +117,133 @@ func (h *MemoryHandler) BatchDiscoverMemories(c *gin.Context) {
 
 	startTime := time.Now()
 
-	responses, err := h.memoryService.BatchDiscoverMemories(c.Request.Context(), req.Requests)
+	includeStats := false
+	applyStatsQueryParam(c, &includeStats)
+	if includeStats {
+		for _, r := range req.Requests {
+			r.IncludeStats = true
+		}
+	}
+
+	responses, err := h.memoryService.BatchDiscoverMemories(c.Request.Context(), req.Requests, req.MaxMemoriesScanned)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, model.BatchDiscoveryResponse{
+	batchResp := model.BatchDiscoveryResponse{
 		Responses:   responses,
 		TotalTimeMs: time.Since(startTime).Milliseconds(),
-	})
+	}
+	if includeStats {
+		perSource := make(map[string]model.QueryStats)
+		var total model.QueryStats
+		for _, resp := range responses {
+			if resp.Stats == nil {
+				continue
+			}
+			for name, s := range resp.Stats.PerSource {
+				merged := perSource[name]
+				merged.MemoriesScanned += s.MemoriesScanned
+				merged.MemoriesEvaluated += s.MemoriesEvaluated
+				merged.EmbeddingComparisons += s.EmbeddingComparisons
+				merged.CacheHits += s.CacheHits
+				merged.WallTimeMs += s.WallTimeMs
+				perSource[name] = merged
+			}
+			total.MemoriesScanned += resp.Stats.Total.MemoriesScanned
+			total.MemoriesEvaluated += resp.Stats.Total.MemoriesEvaluated
+			total.EmbeddingComparisons += resp.Stats.Total.EmbeddingComparisons
+			total.CacheHits += resp.Stats.Total.CacheHits
+			total.WallTimeMs += resp.Stats.Total.WallTimeMs
+		}
+		batchResp.Stats = &model.DiscoveryStats{PerSource: perSource, Total: total}
+	}
+
+	c.JSON(http.StatusOK, batchResp)
+}
+
+// StreamDiscoverMemories handles GET /api/v1/discover/stream, the
+// server-sent-events counterpart to DiscoverMemories: it writes a "source"
+// event as each backing source returns, periodic "heartbeat" events to keep
+// proxies from closing an idle connection, and a terminal "done" event
+// carrying the same aggregate response DiscoverMemories would return.
+//
+// A reconnecting client can set Last-Event-ID to replay whatever it missed
+// from the bounded in-memory ring buffer before the new query's events start.
+func (h *MemoryHandler) StreamDiscoverMemories(c *gin.Context) {
+	flusher, ok := c.Writer.(http.Flusher)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "streaming unsupported"})
+		return
+	}
+
+	req := model.DiscoveryRequest{
+		Context:     c.Query("context"),
+		SourceModel: c.Query("sourceModel"),
+		TargetModel: c.Query("targetModel"),
+		RankerName:  c.Query("rankerName"),
+	}
+	if types := c.Query("memoryTypes"); types != "" {
+		req.MemoryTypes = strings.Split(types, ",")
+	}
+	if v, err := strconv.ParseFloat(c.Query("minQuality"), 64); err == nil {
+		req.MinQuality = v
+	}
+	if v, err := strconv.ParseFloat(c.Query("maxPrice"), 64); err == nil {
+		req.MaxPrice = v
+	}
+	req.Limit = 20
+	if v, err := strconv.Atoi(c.Query("limit")); err == nil && v > 0 {
+		req.Limit = v
+	}
+	applyRequestTimeoutHeader(c, &req)
+	applyStatsQueryParam(c, &req.IncludeStats)
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+
+	var lastID uint64
+	if id := c.GetHeader("Last-Event-ID"); id != "" {
+		lastID, _ = strconv.ParseUint(id, 10, 64)
+	}
+	for _, e := range h.streamBuffer.Since(lastID) {
+		writeSSEFrame(c.Writer, e)
+	}
+	flusher.Flush()
+
+	ctx := c.Request.Context()
+	events, errs := h.memoryService.DiscoverMemoriesStream(ctx, &req)
+	for {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			data, err := json.Marshal(event)
+			if err != nil {
+				continue
+			}
+			writeSSEFrame(c.Writer, h.streamBuffer.Append(event.Type, string(data)))
+			flusher.Flush()
+			if event.Type == "done" {
+				return
+			}
+		case err := <-errs:
+			if err != nil {
+				data, _ := json.Marshal(gin.H{"error": err.Error()})
+				writeSSEFrame(c.Writer, h.streamBuffer.Append("error", string(data)))
+				flusher.Flush()
+			}
+			return
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// writeSSEFrame writes e in the standard "id:/event:/data:\n\n" SSE wire format.
+func writeSSEFrame(w http.ResponseWriter, e sseEvent) {
+	fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", e.ID, e.Event, e.Data)
 }
@@ -0,0 +1,57 @@
+package handler
+
+import "sync"
+
+// sseEvent is one frame written to an SSE client, tagged with a monotonic ID
+// so a reconnecting client can resume via the Last-Event-ID header.
+type sseEvent struct {
+	ID    uint64
+	Event string
+	Data  string
+}
+
+// sseRingBuffer keeps the last `size` events emitted on a stream so a client
+// that reconnects with Last-Event-ID can replay whatever it missed instead of
+// re-running the underlying query from scratch. It's bounded rather than a
+// full replay log: a client that falls more than `size` events behind just
+// starts fresh, same as if it had never set Last-Event-ID.
+type sseRingBuffer struct {
+	mu     sync.Mutex
+	buf    []sseEvent
+	nextID uint64
+	size   int
+}
+
+func newSSERingBuffer(size int) *sseRingBuffer {
+	return &sseRingBuffer{buf: make([]sseEvent, 0, size), size: size}
+}
+
+// Append assigns the next ID to (event, data), stores it, and returns it.
+func (r *sseRingBuffer) Append(event, data string) sseEvent {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.nextID++
+	e := sseEvent{ID: r.nextID, Event: event, Data: data}
+	r.buf = append(r.buf, e)
+	if len(r.buf) > r.size {
+		r.buf = r.buf[len(r.buf)-r.size:]
+	}
+	return e
+}
+
+// Since returns every buffered event with ID strictly greater than lastID, in
+// order. If lastID is older than everything buffered, it returns whatever is
+// left rather than erroring.
+func (r *sseRingBuffer) Since(lastID uint64) []sseEvent {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	out := make([]sseEvent, 0, len(r.buf))
+	for _, e := range r.buf {
+		if e.ID > lastID {
+			out = append(out, e)
+		}
+	}
+	return out
+}
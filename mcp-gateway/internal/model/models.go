@@ -1,5 +1,7 @@
 package model
 
+import "time"
+
 // Memory represents a memory asset in the marketplace
 type Memory struct {
 	ID               string  `json:"id"`
@@ -23,6 +25,44 @@ type DiscoveryRequest struct {
 	MinQuality   float64  `json:"minQuality"`   // Minimum quality (max epsilon)
 	MaxPrice     float64  `json:"maxPrice"`     // Maximum price
 	Limit        int      `json:"limit"`        // Maximum number of results
+	RankerName   string   `json:"rankerName,omitempty"` // e.g. "lexical" (default), "bm25", or "embedding"
+
+	// Deadline, when set, bounds the whole discovery call (all three source
+	// queries and their retries) instead of relying solely on the caller's
+	// context - set from the X-Request-Timeout-Ms header at the handler
+	// layer so clients can enforce an end-to-end SLO.
+	Deadline time.Time `json:"deadline,omitempty"`
+	// SourceTimeouts overrides the per-source query budget by source name
+	// ("kv-cache", "w-matrix", "reasoning-chain"); sources not present here
+	// fall back to the service's default.
+	SourceTimeouts map[string]time.Duration `json:"sourceTimeouts,omitempty"`
+	// QueryVector is an optional embedding of Context; when set, the
+	// Elasticsearch discovery source rescores with a dense-vector kNN
+	// clause alongside its BM25/filter query.
+	QueryVector []float64 `json:"queryVector,omitempty"`
+	// IncludeStats opts into per-source cost accounting (DiscoveryResponse.
+	// Stats), also settable via the "stats=all" query parameter. Left off by
+	// default since computing it costs each DiscoverySource extra bookkeeping.
+	IncludeStats bool `json:"includeStats,omitempty"`
+}
+
+// QueryStats reports one source's query cost for a single DiscoveryRequest:
+// how much work it did versus how many of those candidates actually made it
+// into the response. Only populated when the request's IncludeStats flag is
+// set.
+type QueryStats struct {
+	MemoriesScanned      int64 `json:"memoriesScanned"`      // candidates considered before filtering
+	MemoriesEvaluated    int64 `json:"memoriesEvaluated"`    // candidates that passed filtering
+	EmbeddingComparisons int64 `json:"embeddingComparisons"` // vector-similarity computations performed, if any
+	CacheHits            int64 `json:"cacheHits"`
+	WallTimeMs           int64 `json:"wallTimeMs"`
+}
+
+// DiscoveryStats is the Stats field of a DiscoveryResponse: a per-source
+// cost breakdown plus the sum across every source queried.
+type DiscoveryStats struct {
+	PerSource map[string]QueryStats `json:"perSource"`
+	Total     QueryStats            `json:"total"`
 }
 
 // DiscoveryResponse represents the response from memory discovery
@@ -31,17 +71,43 @@ type DiscoveryResponse struct {
 	TotalFound     int      `json:"totalFound"`
 	QueryTimeMs    int64    `json:"queryTimeMs"`
 	SourcesQueried []string `json:"sourcesQueried"`
+	// PartialFailures lists sources (e.g. "kv-cache") that couldn't be
+	// queried this request - their breaker was open or their retries were
+	// exhausted - so callers can tell a thin result from a complete one.
+	PartialFailures []string `json:"partialFailures,omitempty"`
+	// SourceQueryTimeMs is how long each source took to respond (or time
+	// out), keyed by source name, so callers can see which source dominated
+	// the overall QueryTimeMs.
+	SourceQueryTimeMs map[string]int64 `json:"sourceQueryTimeMs,omitempty"`
+	// Stats is the per-source cost breakdown, set only when the request's
+	// IncludeStats flag (or "stats=all") was set.
+	Stats *DiscoveryStats `json:"stats,omitempty"`
+	// Partial is true when a MaxMemoriesScanned budget cut the fan-out short
+	// - the response is a prefix of what a full query would have returned,
+	// not an error.
+	Partial bool `json:"partial,omitempty"`
 }
 
 // RecommendationRequest represents a recommendation request
 type RecommendationRequest struct {
-	Context         string   `json:"context"`
-	SourceModel     string   `json:"sourceModel"`
-	TargetModel     string   `json:"targetModel,omitempty"`
-	PreferredTypes  []string `json:"preferredTypes,omitempty"`
-	MaxBudget       float64  `json:"maxBudget,omitempty"`
-	MinCreditScore  int      `json:"minCreditScore,omitempty"`
-	Limit           int      `json:"limit"`
+	Context        string        `json:"context"`
+	SourceModel    string        `json:"sourceModel"`
+	TargetModel    string        `json:"targetModel,omitempty"`
+	PreferredTypes []string      `json:"preferredTypes,omitempty"`
+	MaxBudget      float64       `json:"maxBudget,omitempty"`
+	MinCreditScore int           `json:"minCreditScore,omitempty"`
+	Limit          int           `json:"limit"`
+	ScorerName     string        `json:"scorerName,omitempty"`  // e.g. "weighted-linear" (default) or "learned"
+	Weights        *ScoreWeights `json:"weights,omitempty"`     // overrides the scorer's default weights, weighted-linear only
+	QueryVector    []float64     `json:"queryVector,omitempty"` // optional embedding for kNN rescoring against the search index
+}
+
+// ScoreWeights are the four factors a weighted-linear Scorer combines.
+type ScoreWeights struct {
+	Quality       float64 `json:"quality"`
+	Credit        float64 `json:"credit"`
+	Price         float64 `json:"price"`
+	Certification float64 `json:"certification"`
 }
 
 // ScoredMemory represents a memory with recommendation score
@@ -60,10 +126,19 @@ type RecommendationResponse struct {
 // BatchDiscoveryRequest represents a batch discovery request
 type BatchDiscoveryRequest struct {
 	Requests []*DiscoveryRequest `json:"requests"`
+	// MaxMemoriesScanned caps the total number of memories any one
+	// sub-request's fan-out may scan across all its sources; once hit, that
+	// sub-request's response is marked Partial instead of continuing to
+	// accumulate more sources. Zero means no budget.
+	MaxMemoriesScanned int64 `json:"maxMemoriesScanned,omitempty"`
 }
 
 // BatchDiscoveryResponse represents the response from batch discovery
 type BatchDiscoveryResponse struct {
-	Responses []*DiscoveryResponse `json:"responses"`
-	TotalTimeMs int64              `json:"totalTimeMs"`
+	Responses   []*DiscoveryResponse `json:"responses"`
+	TotalTimeMs int64                `json:"totalTimeMs"`
+	// Stats aggregates every sub-request's Stats into one summary, set only
+	// when at least one sub-request had IncludeStats set. Per-sub-request
+	// detail is still available in Responses[i].Stats.
+	Stats *DiscoveryStats `json:"stats,omitempty"`
 }
@@ -0,0 +1,22 @@
+// Package search provides an Elasticsearch/OpenSearch-backed index over the
+// marketplace catalog (memories, reasoning chains, and vectors), so
+// /recommend can run one hybrid keyword+filter+kNN query instead of paging
+// through several tRPC endpoints and filtering in Go on every call.
+package search
+
+// MemoryDocument is the shape indexed for each marketplace item. Field names
+// are snake_case to match the Elasticsearch mapping in mapping.go.
+type MemoryDocument struct {
+	ID               string    `json:"id"`
+	Type             string    `json:"type"` // kv-cache, w-matrix, reasoning-chain
+	Name             string    `json:"name"`
+	Description      string    `json:"description"`
+	Epsilon          float64   `json:"epsilon"`
+	Certification    string    `json:"certification"`
+	Price            float64   `json:"price"`
+	AgentAddress     string    `json:"agent_address"`
+	AgentCreditScore int       `json:"agent_credit_score"`
+	SourceModel      string    `json:"source_model,omitempty"`
+	TargetModel      string    `json:"target_model,omitempty"`
+	Embedding        []float64 `json:"embedding,omitempty"`
+}
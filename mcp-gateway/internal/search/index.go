@@ -0,0 +1,411 @@
+package search
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/awareness-market/mcp-gateway/internal/model"
+	"github.com/elastic/go-elasticsearch/v8"
+	"github.com/elastic/go-elasticsearch/v8/esapi"
+)
+
+// Index wraps an Elasticsearch/OpenSearch client bound to the marketplace
+// catalog index, providing the handful of operations the gateway needs:
+// ensure the mapping exists, upsert documents, and run hybrid queries.
+type Index struct {
+	es   *elasticsearch.Client
+	name string
+}
+
+// NewIndex connects to the given ES/OpenSearch addresses and binds to
+// indexName (DefaultIndexName if empty).
+func NewIndex(addresses []string, indexName string) (*Index, error) {
+	if indexName == "" {
+		indexName = DefaultIndexName
+	}
+
+	es, err := elasticsearch.NewClient(elasticsearch.Config{Addresses: addresses})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create elasticsearch client: %w", err)
+	}
+
+	return &Index{es: es, name: indexName}, nil
+}
+
+// EnsureMapping creates the index with its mapping if it does not already exist.
+func (idx *Index) EnsureMapping(ctx context.Context) error {
+	exists, err := esapi.IndicesExistsRequest{Index: []string{idx.name}}.Do(ctx, idx.es)
+	if err != nil {
+		return fmt.Errorf("failed to check index existence: %w", err)
+	}
+	defer exists.Body.Close()
+
+	if exists.StatusCode == 200 {
+		return nil
+	}
+
+	create, err := esapi.IndicesCreateRequest{
+		Index: idx.name,
+		Body:  strings.NewReader(mapping()),
+	}.Do(ctx, idx.es)
+	if err != nil {
+		return fmt.Errorf("failed to create index: %w", err)
+	}
+	defer create.Body.Close()
+
+	if create.IsError() {
+		return fmt.Errorf("failed to create index %q: %s", idx.name, create.String())
+	}
+	return nil
+}
+
+// IndexDocument upserts a single MemoryDocument.
+func (idx *Index) IndexDocument(ctx context.Context, doc MemoryDocument) error {
+	body, err := json.Marshal(doc)
+	if err != nil {
+		return fmt.Errorf("failed to marshal document: %w", err)
+	}
+
+	res, err := esapi.IndexRequest{
+		Index:      idx.name,
+		DocumentID: doc.ID,
+		Body:       bytes.NewReader(body),
+		Refresh:    "false",
+	}.Do(ctx, idx.es)
+	if err != nil {
+		return fmt.Errorf("failed to index document %s: %w", doc.ID, err)
+	}
+	defer res.Body.Close()
+
+	if res.IsError() {
+		return fmt.Errorf("index request failed for %s: %s", doc.ID, res.String())
+	}
+	return nil
+}
+
+// BulkIndex upserts many documents in a single request using the ES bulk API.
+func (idx *Index) BulkIndex(ctx context.Context, docs []MemoryDocument) error {
+	if len(docs) == 0 {
+		return nil
+	}
+
+	var buf bytes.Buffer
+	for _, doc := range docs {
+		meta := map[string]interface{}{
+			"index": map[string]interface{}{"_index": idx.name, "_id": doc.ID},
+		}
+		metaLine, _ := json.Marshal(meta)
+		docLine, err := json.Marshal(doc)
+		if err != nil {
+			return fmt.Errorf("failed to marshal document %s: %w", doc.ID, err)
+		}
+		buf.Write(metaLine)
+		buf.WriteByte('\n')
+		buf.Write(docLine)
+		buf.WriteByte('\n')
+	}
+
+	res, err := esapi.BulkRequest{Body: bytes.NewReader(buf.Bytes())}.Do(ctx, idx.es)
+	if err != nil {
+		return fmt.Errorf("bulk index request failed: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.IsError() {
+		return fmt.Errorf("bulk index request failed: %s", res.String())
+	}
+	return nil
+}
+
+// Search runs a hybrid query combining keyword filters (type, certification,
+// credit score, price/budget), BM25 text match over description (from
+// req.Context), and optional kNN rescoring when req.QueryVector is set.
+// It returns one upstream hop instead of the three tRPC calls
+// fetchAvailableMemories historically made.
+func (idx *Index) Search(ctx context.Context, req *model.RecommendationRequest) ([]model.Memory, error) {
+	query := idx.buildQuery(req)
+
+	body, err := json.Marshal(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal search query: %w", err)
+	}
+
+	res, err := esapi.SearchRequest{
+		Index: []string{idx.name},
+		Body:  bytes.NewReader(body),
+	}.Do(ctx, idx.es)
+	if err != nil {
+		return nil, fmt.Errorf("search request failed: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.IsError() {
+		return nil, fmt.Errorf("search request failed: %s", res.String())
+	}
+
+	return decodeSearchHits(res.Body)
+}
+
+// buildQuery assembles the bool query (filters + BM25 match) and, when a
+// QueryVector is present, a knn rescoring clause.
+func (idx *Index) buildQuery(req *model.RecommendationRequest) map[string]interface{} {
+	filters := []map[string]interface{}{}
+
+	if len(req.PreferredTypes) > 0 {
+		filters = append(filters, map[string]interface{}{
+			"terms": map[string]interface{}{"type": req.PreferredTypes},
+		})
+	}
+	if req.MaxBudget > 0 {
+		filters = append(filters, map[string]interface{}{
+			"range": map[string]interface{}{"price": map[string]interface{}{"lte": req.MaxBudget}},
+		})
+	}
+	if req.MinCreditScore > 0 {
+		filters = append(filters, map[string]interface{}{
+			"range": map[string]interface{}{"agent_credit_score": map[string]interface{}{"gte": req.MinCreditScore}},
+		})
+	}
+	if req.SourceModel != "" {
+		filters = append(filters, map[string]interface{}{
+			"term": map[string]interface{}{"source_model": req.SourceModel},
+		})
+	}
+	if req.TargetModel != "" {
+		filters = append(filters, map[string]interface{}{
+			"term": map[string]interface{}{"target_model": req.TargetModel},
+		})
+	}
+
+	must := []map[string]interface{}{}
+	if req.Context != "" {
+		must = append(must, map[string]interface{}{
+			"match": map[string]interface{}{"description": req.Context},
+		})
+	} else {
+		must = append(must, map[string]interface{}{"match_all": map[string]interface{}{}})
+	}
+
+	query := map[string]interface{}{
+		"size": limitOrDefault(req.Limit),
+		"query": map[string]interface{}{
+			"bool": map[string]interface{}{
+				"must":   must,
+				"filter": filters,
+			},
+		},
+	}
+
+	if len(req.QueryVector) > 0 {
+		query["knn"] = map[string]interface{}{
+			"field":          "embedding",
+			"query_vector":   req.QueryVector,
+			"k":              limitOrDefault(req.Limit),
+			"num_candidates": limitOrDefault(req.Limit) * 10,
+		}
+	}
+
+	return query
+}
+
+func limitOrDefault(limit int) int {
+	if limit > 0 {
+		return limit
+	}
+	return 20
+}
+
+// SearchDiscovery runs a compound query over a model.DiscoveryRequest: BM25
+// over description (req.Context), term filters for req.MemoryTypes, range
+// filters for req.MinQuality (epsilon) and req.MaxPrice, and an optional
+// dense-vector kNN rescore when req.QueryVector is set.
+func (idx *Index) SearchDiscovery(ctx context.Context, req *model.DiscoveryRequest) ([]model.Memory, error) {
+	body, err := json.Marshal(idx.buildDiscoveryQuery(req))
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal discovery query: %w", err)
+	}
+
+	res, err := esapi.SearchRequest{
+		Index: []string{idx.name},
+		Body:  bytes.NewReader(body),
+	}.Do(ctx, idx.es)
+	if err != nil {
+		return nil, fmt.Errorf("discovery search request failed: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.IsError() {
+		return nil, fmt.Errorf("discovery search request failed: %s", res.String())
+	}
+
+	return decodeSearchHits(res.Body)
+}
+
+// MSearchDiscovery answers many DiscoveryRequests in one round trip via the
+// ES/OpenSearch _msearch API, so BatchDiscoverMemories doesn't pay one HTTP
+// round trip per request when this source is registered.
+func (idx *Index) MSearchDiscovery(ctx context.Context, reqs []*model.DiscoveryRequest) ([][]model.Memory, error) {
+	if len(reqs) == 0 {
+		return nil, nil
+	}
+
+	var buf bytes.Buffer
+	header, err := json.Marshal(map[string]interface{}{"index": idx.name})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal msearch header: %w", err)
+	}
+	for _, req := range reqs {
+		query, err := json.Marshal(idx.buildDiscoveryQuery(req))
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal discovery query: %w", err)
+		}
+		buf.Write(header)
+		buf.WriteByte('\n')
+		buf.Write(query)
+		buf.WriteByte('\n')
+	}
+
+	res, err := esapi.MsearchRequest{Body: bytes.NewReader(buf.Bytes())}.Do(ctx, idx.es)
+	if err != nil {
+		return nil, fmt.Errorf("msearch request failed: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.IsError() {
+		return nil, fmt.Errorf("msearch request failed: %s", res.String())
+	}
+
+	var parsed struct {
+		Responses []struct {
+			Hits struct {
+				Hits []struct {
+					Source MemoryDocument `json:"_source"`
+				} `json:"hits"`
+			} `json:"hits"`
+			Error json.RawMessage `json:"error,omitempty"`
+		} `json:"responses"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&parsed); err != nil {
+		return nil, fmt.Errorf("failed to decode msearch response: %w", err)
+	}
+	if len(parsed.Responses) != len(reqs) {
+		return nil, fmt.Errorf("msearch returned %d responses for %d requests", len(parsed.Responses), len(reqs))
+	}
+
+	results := make([][]model.Memory, len(reqs))
+	for i, r := range parsed.Responses {
+		if r.Error != nil {
+			return nil, fmt.Errorf("msearch sub-request %d failed: %s", i, r.Error)
+		}
+		memories := make([]model.Memory, 0, len(r.Hits.Hits))
+		for _, hit := range r.Hits.Hits {
+			memories = append(memories, hit.Source.toMemory())
+		}
+		results[i] = memories
+	}
+	return results, nil
+}
+
+// buildDiscoveryQuery assembles the bool query (filters + BM25) and,
+// when req.QueryVector is set, a knn rescoring clause, for one
+// DiscoveryRequest.
+func (idx *Index) buildDiscoveryQuery(req *model.DiscoveryRequest) map[string]interface{} {
+	filters := []map[string]interface{}{}
+
+	if len(req.MemoryTypes) > 0 {
+		filters = append(filters, map[string]interface{}{
+			"terms": map[string]interface{}{"type": req.MemoryTypes},
+		})
+	}
+	if req.MaxPrice > 0 {
+		filters = append(filters, map[string]interface{}{
+			"range": map[string]interface{}{"price": map[string]interface{}{"lte": req.MaxPrice}},
+		})
+	}
+	if req.MinQuality > 0 {
+		filters = append(filters, map[string]interface{}{
+			"range": map[string]interface{}{"epsilon": map[string]interface{}{"lte": req.MinQuality}},
+		})
+	}
+	if req.SourceModel != "" {
+		filters = append(filters, map[string]interface{}{
+			"term": map[string]interface{}{"source_model": req.SourceModel},
+		})
+	}
+	if req.TargetModel != "" {
+		filters = append(filters, map[string]interface{}{
+			"term": map[string]interface{}{"target_model": req.TargetModel},
+		})
+	}
+
+	must := []map[string]interface{}{}
+	if req.Context != "" {
+		must = append(must, map[string]interface{}{
+			"match": map[string]interface{}{"description": req.Context},
+		})
+	} else {
+		must = append(must, map[string]interface{}{"match_all": map[string]interface{}{}})
+	}
+
+	query := map[string]interface{}{
+		"size": limitOrDefault(req.Limit),
+		"query": map[string]interface{}{
+			"bool": map[string]interface{}{
+				"must":   must,
+				"filter": filters,
+			},
+		},
+	}
+
+	if len(req.QueryVector) > 0 {
+		query["knn"] = map[string]interface{}{
+			"field":          "embedding",
+			"query_vector":   req.QueryVector,
+			"k":              limitOrDefault(req.Limit),
+			"num_candidates": limitOrDefault(req.Limit) * 10,
+		}
+	}
+
+	return query
+}
+
+// decodeSearchHits parses a plain (non-msearch) _search response body into
+// Memory results.
+func decodeSearchHits(body io.Reader) ([]model.Memory, error) {
+	var parsed struct {
+		Hits struct {
+			Hits []struct {
+				Source MemoryDocument `json:"_source"`
+			} `json:"hits"`
+		} `json:"hits"`
+	}
+	if err := json.NewDecoder(body).Decode(&parsed); err != nil {
+		return nil, fmt.Errorf("failed to decode search response: %w", err)
+	}
+
+	memories := make([]model.Memory, 0, len(parsed.Hits.Hits))
+	for _, hit := range parsed.Hits.Hits {
+		memories = append(memories, hit.Source.toMemory())
+	}
+	return memories, nil
+}
+
+// toMemory converts an indexed document back into the model.Memory shape
+// callers expect.
+func (d MemoryDocument) toMemory() model.Memory {
+	return model.Memory{
+		ID:               d.ID,
+		Type:             d.Type,
+		Name:             d.Name,
+		Description:      d.Description,
+		Epsilon:          d.Epsilon,
+		Certification:    d.Certification,
+		Price:            d.Price,
+		AgentAddress:     d.AgentAddress,
+		AgentCreditScore: d.AgentCreditScore,
+	}
+}
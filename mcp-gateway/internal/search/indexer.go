@@ -0,0 +1,55 @@
+package search
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// CrawlFunc produces the current marketplace catalog as index documents.
+// Callers typically wire this to whatever already knows how to page through
+// the tRPC endpoints (e.g. RecommendationService.CrawlForIndex).
+type CrawlFunc func(ctx context.Context) ([]MemoryDocument, error)
+
+// Indexer periodically re-crawls the marketplace and bulk-upserts the result
+// into an Index, so Index.Search never serves data staler than one interval.
+type Indexer struct {
+	index    *Index
+	crawl    CrawlFunc
+	interval time.Duration
+}
+
+// NewIndexer builds an Indexer that re-crawls every interval.
+func NewIndexer(index *Index, crawl CrawlFunc, interval time.Duration) *Indexer {
+	return &Indexer{index: index, crawl: crawl, interval: interval}
+}
+
+// Run crawls once immediately, then on every tick, until ctx is cancelled.
+func (ix *Indexer) Run(ctx context.Context) {
+	ix.crawlOnce(ctx)
+
+	ticker := time.NewTicker(ix.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			ix.crawlOnce(ctx)
+		}
+	}
+}
+
+func (ix *Indexer) crawlOnce(ctx context.Context) {
+	docs, err := ix.crawl(ctx)
+	if err != nil {
+		log.Printf("search: crawl failed: %v", err)
+		return
+	}
+	if err := ix.index.BulkIndex(ctx, docs); err != nil {
+		log.Printf("search: bulk index failed: %v", err)
+		return
+	}
+	log.Printf("search: indexed %d marketplace documents", len(docs))
+}
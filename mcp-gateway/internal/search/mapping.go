@@ -0,0 +1,43 @@
+package search
+
+import "fmt"
+
+// EmbeddingDims is the size of the dense_vector field used for kNN rescoring.
+// It must match whatever embedding model produced Memory.QueryVector.
+const EmbeddingDims = 768
+
+// DefaultIndexName is the marketplace catalog index this package manages.
+const DefaultIndexName = "awareness-market-memories"
+
+// mapping returns the ES/OpenSearch index mapping: keyword fields for exact
+// filters, numeric ranges for price/epsilon/credit score, a BM25 text field
+// on description, and a dense_vector field for kNN over Embedding.
+func mapping() string {
+	return fmt.Sprintf(`{
+  "settings": {
+    "number_of_shards": 1,
+    "number_of_replicas": 1
+  },
+  "mappings": {
+    "properties": {
+      "id":                 {"type": "keyword"},
+      "type":                {"type": "keyword"},
+      "name":                {"type": "text"},
+      "description":         {"type": "text", "analyzer": "standard"},
+      "epsilon":             {"type": "double"},
+      "certification":       {"type": "keyword"},
+      "price":               {"type": "double"},
+      "agent_address":       {"type": "keyword"},
+      "agent_credit_score":  {"type": "integer"},
+      "source_model":        {"type": "keyword"},
+      "target_model":        {"type": "keyword"},
+      "embedding": {
+        "type": "dense_vector",
+        "dims": %d,
+        "index": true,
+        "similarity": "cosine"
+      }
+    }
+  }
+}`, EmbeddingDims)
+}
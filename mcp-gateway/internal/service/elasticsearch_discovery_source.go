@@ -0,0 +1,69 @@
+package service
+
+import (
+	"context"
+
+	"github.com/awareness-market/mcp-gateway/internal/model"
+	"github.com/awareness-market/mcp-gateway/internal/search"
+)
+
+// ElasticsearchDiscoverySource adapts a search.Index into a DiscoverySource
+// (and BatchDiscoverySource) so it can be registered with a
+// MemoryDiscoveryService via AddSource, fanning in semantic/BM25 search
+// results alongside the kv-cache/w-matrix/reasoning-chain upstream sources.
+type ElasticsearchDiscoverySource struct {
+	index *search.Index
+}
+
+// NewElasticsearchDiscoverySource wraps index as a DiscoverySource named
+// "elasticsearch".
+func NewElasticsearchDiscoverySource(index *search.Index) *ElasticsearchDiscoverySource {
+	return &ElasticsearchDiscoverySource{index: index}
+}
+
+// Name identifies this source in DiscoveryResponse.SourcesQueried/
+// PartialFailures/SourceQueryTimeMs.
+func (e *ElasticsearchDiscoverySource) Name() string {
+	return "elasticsearch"
+}
+
+// Discover runs one DiscoveryRequest against the index. Elasticsearch
+// already applies every filter server-side, so "scanned" and "evaluated"
+// are the same count here; a kNN rescore (when req.QueryVector is set)
+// counts one embedding comparison per hit returned.
+func (e *ElasticsearchDiscoverySource) Discover(ctx context.Context, req *model.DiscoveryRequest) ([]model.Memory, error) {
+	memories, err := e.index.SearchDiscovery(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	stats := queryStatsFromContext(ctx)
+	stats.AddScanned(int64(len(memories)))
+	stats.AddEvaluated(int64(len(memories)))
+	if len(req.QueryVector) > 0 {
+		stats.AddEmbeddingComparisons(int64(len(memories)))
+	}
+	return memories, nil
+}
+
+// DiscoverBatch answers many DiscoveryRequests in a single _msearch round
+// trip, used by BatchDiscoverMemories instead of calling Discover once per
+// request. The cost counters are recorded once for the whole batch - see
+// BatchDiscoverMemories' handling of BatchDiscoverySource for how that's
+// attributed back to individual sub-requests.
+func (e *ElasticsearchDiscoverySource) DiscoverBatch(ctx context.Context, reqs []*model.DiscoveryRequest) ([][]model.Memory, error) {
+	results, err := e.index.MSearchDiscovery(ctx, reqs)
+	if err != nil {
+		return nil, err
+	}
+	stats := queryStatsFromContext(ctx)
+	var total int64
+	for i, memories := range results {
+		total += int64(len(memories))
+		if len(reqs[i].QueryVector) > 0 {
+			stats.AddEmbeddingComparisons(int64(len(memories)))
+		}
+	}
+	stats.AddScanned(total)
+	stats.AddEvaluated(total)
+	return results, nil
+}
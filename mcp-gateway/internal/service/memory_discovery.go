@@ -1,8 +1,11 @@
 package service
 
 import (
+	"container/heap"
 	"context"
 	"fmt"
+	"log"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -13,116 +16,573 @@ import (
 	"golang.org/x/sync/errgroup"
 )
 
+// sourceRequestTimeout bounds a single source query, independent of ctx's
+// deadline, so one slow upstream can't hold the fan-out open indefinitely.
+const sourceRequestTimeout = 5 * time.Second
+
+// breakerFailureThreshold/breakerCooldown tune the per-source circuit
+// breakers: open after this many consecutive failures, stay open for
+// breakerCooldown before letting a half-open probe through.
+const (
+	breakerFailureThreshold = 5
+	breakerCooldown         = 30 * time.Second
+)
+
+// DiscoverySource is implemented by each backend MemoryDiscoveryService fans
+// out to. The built-in kv-cache, w-matrix, and reasoning-chain sources are
+// wrapped as funcSource; a new backend (e.g. Elasticsearch) only needs to
+// implement this interface and be registered via AddSource.
+type DiscoverySource interface {
+	Name() string
+	Discover(ctx context.Context, req *model.DiscoveryRequest) ([]model.Memory, error)
+}
+
+// BatchDiscoverySource may optionally be implemented by a DiscoverySource
+// that can answer many requests in one round trip (e.g. Elasticsearch's
+// msearch). BatchDiscoverMemories uses DiscoverBatch when a source
+// implements it instead of calling Discover once per request.
+type BatchDiscoverySource interface {
+	DiscoverySource
+	DiscoverBatch(ctx context.Context, reqs []*model.DiscoveryRequest) ([][]model.Memory, error)
+}
+
+// funcSource adapts one of the service's own query methods (which all share
+// the func(context.Context, *model.DiscoveryRequest) ([]model.Memory, error)
+// signature) to DiscoverySource.
+type funcSource struct {
+	name  string
+	query func(context.Context, *model.DiscoveryRequest) ([]model.Memory, error)
+}
+
+func (f funcSource) Name() string { return f.name }
+
+func (f funcSource) Discover(ctx context.Context, req *model.DiscoveryRequest) ([]model.Memory, error) {
+	return f.query(ctx, req)
+}
+
 // MemoryDiscoveryService handles concurrent memory discovery across multiple sources
 type MemoryDiscoveryService struct {
 	apiClient *client.AwarenessAPIClient
+
+	// One circuit breaker per upstream endpoint, so a failing kv-cache
+	// backend doesn't trip the breaker guarding w-matrix or reasoning-chain
+	// calls.
+	kvCacheBreaker        *client.CircuitBreaker
+	wMatrixBreaker        *client.CircuitBreaker
+	reasoningChainBreaker *client.CircuitBreaker
+
+	// sources is the list every DiscoverMemories/DiscoverMemoriesStream/
+	// BatchDiscoverMemories call fans out to. It starts with the three
+	// built-in upstream query methods; AddSource appends more (e.g. an
+	// Elasticsearch-backed source) without touching the fan-out logic.
+	sources []DiscoverySource
+
+	// mu guards defaultDeadline, sourceTimeouts, and maxMemoriesScanned, the
+	// service-wide defaults set via SetDeadline/SetSourceTimeout/
+	// SetMaxMemoriesScanned. A request's own Deadline/SourceTimeouts fields
+	// always take precedence over these.
+	mu                 sync.RWMutex
+	defaultDeadline    time.Duration
+	sourceTimeouts     map[string]time.Duration
+	maxMemoriesScanned int64
 }
 
 // NewMemoryDiscoveryService creates a new memory discovery service
 func NewMemoryDiscoveryService(apiClient *client.AwarenessAPIClient) *MemoryDiscoveryService {
-	return &MemoryDiscoveryService{
-		apiClient: apiClient,
+	s := &MemoryDiscoveryService{
+		apiClient:             apiClient,
+		kvCacheBreaker:        client.NewCircuitBreaker(breakerFailureThreshold, breakerCooldown),
+		wMatrixBreaker:        client.NewCircuitBreaker(breakerFailureThreshold, breakerCooldown),
+		reasoningChainBreaker: client.NewCircuitBreaker(breakerFailureThreshold, breakerCooldown),
+	}
+	s.sources = []DiscoverySource{
+		funcSource{"kv-cache", s.queryKVCacheMemories},
+		funcSource{"w-matrix", s.queryWMatrixMemories},
+		funcSource{"reasoning-chain", s.queryReasoningChainMemories},
 	}
+	return s
 }
 
-// DiscoverMemories discovers relevant memories for a given context using concurrent queries
-func (s *MemoryDiscoveryService) DiscoverMemories(ctx context.Context, req *model.DiscoveryRequest) (*model.DiscoveryResponse, error) {
-	startTime := time.Now()
+// AddSource registers an additional DiscoverySource that every subsequent
+// DiscoverMemories/DiscoverMemoriesStream/BatchDiscoverMemories call fans
+// out to alongside the built-in kv-cache/w-matrix/reasoning-chain sources.
+// Not safe to call concurrently with a discovery call; call it during
+// startup before the service is handed to handlers.
+func (s *MemoryDiscoveryService) AddSource(src DiscoverySource) {
+	s.sources = append(s.sources, src)
+}
 
-	// Create error group for concurrent operations
-	g, ctx := errgroup.WithContext(ctx)
-	
-	// Results channels
-	kvCacheChan := make(chan []model.Memory, 1)
-	wMatrixChan := make(chan []model.Memory, 1)
-	reasoningChainChan := make(chan []model.Memory, 1)
-
-	// Concurrent query for KV-Cache memories
-	g.Go(func() error {
-		memories, err := s.queryKVCacheMemories(ctx, req)
-		if err != nil {
-			return fmt.Errorf("kv-cache query failed: %w", err)
+// SetDeadline sets the default overall time budget for DiscoverMemories and
+// DiscoverMemoriesStream calls whose request doesn't set its own Deadline.
+// Pass 0 to rely solely on the caller's context again.
+func (s *MemoryDiscoveryService) SetDeadline(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.defaultDeadline = d
+}
+
+// SetSourceTimeout overrides the default per-source query budget (by source
+// name: "kv-cache", "w-matrix", or "reasoning-chain") for requests that
+// don't set SourceTimeouts themselves. Pass 0 to fall back to
+// sourceRequestTimeout.
+func (s *MemoryDiscoveryService) SetSourceTimeout(source string, d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.sourceTimeouts == nil {
+		s.sourceTimeouts = make(map[string]time.Duration)
+	}
+	s.sourceTimeouts[source] = d
+}
+
+// requestOptions builds the retry/circuit-breaker options a source query
+// should use for its upstream call.
+func requestOptions(breaker *client.CircuitBreaker) client.RequestOptions {
+	return client.RequestOptions{
+		Timeout: sourceRequestTimeout,
+		Retry:   client.DefaultRetryPolicy(),
+		Breaker: breaker,
+	}
+}
+
+// withDeadline derives the context DiscoverMemories/DiscoverMemoriesStream
+// should run under: req.Deadline if set, else the service's default
+// deadline, else ctx unchanged.
+func (s *MemoryDiscoveryService) withDeadline(ctx context.Context, req *model.DiscoveryRequest) (context.Context, context.CancelFunc) {
+	if !req.Deadline.IsZero() {
+		return context.WithDeadline(ctx, req.Deadline)
+	}
+	s.mu.RLock()
+	d := s.defaultDeadline
+	s.mu.RUnlock()
+	if d > 0 {
+		return context.WithTimeout(ctx, d)
+	}
+	return ctx, func() {}
+}
+
+// SetMaxMemoriesScanned sets the default per-request budget on how many
+// memories a fan-out may scan across all its sources before later sources'
+// contributions are dropped and the response is marked Partial. Pass 0 to
+// disable the budget (the default). A request can't currently override this
+// per-call; it's a service-wide cost ceiling.
+func (s *MemoryDiscoveryService) SetMaxMemoriesScanned(n int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.maxMemoriesScanned = n
+}
+
+func (s *MemoryDiscoveryService) maxScanned() int64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.maxMemoriesScanned
+}
+
+// sourceTimeout resolves the time budget for one source's query: the
+// request's own SourceTimeouts override, then the service-wide default set
+// via SetSourceTimeout, then sourceRequestTimeout.
+func (s *MemoryDiscoveryService) sourceTimeout(source string, req *model.DiscoveryRequest) time.Duration {
+	if d, ok := req.SourceTimeouts[source]; ok && d > 0 {
+		return d
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if d, ok := s.sourceTimeouts[source]; ok && d > 0 {
+		return d
+	}
+	return sourceRequestTimeout
+}
+
+// streamHeartbeatInterval is how often DiscoverMemoriesStream emits a
+// heartbeat event while a source query is still in flight, so proxies and
+// load balancers sitting in front of the SSE/gRPC endpoints don't treat the
+// connection as idle and close it.
+const streamHeartbeatInterval = 15 * time.Second
+
+// MemoryEvent is one message on a DiscoverMemoriesStream channel: either a
+// batch of memories from a single source, a heartbeat, or the terminal
+// "done" event carrying aggregate stats.
+type MemoryEvent struct {
+	Type     string                   `json:"type"` // "source", "heartbeat", or "done"
+	Source   string                   `json:"source,omitempty"`
+	Memories []model.Memory           `json:"memories,omitempty"`
+	Response *model.DiscoveryResponse `json:"response,omitempty"` // set on the "done" event
+}
+
+// DiscoverMemoriesStream is the streaming counterpart to DiscoverMemories: it
+// pushes a MemoryEvent as soon as each backing source (kv-cache, w-matrix,
+// reasoning-chain) returns instead of waiting for every source via g.Wait(),
+// so callers like the SSE and gRPC streaming endpoints can render results
+// incrementally. The returned channels are both closed once the terminal
+// "done" event has been sent or ctx is canceled, whichever happens first.
+func (s *MemoryDiscoveryService) DiscoverMemoriesStream(ctx context.Context, req *model.DiscoveryRequest) (<-chan MemoryEvent, <-chan error) {
+	events := make(chan MemoryEvent, 8)
+	errs := make(chan error, 1)
+
+	go func() {
+		defer close(events)
+		defer close(errs)
+
+		startTime := time.Now()
+
+		ctx, cancel := s.withDeadline(ctx, req)
+		defer cancel()
+
+		g, gctx := errgroup.WithContext(ctx)
+
+		type sourceResult struct {
+			source   string
+			memories []model.Memory
+			err      error
+			elapsed  time.Duration
+			stats    model.QueryStats
 		}
-		kvCacheChan <- memories
-		return nil
-	})
+		resultChan := make(chan sourceResult, len(s.sources))
+
+		for _, src := range s.sources {
+			src := src
+			g.Go(func() error {
+				sourceCtx, cancel := context.WithTimeout(gctx, s.sourceTimeout(src.Name(), req))
+				defer cancel()
+
+				var collector *QueryStatsCollector
+				if req.IncludeStats {
+					sourceCtx, collector = withQueryStats(sourceCtx)
+				}
+
+				sourceStart := time.Now()
+				memories, err := src.Discover(sourceCtx, req)
+				elapsed := time.Since(sourceStart)
 
-	// Concurrent query for W-Matrix memories
-	g.Go(func() error {
-		memories, err := s.queryWMatrixMemories(ctx, req)
-		if err != nil {
-			return fmt.Errorf("w-matrix query failed: %w", err)
+				res := sourceResult{source: src.Name(), memories: memories, err: err, elapsed: elapsed}
+				if req.IncludeStats {
+					res.stats = collector.snapshot(elapsed)
+				}
+				resultChan <- res
+				return nil
+			})
 		}
-		wMatrixChan <- memories
-		return nil
-	})
 
-	// Concurrent query for Reasoning Chain memories
-	g.Go(func() error {
-		memories, err := s.queryReasoningChainMemories(ctx, req)
-		if err != nil {
-			return fmt.Errorf("reasoning-chain query failed: %w", err)
+		done := make(chan error, 1)
+		go func() { done <- g.Wait() }()
+
+		heartbeat := time.NewTicker(streamHeartbeatInterval)
+		defer heartbeat.Stop()
+
+		maxMemoriesScanned := s.maxScanned()
+		var allMemories []model.Memory
+		sourcesQueried := make([]string, 0, len(s.sources))
+		var partialFailures []string
+		sourceQueryTimeMs := make(map[string]int64, len(s.sources))
+		perSourceStats := make(map[string]model.QueryStats, len(s.sources))
+		var scanned int64
+		partial := false
+		for remaining := len(s.sources); remaining > 0; {
+			select {
+			case res := <-resultChan:
+				remaining--
+				sourceQueryTimeMs[res.source] = res.elapsed.Milliseconds()
+				if req.IncludeStats {
+					perSourceStats[res.source] = res.stats
+				}
+				if res.err != nil {
+					log.Printf("memory discovery: %s query failed, continuing without it: %v", res.source, res.err)
+					partialFailures = append(partialFailures, res.source)
+					continue
+				}
+				if maxMemoriesScanned > 0 && scanned >= maxMemoriesScanned {
+					partial = true
+					continue
+				}
+				scanned += int64(len(res.memories))
+				allMemories = append(allMemories, res.memories...)
+				sourcesQueried = append(sourcesQueried, res.source)
+				events <- MemoryEvent{Type: "source", Source: res.source, Memories: res.memories}
+			case <-heartbeat.C:
+				events <- MemoryEvent{Type: "heartbeat"}
+			case err := <-done:
+				if err != nil {
+					errs <- err
+					return
+				}
+			case <-ctx.Done():
+				errs <- ctx.Err()
+				return
+			}
 		}
-		reasoningChainChan <- memories
-		return nil
-	})
+		if err := <-done; err != nil {
+			errs <- err
+			return
+		}
+
+		scoreMemoriesByRelevance(req.Context, req.RankerName, allMemories)
+		if req.Limit > 0 && req.Limit < len(allMemories) {
+			allMemories = topKMemoriesByRelevance(allMemories, req.Limit)
+		} else {
+			sortMemoriesByRelevance(allMemories)
+		}
+
+		resp := &model.DiscoveryResponse{
+			Memories:          allMemories,
+			TotalFound:        len(allMemories),
+			QueryTimeMs:       time.Since(startTime).Milliseconds(),
+			SourcesQueried:    sourcesQueried,
+			PartialFailures:   partialFailures,
+			SourceQueryTimeMs: sourceQueryTimeMs,
+			Partial:           partial,
+		}
+		if req.IncludeStats {
+			resp.Stats = buildDiscoveryStats(perSourceStats)
+		}
+
+		events <- MemoryEvent{
+			Type:     "done",
+			Response: resp,
+		}
+	}()
+
+	return events, errs
+}
+
+// sourceResult is one source's outcome from a fan-out, shared by
+// DiscoverMemories and BatchDiscoverMemories.
+type sourceResult struct {
+	source   string
+	memories []model.Memory
+	err      error
+	elapsed  time.Duration
+	// stats is only populated when the originating request's IncludeStats
+	// flag was set.
+	stats model.QueryStats
+}
 
-	// Wait for all queries to complete
-	if err := g.Wait(); err != nil {
-		return nil, err
+// queryAllSources fans out req to every registered source concurrently. The
+// per-source goroutines never return an error to the errgroup - a failed
+// source (breaker open, deadline expired, retries exhausted) is captured in
+// its sourceResult.err instead, so one failing source can't abort the
+// others.
+func (s *MemoryDiscoveryService) queryAllSources(ctx context.Context, req *model.DiscoveryRequest) []sourceResult {
+	g, ctx := errgroup.WithContext(ctx)
+	results := make([]sourceResult, len(s.sources))
+
+	for i, src := range s.sources {
+		i, src := i, src
+		g.Go(func() error {
+			sourceCtx, cancel := context.WithTimeout(ctx, s.sourceTimeout(src.Name(), req))
+			defer cancel()
+
+			var collector *QueryStatsCollector
+			if req.IncludeStats {
+				sourceCtx, collector = withQueryStats(sourceCtx)
+			}
+
+			sourceStart := time.Now()
+			memories, err := src.Discover(sourceCtx, req)
+			elapsed := time.Since(sourceStart)
+
+			res := sourceResult{source: src.Name(), memories: memories, err: err, elapsed: elapsed}
+			if req.IncludeStats {
+				res.stats = collector.snapshot(elapsed)
+			}
+			results[i] = res
+			return nil
+		})
 	}
 
-	// Collect results
+	g.Wait()
+	return results
+}
+
+// assembleResponse scores, ranks, and packages a fan-out's sourceResults
+// into a DiscoveryResponse, logging (and recording as a partial failure)
+// any source that errored. maxMemoriesScanned, if positive, caps how many
+// memories are folded in across sources in order: once the running total of
+// res.memories reaches the budget, later sources' memories are dropped and
+// Partial is set instead of an error.
+func assembleResponse(req *model.DiscoveryRequest, startTime time.Time, results []sourceResult, maxMemoriesScanned int64) *model.DiscoveryResponse {
 	var allMemories []model.Memory
-	allMemories = append(allMemories, <-kvCacheChan...)
-	allMemories = append(allMemories, <-wMatrixChan...)
-	allMemories = append(allMemories, <-reasoningChainChan...)
+	sourcesQueried := make([]string, 0, len(results))
+	var partialFailures []string
+	sourceQueryTimeMs := make(map[string]int64, len(results))
+	perSourceStats := make(map[string]model.QueryStats, len(results))
+	var scanned int64
+	partial := false
+
+	for _, res := range results {
+		sourceQueryTimeMs[res.source] = res.elapsed.Milliseconds()
+		if req.IncludeStats {
+			perSourceStats[res.source] = res.stats
+		}
+		if res.err != nil {
+			log.Printf("memory discovery: %s query failed, continuing without it: %v", res.source, res.err)
+			partialFailures = append(partialFailures, res.source)
+			continue
+		}
+		if maxMemoriesScanned > 0 && scanned >= maxMemoriesScanned {
+			partial = true
+			continue
+		}
+		scanned += int64(len(res.memories))
+		allMemories = append(allMemories, res.memories...)
+		sourcesQueried = append(sourcesQueried, res.source)
+	}
 
-	// Sort by relevance score
-	sortMemoriesByRelevance(allMemories)
+	// Score relevance over the whole fanned-in corpus at once (rather than
+	// per-source) so corpus-aware rankers like BM25 see every candidate's
+	// document frequency, not just one source's.
+	scoreMemoriesByRelevance(req.Context, req.RankerName, allMemories)
+
+	// Sort by relevance score. When a limit narrower than the full corpus is
+	// requested, topKMemoriesByRelevance's heap selection does O(n log k)
+	// work instead of a full O(n log n) sort followed by a slice.
+	if req.Limit > 0 && req.Limit < len(allMemories) {
+		allMemories = topKMemoriesByRelevance(allMemories, req.Limit)
+	} else {
+		sortMemoriesByRelevance(allMemories)
+	}
 
-	// Apply limit
-	if req.Limit > 0 && len(allMemories) > req.Limit {
-		allMemories = allMemories[:req.Limit]
+	resp := &model.DiscoveryResponse{
+		Memories:          allMemories,
+		TotalFound:        len(allMemories),
+		QueryTimeMs:       time.Since(startTime).Milliseconds(),
+		SourcesQueried:    sourcesQueried,
+		PartialFailures:   partialFailures,
+		SourceQueryTimeMs: sourceQueryTimeMs,
+		Partial:           partial,
+	}
+	if req.IncludeStats {
+		resp.Stats = buildDiscoveryStats(perSourceStats)
 	}
+	return resp
+}
+
+// DiscoverMemories discovers relevant memories for a given context using concurrent queries
+func (s *MemoryDiscoveryService) DiscoverMemories(ctx context.Context, req *model.DiscoveryRequest) (*model.DiscoveryResponse, error) {
+	startTime := time.Now()
 
-	return &model.DiscoveryResponse{
-		Memories:      allMemories,
-		TotalFound:    len(allMemories),
-		QueryTimeMs:   time.Since(startTime).Milliseconds(),
-		SourcesQueried: []string{"kv-cache", "w-matrix", "reasoning-chain"},
-	}, nil
+	ctx, cancel := s.withDeadline(ctx, req)
+	defer cancel()
+
+	results := s.queryAllSources(ctx, req)
+	return assembleResponse(req, startTime, results, s.maxScanned()), nil
 }
 
-// BatchDiscoverMemories discovers memories for multiple contexts concurrently
-func (s *MemoryDiscoveryService) BatchDiscoverMemories(ctx context.Context, requests []*model.DiscoveryRequest) ([]*model.DiscoveryResponse, error) {
-	results := make([]*model.DiscoveryResponse, len(requests))
+// BatchDiscoverMemories discovers memories for multiple contexts
+// concurrently. Any registered source that implements BatchDiscoverySource
+// (e.g. an Elasticsearch source backed by _msearch) answers all of its
+// requests in a single round trip instead of one call per request; other
+// sources still run once per request via queryAllSources.
+//
+// maxMemoriesScanned, if positive, overrides the service's default
+// SetMaxMemoriesScanned budget for every sub-request in this batch (pass 0
+// to use the service default).
+func (s *MemoryDiscoveryService) BatchDiscoverMemories(ctx context.Context, requests []*model.DiscoveryRequest, maxMemoriesScanned int64) ([]*model.DiscoveryResponse, error) {
+	if len(requests) == 0 {
+		return nil, nil
+	}
+	if maxMemoriesScanned <= 0 {
+		maxMemoriesScanned = s.maxScanned()
+	}
+
+	startTimes := make([]time.Time, len(requests))
+	now := time.Now()
+	for i := range startTimes {
+		startTimes[i] = now
+	}
+
+	// perRequest[i] accumulates the sourceResult for request i from every
+	// source, whether that source answered in a batch call or individually.
+	perRequest := make([][]sourceResult, len(requests))
 	var mu sync.Mutex
-	
 	g, ctx := errgroup.WithContext(ctx)
-	
-	// Process each request concurrently
-	for i, req := range requests {
-		i, req := i, req // Capture loop variables
+
+	for _, src := range s.sources {
+		src := src
+		batchSrc, ok := src.(BatchDiscoverySource)
+		if !ok {
+			for i, req := range requests {
+				i, req := i, req
+				g.Go(func() error {
+					sourceCtx, cancel := context.WithTimeout(ctx, s.sourceTimeout(src.Name(), req))
+					defer cancel()
+
+					var collector *QueryStatsCollector
+					if req.IncludeStats {
+						sourceCtx, collector = withQueryStats(sourceCtx)
+					}
+
+					sourceStart := time.Now()
+					memories, err := src.Discover(sourceCtx, req)
+					elapsed := time.Since(sourceStart)
+
+					res := sourceResult{source: src.Name(), memories: memories, err: err, elapsed: elapsed}
+					if req.IncludeStats {
+						res.stats = collector.snapshot(elapsed)
+					}
+					mu.Lock()
+					perRequest[i] = append(perRequest[i], res)
+					mu.Unlock()
+					return nil
+				})
+			}
+			continue
+		}
+
 		g.Go(func() error {
-			resp, err := s.DiscoverMemories(ctx, req)
-			if err != nil {
-				return err
+			// A batched DiscoverBatch call can't attribute cost to one
+			// sub-request over another, so when any sub-request wants stats
+			// the whole batch's counters are recorded against every
+			// sub-request that asked for them.
+			wantStats := false
+			for _, req := range requests {
+				if req.IncludeStats {
+					wantStats = true
+					break
+				}
+			}
+			batchCtx := ctx
+			var collector *QueryStatsCollector
+			if wantStats {
+				batchCtx, collector = withQueryStats(batchCtx)
 			}
-			
+
+			sourceStart := time.Now()
+			batched, err := batchSrc.DiscoverBatch(batchCtx, requests)
+			elapsed := time.Since(sourceStart)
+			var stats model.QueryStats
+			if wantStats {
+				stats = collector.snapshot(elapsed)
+			}
+
 			mu.Lock()
-			results[i] = resp
-			mu.Unlock()
-			
+			defer mu.Unlock()
+			if err != nil {
+				for i, req := range requests {
+					res := sourceResult{source: src.Name(), err: err, elapsed: elapsed}
+					if req.IncludeStats {
+						res.stats = stats
+					}
+					perRequest[i] = append(perRequest[i], res)
+				}
+				return nil
+			}
+			for i, req := range requests {
+				res := sourceResult{source: src.Name(), memories: batched[i], elapsed: elapsed}
+				if req.IncludeStats {
+					res.stats = stats
+				}
+				perRequest[i] = append(perRequest[i], res)
+			}
 			return nil
 		})
 	}
-	
-	if err := g.Wait(); err != nil {
-		return nil, err
+
+	g.Wait()
+
+	responses := make([]*model.DiscoveryResponse, len(requests))
+	for i, req := range requests {
+		responses[i] = assembleResponse(req, startTimes[i], perRequest[i], maxMemoriesScanned)
 	}
-	
-	return results, nil
+	return responses, nil
 }
 
 // queryKVCacheMemories queries KV-Cache memories from the API
@@ -144,7 +604,7 @@ func (s *MemoryDiscoveryService) queryKVCacheMemories(ctx context.Context, req *
 		queryParams += fmt.Sprintf("&maxPrice=%.2f", req.MaxPrice)
 	}
 
-	if err := s.apiClient.Get(ctx, "/api/trpc/packages.browsePackages"+queryParams, &packages); err != nil {
+	if err := s.apiClient.GetWithOptions(ctx, "/api/trpc/packages.browsePackages"+queryParams, &packages, requestOptions(s.kvCacheBreaker)); err != nil {
 		return nil, fmt.Errorf("failed to fetch KV-Cache memories: %w", err)
 	}
 
@@ -165,11 +625,14 @@ func (s *MemoryDiscoveryService) queryKVCacheMemories(ctx context.Context, req *
 				Epsilon:        epsilon,
 				Certification:  "gold",
 				Price:          price,
-				RelevanceScore: calculateRelevance(req.Context, pkg.Name+" "+pkg.Description),
 			})
 		}
 	}
 
+	stats := queryStatsFromContext(ctx)
+	stats.AddScanned(int64(len(packages)))
+	stats.AddEvaluated(int64(len(memories)))
+
 	return memories, nil
 }
 
@@ -198,7 +661,7 @@ func (s *MemoryDiscoveryService) queryWMatrixMemories(ctx context.Context, req *
 		queryParams += fmt.Sprintf("&maxPrice=%.2f", req.MaxPrice)
 	}
 
-	if err := s.apiClient.Get(ctx, "/api/trpc/wMatrix.browseListings"+queryParams, &wMatrices); err != nil {
+	if err := s.apiClient.GetWithOptions(ctx, "/api/trpc/wMatrix.browseListings"+queryParams, &wMatrices, requestOptions(s.wMatrixBreaker)); err != nil {
 		return nil, fmt.Errorf("failed to fetch W-Matrix memories: %w", err)
 	}
 
@@ -213,11 +676,14 @@ func (s *MemoryDiscoveryService) queryWMatrixMemories(ctx context.Context, req *
 				Epsilon:        wm.AverageEpsilon,
 				Certification:  "gold",
 				Price:          wm.Price,
-				RelevanceScore: calculateRelevance(req.Context, wm.Title+" "+wm.Description),
 			})
 		}
 	}
 
+	stats := queryStatsFromContext(ctx)
+	stats.AddScanned(int64(len(wMatrices)))
+	stats.AddEvaluated(int64(len(memories)))
+
 	return memories, nil
 }
 
@@ -239,7 +705,7 @@ func (s *MemoryDiscoveryService) queryReasoningChainMemories(ctx context.Context
 		queryParams += fmt.Sprintf("&maxPrice=%.2f", req.MaxPrice)
 	}
 
-	if err := s.apiClient.Get(ctx, "/api/trpc/packages.browsePackages"+queryParams, &packages); err != nil {
+	if err := s.apiClient.GetWithOptions(ctx, "/api/trpc/packages.browsePackages"+queryParams, &packages, requestOptions(s.reasoningChainBreaker)); err != nil {
 		return nil, fmt.Errorf("failed to fetch Reasoning Chain memories: %w", err)
 	}
 
@@ -260,14 +726,34 @@ func (s *MemoryDiscoveryService) queryReasoningChainMemories(ctx context.Context
 				Epsilon:        epsilon,
 				Certification:  "silver",
 				Price:          price,
-				RelevanceScore: calculateRelevance(req.Context, pkg.Name+" "+pkg.Description),
 			})
 		}
 	}
 
+	stats := queryStatsFromContext(ctx)
+	stats.AddScanned(int64(len(packages)))
+	stats.AddEvaluated(int64(len(memories)))
+
 	return memories, nil
 }
 
+// scoreMemoriesByRelevance assigns RelevanceScore to every memory using the
+// ranker named by rankerName (falling back to "lexical"), scoring the whole
+// fanned-in corpus in one BatchScore call.
+func scoreMemoriesByRelevance(context, rankerName string, memories []model.Memory) {
+	ranker := GetRanker(rankerName)
+
+	docs := make([]Document, len(memories))
+	for i, m := range memories {
+		docs[i] = Document{ID: m.ID, Text: m.Name + " " + m.Description}
+	}
+
+	scores := ranker.BatchScore(context, docs)
+	for i := range memories {
+		memories[i].RelevanceScore = scores[i]
+	}
+}
+
 // calculateRelevance calculates relevance score between context and memory description
 func calculateRelevance(context, description string) float64 {
 	// Convert both to lowercase for case-insensitive matching
@@ -359,14 +845,59 @@ func tokenize(text string) []string {
 	return words
 }
 
-// sortMemoriesByRelevance sorts memories by relevance score in descending order
+// sortMemoriesByRelevance sorts memories by relevance score in descending
+// order, O(n log n) instead of the bubble sort this used to be.
 func sortMemoriesByRelevance(memories []model.Memory) {
-	// Simple bubble sort for now (replace with more efficient algorithm if needed)
-	for i := 0; i < len(memories); i++ {
-		for j := i + 1; j < len(memories); j++ {
-			if memories[i].RelevanceScore < memories[j].RelevanceScore {
-				memories[i], memories[j] = memories[j], memories[i]
-			}
+	sort.Slice(memories, func(i, j int) bool {
+		return memories[i].RelevanceScore > memories[j].RelevanceScore
+	})
+}
+
+// memoryHeap is a min-heap of memories ordered by RelevanceScore, used by
+// topKMemoriesByRelevance to keep only the k highest-scoring memories seen
+// so far in O(log k) per insertion.
+type memoryHeap []model.Memory
+
+func (h memoryHeap) Len() int            { return len(h) }
+func (h memoryHeap) Less(i, j int) bool  { return h[i].RelevanceScore < h[j].RelevanceScore }
+func (h memoryHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *memoryHeap) Push(x interface{}) { *h = append(*h, x.(model.Memory)) }
+func (h *memoryHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	item := old[n-1]
+	*h = old[:n-1]
+	return item
+}
+
+// topKMemoriesByRelevance returns the k highest-scoring memories in
+// descending order of RelevanceScore, doing O(n log k) work instead of
+// sortMemoriesByRelevance's O(n log n) when k is much smaller than n.
+func topKMemoriesByRelevance(memories []model.Memory, k int) []model.Memory {
+	if k <= 0 {
+		return nil
+	}
+	if k >= len(memories) {
+		sortMemoriesByRelevance(memories)
+		return memories
+	}
+
+	h := make(memoryHeap, 0, k)
+	heap.Init(&h)
+	for _, m := range memories {
+		if h.Len() < k {
+			heap.Push(&h, m)
+			continue
+		}
+		if m.RelevanceScore > h[0].RelevanceScore {
+			heap.Pop(&h)
+			heap.Push(&h, m)
 		}
 	}
+
+	top := make([]model.Memory, h.Len())
+	for i := len(top) - 1; i >= 0; i-- {
+		top[i] = heap.Pop(&h).(model.Memory)
+	}
+	return top
 }
@@ -0,0 +1,101 @@
+package service
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/awareness-market/mcp-gateway/internal/model"
+)
+
+// queryStatsKey is the context key a QueryStatsCollector is stored under via
+// withQueryStats.
+type queryStatsKey struct{}
+
+// QueryStatsCollector accumulates one source's cost counters for a single
+// DiscoveryRequest. A DiscoverySource pulls its collector from ctx (via
+// queryStatsFromContext) and adds to it as it works; queryAllSources reads
+// the final counts back out once the source returns. Nil-safe so sources
+// don't need to special-case the IncludeStats-off path.
+type QueryStatsCollector struct {
+	scanned      int64
+	evaluated    int64
+	embeddingCmp int64
+	cacheHits    int64
+}
+
+// withQueryStats derives a child context carrying a fresh QueryStatsCollector.
+func withQueryStats(ctx context.Context) (context.Context, *QueryStatsCollector) {
+	c := &QueryStatsCollector{}
+	return context.WithValue(ctx, queryStatsKey{}, c), c
+}
+
+// queryStatsFromContext returns the collector installed by withQueryStats,
+// or nil if the request didn't set IncludeStats.
+func queryStatsFromContext(ctx context.Context) *QueryStatsCollector {
+	c, _ := ctx.Value(queryStatsKey{}).(*QueryStatsCollector)
+	return c
+}
+
+// AddScanned records candidates considered before any local filtering. No-op
+// on a nil collector so callers can write c.AddScanned(n) unconditionally.
+func (c *QueryStatsCollector) AddScanned(n int64) {
+	if c == nil {
+		return
+	}
+	atomic.AddInt64(&c.scanned, n)
+}
+
+// AddEvaluated records candidates that survived filtering.
+func (c *QueryStatsCollector) AddEvaluated(n int64) {
+	if c == nil {
+		return
+	}
+	atomic.AddInt64(&c.evaluated, n)
+}
+
+// AddEmbeddingComparisons records vector-similarity computations performed
+// (e.g. a kNN rescore pass).
+func (c *QueryStatsCollector) AddEmbeddingComparisons(n int64) {
+	if c == nil {
+		return
+	}
+	atomic.AddInt64(&c.embeddingCmp, n)
+}
+
+// AddCacheHit records one cache hit avoiding an upstream round trip.
+func (c *QueryStatsCollector) AddCacheHit() {
+	if c == nil {
+		return
+	}
+	atomic.AddInt64(&c.cacheHits, 1)
+}
+
+// snapshot reads the collector's current counts into a model.QueryStats,
+// stamping the source's wall-clock time alongside them.
+func (c *QueryStatsCollector) snapshot(wallTime time.Duration) model.QueryStats {
+	if c == nil {
+		return model.QueryStats{WallTimeMs: wallTime.Milliseconds()}
+	}
+	return model.QueryStats{
+		MemoriesScanned:      atomic.LoadInt64(&c.scanned),
+		MemoriesEvaluated:    atomic.LoadInt64(&c.evaluated),
+		EmbeddingComparisons: atomic.LoadInt64(&c.embeddingCmp),
+		CacheHits:            atomic.LoadInt64(&c.cacheHits),
+		WallTimeMs:           wallTime.Milliseconds(),
+	}
+}
+
+// buildDiscoveryStats packages a fan-out's per-source QueryStats into a
+// model.DiscoveryStats, summing the Total across every entry in perSource.
+func buildDiscoveryStats(perSource map[string]model.QueryStats) *model.DiscoveryStats {
+	var total model.QueryStats
+	for _, s := range perSource {
+		total.MemoriesScanned += s.MemoriesScanned
+		total.MemoriesEvaluated += s.MemoriesEvaluated
+		total.EmbeddingComparisons += s.EmbeddingComparisons
+		total.CacheHits += s.CacheHits
+		total.WallTimeMs += s.WallTimeMs
+	}
+	return &model.DiscoveryStats{PerSource: perSource, Total: total}
+}
@@ -0,0 +1,70 @@
+package service
+
+import "sync"
+
+// Document is a single piece of rankable text, typically a memory's name
+// and description concatenated together.
+type Document struct {
+	ID   string
+	Text string
+}
+
+// Ranker scores how relevant a document is to a free-text context. Forks can
+// register their own implementation (e.g. a cross-encoder reranker) while
+// DiscoverMemories keeps picking one by DiscoveryRequest.RankerName.
+type Ranker interface {
+	Name() string
+	Score(context string, doc Document) float64
+	// BatchScore scores every doc against context in one call, so rankers
+	// that need corpus-wide statistics (BM25's IDF) can compute them once
+	// instead of per-document.
+	BatchScore(context string, docs []Document) []float64
+}
+
+var (
+	rankersMu sync.RWMutex
+	rankers   = map[string]Ranker{}
+)
+
+// RegisterRanker makes a Ranker available by name for DiscoveryRequest.RankerName.
+func RegisterRanker(r Ranker) {
+	rankersMu.Lock()
+	defer rankersMu.Unlock()
+	rankers[r.Name()] = r
+}
+
+// GetRanker looks up a registered ranker, falling back to "lexical".
+func GetRanker(name string) Ranker {
+	rankersMu.RLock()
+	defer rankersMu.RUnlock()
+	if r, ok := rankers[name]; ok {
+		return r
+	}
+	return rankers["lexical"]
+}
+
+func init() {
+	RegisterRanker(LexicalRanker{})
+	RegisterRanker(NewBM25Ranker())
+	if er := NewEmbeddingRankerFromEnv(); er != nil {
+		RegisterRanker(er)
+	}
+}
+
+// LexicalRanker is the original Jaccard-plus-substring calculateRelevance,
+// kept as the zero-configuration default.
+type LexicalRanker struct{}
+
+func (LexicalRanker) Name() string { return "lexical" }
+
+func (LexicalRanker) Score(context string, doc Document) float64 {
+	return calculateRelevance(context, doc.Text)
+}
+
+func (r LexicalRanker) BatchScore(context string, docs []Document) []float64 {
+	scores := make([]float64, len(docs))
+	for i, doc := range docs {
+		scores[i] = r.Score(context, doc)
+	}
+	return scores
+}
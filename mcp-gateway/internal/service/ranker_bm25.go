@@ -0,0 +1,131 @@
+package service
+
+import (
+	"math"
+	"strings"
+	"sync"
+	"time"
+)
+
+// bm25TTL is how long BM25Ranker trusts its cached corpus statistics before
+// rebuilding them from the next batch of documents it sees.
+const bm25TTL = 5 * time.Minute
+
+// BM25Ranker scores context/document relevance with Okapi BM25 instead of
+// LexicalRanker's Jaccard overlap. It has no standing corpus to draw
+// statistics from (memories are fetched fresh per request), so it treats
+// each BatchScore call's documents as a sample of the corpus and rebuilds
+// its inverted index from that sample whenever the TTL has elapsed,
+// amortizing the rebuild across the bursts of calls a busy gateway sees
+// rather than doing it on every single request.
+type BM25Ranker struct {
+	k1 float64
+	b  float64
+
+	mu          sync.Mutex
+	lastRefresh time.Time
+	docFreq     map[string]int // term -> number of docs containing it
+	corpusSize  int
+	avgDocLen   float64
+}
+
+// NewBM25Ranker builds a BM25Ranker with the standard k1=1.2, b=0.75 defaults.
+func NewBM25Ranker() *BM25Ranker {
+	return &BM25Ranker{k1: 1.2, b: 0.75, docFreq: map[string]int{}}
+}
+
+func (r *BM25Ranker) Name() string { return "bm25" }
+
+// Score ranks a single document using whatever corpus statistics are
+// currently cached (falling back to idf=1 for terms never seen). Prefer
+// BatchScore when scoring a full result set so the cache is fresh.
+func (r *BM25Ranker) Score(context string, doc Document) float64 {
+	return r.score(tokenize(strings.ToLower(context)), doc)
+}
+
+func (r *BM25Ranker) BatchScore(context string, docs []Document) []float64 {
+	r.refreshIfStale(docs)
+
+	queryTerms := tokenize(strings.ToLower(context))
+	scores := make([]float64, len(docs))
+	for i, doc := range docs {
+		scores[i] = r.score(queryTerms, doc)
+	}
+	return scores
+}
+
+func (r *BM25Ranker) score(queryTerms []string, doc Document) float64 {
+	docTerms := tokenize(strings.ToLower(doc.Text))
+	if len(queryTerms) == 0 || len(docTerms) == 0 {
+		return 0
+	}
+
+	termFreq := map[string]int{}
+	for _, t := range docTerms {
+		termFreq[t]++
+	}
+
+	r.mu.Lock()
+	docFreq := r.docFreq
+	corpusSize := r.corpusSize
+	avgDocLen := r.avgDocLen
+	r.mu.Unlock()
+	if avgDocLen == 0 {
+		avgDocLen = float64(len(docTerms))
+	}
+
+	docLen := float64(len(docTerms))
+	var score float64
+	for _, term := range queryTerms {
+		tf := float64(termFreq[term])
+		if tf == 0 {
+			continue
+		}
+		idf := bm25IDF(corpusSize, docFreq[term])
+		numerator := tf * (r.k1 + 1)
+		denominator := tf + r.k1*(1-r.b+r.b*docLen/avgDocLen)
+		score += idf * (numerator / denominator)
+	}
+	return score
+}
+
+// bm25IDF is the standard BM25 inverse document frequency with a +1 floor so
+// it never goes negative for very common terms.
+func bm25IDF(corpusSize, df int) float64 {
+	if corpusSize == 0 {
+		return 1
+	}
+	return math.Log((float64(corpusSize-df)+0.5)/(float64(df)+0.5) + 1)
+}
+
+// refreshIfStale rebuilds the document-frequency index and average document
+// length from docs if the TTL has elapsed since the last rebuild.
+func (r *BM25Ranker) refreshIfStale(docs []Document) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if time.Since(r.lastRefresh) < bm25TTL {
+		return
+	}
+
+	docFreq := map[string]int{}
+	totalLen := 0
+	for _, doc := range docs {
+		seen := map[string]bool{}
+		terms := tokenize(strings.ToLower(doc.Text))
+		totalLen += len(terms)
+		for _, t := range terms {
+			if !seen[t] {
+				docFreq[t]++
+				seen[t] = true
+			}
+		}
+	}
+
+	r.docFreq = docFreq
+	r.corpusSize = len(docs)
+	if len(docs) > 0 {
+		r.avgDocLen = float64(totalLen) / float64(len(docs))
+	}
+	r.lastRefresh = time.Now()
+}
@@ -0,0 +1,153 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"os"
+	"time"
+)
+
+// EmbeddingRanker scores context/document relevance by cosine similarity
+// between embeddings fetched from a configurable OpenAI-compatible
+// /v1/embeddings endpoint, for deployments that want semantic matching
+// beyond BM25Ranker's term overlap.
+type EmbeddingRanker struct {
+	client  *http.Client
+	baseURL string
+	apiKey  string
+	model   string
+}
+
+// NewEmbeddingRankerFromEnv builds an EmbeddingRanker from EMBEDDING_API_URL
+// (required), EMBEDDING_API_KEY, and EMBEDDING_MODEL (default
+// "text-embedding-3-small"). Returns nil if EMBEDDING_API_URL is unset, so
+// init() can skip registering it rather than shipping a ranker guaranteed
+// to fail every call.
+func NewEmbeddingRankerFromEnv() *EmbeddingRanker {
+	baseURL := os.Getenv("EMBEDDING_API_URL")
+	if baseURL == "" {
+		return nil
+	}
+	model := os.Getenv("EMBEDDING_MODEL")
+	if model == "" {
+		model = "text-embedding-3-small"
+	}
+	return &EmbeddingRanker{
+		client:  &http.Client{Timeout: 10 * time.Second},
+		baseURL: baseURL,
+		apiKey:  os.Getenv("EMBEDDING_API_KEY"),
+		model:   model,
+	}
+}
+
+func (EmbeddingRanker) Name() string { return "embedding" }
+
+func (r *EmbeddingRanker) Score(context string, doc Document) float64 {
+	scores := r.BatchScore(context, []Document{doc})
+	if len(scores) == 0 {
+		return 0
+	}
+	return scores[0]
+}
+
+// BatchScore embeds context and every doc in a single request, then scores
+// each doc by cosine similarity to the context embedding.
+func (r *EmbeddingRanker) BatchScore(context string, docs []Document) []float64 {
+	scores := make([]float64, len(docs))
+	if context == "" || len(docs) == 0 {
+		return scores
+	}
+
+	input := make([]string, 0, len(docs)+1)
+	input = append(input, context)
+	for _, doc := range docs {
+		input = append(input, doc.Text)
+	}
+
+	embeddings, err := r.embed(input)
+	if err != nil {
+		log.Printf("embedding ranker: %v", err)
+		return scores
+	}
+	if len(embeddings) != len(input) {
+		log.Printf("embedding ranker: expected %d embeddings, got %d", len(input), len(embeddings))
+		return scores
+	}
+
+	contextVec := embeddings[0]
+	for i := range docs {
+		scores[i] = cosineSimilarity(contextVec, embeddings[i+1])
+	}
+	return scores
+}
+
+type embeddingsRequest struct {
+	Model string   `json:"model"`
+	Input []string `json:"input"`
+}
+
+type embeddingsResponse struct {
+	Data []struct {
+		Embedding []float64 `json:"embedding"`
+	} `json:"data"`
+}
+
+func (r *EmbeddingRanker) embed(input []string) ([][]float64, error) {
+	body, err := json.Marshal(embeddingsRequest{Model: r.model, Input: input})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal embeddings request: %w", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, r.baseURL+"/v1/embeddings", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to build embeddings request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if r.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+r.apiKey)
+	}
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("embeddings request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("embeddings endpoint returned status %d", resp.StatusCode)
+	}
+
+	var parsed embeddingsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&parsed); err != nil {
+		return nil, fmt.Errorf("failed to decode embeddings response: %w", err)
+	}
+
+	vectors := make([][]float64, len(parsed.Data))
+	for i, d := range parsed.Data {
+		vectors[i] = d.Embedding
+	}
+	return vectors, nil
+}
+
+// cosineSimilarity returns the cosine similarity of a and b, or 0 if either
+// is zero-length or zero-magnitude.
+func cosineSimilarity(a, b []float64) float64 {
+	if len(a) == 0 || len(a) != len(b) {
+		return 0
+	}
+
+	var dot, normA, normB float64
+	for i := range a {
+		dot += a[i] * b[i]
+		normA += a[i] * a[i]
+		normB += b[i] * b[i]
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
@@ -4,26 +4,50 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"math"
 	"net/url"
 	"sort"
+	"time"
 
 	"github.com/awareness-market/mcp-gateway/internal/model"
+	"github.com/awareness-market/mcp-gateway/internal/search"
 	"github.com/awareness-market/mcp-gateway/pkg/client"
+	"golang.org/x/sync/errgroup"
+	"golang.org/x/sync/singleflight"
 )
 
+// creditLookupConcurrency bounds how many agentCredit.getProfile calls run at
+// once when scoring a batch of memories, so a large result set can't open
+// hundreds of sockets against the credit service.
+const creditLookupConcurrency = 8
+
 // RecommendationService provides intelligent memory recommendations
 type RecommendationService struct {
-	apiClient *client.AwarenessAPIClient
+	apiClient      *client.AwarenessAPIClient
+	creditBreaker  *client.CircuitBreaker
+	packageBreaker *client.CircuitBreaker
+	creditGroup    singleflight.Group
+
+	// searchIndex, when set via SetSearchIndex, lets fetchAvailableMemories
+	// run one hybrid ES/OpenSearch query instead of fanning out to the
+	// tRPC endpoints directly.
+	searchIndex *search.Index
 }
 
 // NewRecommendationService creates a new recommendation service
 func NewRecommendationService(apiClient *client.AwarenessAPIClient) *RecommendationService {
 	return &RecommendationService{
-		apiClient: apiClient,
+		apiClient:      apiClient,
+		creditBreaker:  client.NewCircuitBreaker(5, 30*time.Second),
+		packageBreaker: client.NewCircuitBreaker(5, 30*time.Second),
 	}
 }
 
+// SetSearchIndex enables the search-index fast path for fetchAvailableMemories.
+// Pass nil to fall back to the direct tRPC fan-out (the default).
+func (s *RecommendationService) SetSearchIndex(index *search.Index) {
+	s.searchIndex = index
+}
+
 // RecommendMemories recommends memories based on context, quality, and agent credit scores
 func (s *RecommendationService) RecommendMemories(ctx context.Context, req *model.RecommendationRequest) (*model.RecommendationResponse, error) {
 	// Get available memories
@@ -51,23 +75,43 @@ func (s *RecommendationService) RecommendMemories(ctx context.Context, req *mode
 	}, nil
 }
 
+// AgentCreditScore exposes the credit score lookup for callers outside this
+// package, e.g. the GraphQL gateway's lazily-resolved agentCreditScore field.
+func (s *RecommendationService) AgentCreditScore(ctx context.Context, agentAddress string, fallback int) int {
+	return s.getAgentCreditScore(ctx, agentAddress, fallback)
+}
+
+// getAgentCreditScore looks up an agent's credit score with a per-call
+// deadline, retries on 5xx/network errors, and a circuit breaker shared
+// across all agentCredit calls. Concurrent lookups for the same
+// agentAddress (common when one creator lists many memories) are
+// deduplicated into a single upstream call via creditGroup.
 func (s *RecommendationService) getAgentCreditScore(ctx context.Context, agentAddress string, fallback int) int {
 	if agentAddress == "" {
 		return fallback
 	}
 
-	input := fmt.Sprintf(`{"agentAddress":"%s"}`, agentAddress)
-	endpoint := "/api/trpc/agentCredit.getProfile?input=" + url.QueryEscape(input)
-	var raw interface{}
+	v, _, _ := s.creditGroup.Do(agentAddress, func() (interface{}, error) {
+		input := fmt.Sprintf(`{"agentAddress":"%s"}`, agentAddress)
+		endpoint := "/api/trpc/agentCredit.getProfile?input=" + url.QueryEscape(input)
+		var raw interface{}
+
+		opts := client.DefaultRequestOptions()
+		opts.Timeout = 3 * time.Second
+		opts.Breaker = s.creditBreaker
 
-	if err := s.apiClient.Get(ctx, endpoint, &raw); err != nil {
+		if err := s.apiClient.GetWithOptions(ctx, endpoint, &raw, opts); err != nil {
+			return nil, err
+		}
+		return raw, nil
+	})
+	if v == nil {
 		return fallback
 	}
 
-	if score, ok := extractCreditScore(raw); ok {
+	if score, ok := extractCreditScore(v); ok {
 		return score
 	}
-
 	return fallback
 }
 
@@ -125,153 +169,88 @@ func unwrapTrpcField(raw interface{}, field string) (interface{}, bool) {
 	return nil, false
 }
 
-// fetchAvailableMemories fetches available memories from the marketplace
+type packageResponse struct {
+	ID                   int    `json:"id"`
+	PackageID            string `json:"packageId"`
+	Name                 string `json:"name"`
+	Description          string `json:"description"`
+	Price                string `json:"price"`
+	Status               string `json:"status"`
+	CreatorID            int    `json:"creatorId"`
+	InformationRetention string `json:"informationRetention"`
+}
+
+type wMatrixResponse struct {
+	ID             int     `json:"id"`
+	Title          string  `json:"title"`
+	Description    string  `json:"description"`
+	SourceModel    string  `json:"sourceModel"`
+	TargetModel    string  `json:"targetModel"`
+	Price          float64 `json:"price"`
+	AverageEpsilon float64 `json:"averageEpsilon"`
+	Status         string  `json:"status"`
+}
+
+// fetchAvailableMemories fetches available memories from the marketplace. If
+// a search index is configured (SetSearchIndex), this is a single hybrid
+// query combining filters, BM25 text match, and optional kNN rescoring.
+// Otherwise it falls back to querying the three package/listing tRPC
+// endpoints concurrently, with credit score lookups for the results fanned
+// out across a bounded worker pool (see creditLookupConcurrency).
 func (s *RecommendationService) fetchAvailableMemories(ctx context.Context, req *model.RecommendationRequest) ([]model.Memory, error) {
-	type PackageResponse struct {
-		ID                   int    `json:"id"`
-		PackageID            string `json:"packageId"`
-		Name                 string `json:"name"`
-		Description          string `json:"description"`
-		Price                string `json:"price"`
-		Status               string `json:"status"`
-		CreatorID            int    `json:"creatorId"`
-		InformationRetention string `json:"informationRetention"`
-	}
-
-	type WMatrixResponse struct {
-		ID             int     `json:"id"`
-		Title          string  `json:"title"`
-		Description    string  `json:"description"`
-		SourceModel    string  `json:"sourceModel"`
-		TargetModel    string  `json:"targetModel"`
-		Price          float64 `json:"price"`
-		AverageEpsilon float64 `json:"averageEpsilon"`
-		Status         string  `json:"status"`
-	}
-
-	var allMemories []model.Memory
-
-	// Fetch packages (KV-Cache and Reasoning Chains)
+	if s.searchIndex != nil {
+		return s.searchIndex.Search(ctx, req)
+	}
+	return s.fetchAvailableMemoriesViaTRPC(ctx, req)
+}
+
+// fetchAvailableMemoriesViaTRPC is the tRPC fan-out fallback, and also what
+// CrawlForIndex uses to repopulate the search index (it must bypass
+// searchIndex itself, or a configured index would just query itself).
+func (s *RecommendationService) fetchAvailableMemoriesViaTRPC(ctx context.Context, req *model.RecommendationRequest) ([]model.Memory, error) {
 	queryParams := fmt.Sprintf("?limit=%d", req.Limit)
 	if req.MaxBudget > 0 {
 		queryParams += fmt.Sprintf("&maxPrice=%.2f", req.MaxBudget)
 	}
 
-	// Fetch KV-Cache packages
-	if len(req.PreferredTypes) == 0 || contains(req.PreferredTypes, "kv-cache") {
-		var raw interface{}
-		kvParams := queryParams + "&packageType=memory"
-		if err := s.apiClient.Get(ctx, "/api/trpc/packages.browsePackages"+kvParams, &raw); err == nil {
-			var kvPackages []PackageResponse
-			if value, ok := unwrapTrpcField(raw, "packages"); ok {
-				kvPackages, _ = decodeSlice[PackageResponse](value)
-			} else if direct, ok := decodeSlice[PackageResponse](raw); ok {
-				kvPackages = direct
-			}
-
-			for _, pkg := range kvPackages {
-				price := 0.0
-				fmt.Sscanf(pkg.Price, "%f", &price)
-				epsilon := 0.05
-				if ir, err := fmt.Sscanf(pkg.InformationRetention, "%f", &epsilon); err == nil && ir == 1 {
-					epsilon = 1.0 - epsilon
-				}
+	g, gctx := errgroup.WithContext(ctx)
+	kvChan := make(chan []model.Memory, 1)
+	chainChan := make(chan []model.Memory, 1)
+	wMatrixChan := make(chan []model.Memory, 1)
 
-				agentAddress := fmt.Sprintf("0x%040d", pkg.CreatorID)
-				creditScore := s.getAgentCreditScore(ctx, agentAddress, 700)
-
-				allMemories = append(allMemories, model.Memory{
-					ID:               pkg.PackageID,
-					Type:             "kv-cache",
-					Name:             pkg.Name,
-					Description:      pkg.Description,
-					Epsilon:          epsilon,
-					Certification:    "gold",
-					Price:            price,
-					AgentAddress:     agentAddress,
-					AgentCreditScore: creditScore,
-				})
-			}
-		}
+	if len(req.PreferredTypes) == 0 || contains(req.PreferredTypes, "kv-cache") {
+		g.Go(func() error {
+			kvChan <- s.fetchPackageMemories(gctx, queryParams+"&packageType=memory", "kv-cache", "gold", 700)
+			return nil
+		})
+	} else {
+		kvChan <- nil
 	}
 
-	// Fetch Reasoning Chain packages
 	if len(req.PreferredTypes) == 0 || contains(req.PreferredTypes, "reasoning-chain") {
-		var raw interface{}
-		chainParams := queryParams + "&packageType=chain"
-		if err := s.apiClient.Get(ctx, "/api/trpc/packages.browsePackages"+chainParams, &raw); err == nil {
-			var chainPackages []PackageResponse
-			if value, ok := unwrapTrpcField(raw, "packages"); ok {
-				chainPackages, _ = decodeSlice[PackageResponse](value)
-			} else if direct, ok := decodeSlice[PackageResponse](raw); ok {
-				chainPackages = direct
-			}
-
-			for _, pkg := range chainPackages {
-				price := 0.0
-				fmt.Sscanf(pkg.Price, "%f", &price)
-				epsilon := 0.05
-				if ir, err := fmt.Sscanf(pkg.InformationRetention, "%f", &epsilon); err == nil && ir == 1 {
-					epsilon = 1.0 - epsilon
-				}
-
-				agentAddress := fmt.Sprintf("0x%040d", pkg.CreatorID)
-				creditScore := s.getAgentCreditScore(ctx, agentAddress, 650)
-
-				allMemories = append(allMemories, model.Memory{
-					ID:               pkg.PackageID,
-					Type:             "reasoning-chain",
-					Name:             pkg.Name,
-					Description:      pkg.Description,
-					Epsilon:          epsilon,
-					Certification:    "silver",
-					Price:            price,
-					AgentAddress:     agentAddress,
-					AgentCreditScore: creditScore,
-				})
-			}
-		}
+		g.Go(func() error {
+			chainChan <- s.fetchPackageMemories(gctx, queryParams+"&packageType=chain", "reasoning-chain", "silver", 650)
+			return nil
+		})
+	} else {
+		chainChan <- nil
 	}
 
-	// Fetch W-Matrix listings
 	if len(req.PreferredTypes) == 0 || contains(req.PreferredTypes, "w-matrix") {
-		var wMatrices []WMatrixResponse
-		wParams := queryParams
-		if req.SourceModel != "" {
-			wParams += "&sourceModel=" + req.SourceModel
-		}
-		if req.TargetModel != "" {
-			wParams += "&targetModel=" + req.TargetModel
-		}
-
-		var raw interface{}
-		if err := s.apiClient.Get(ctx, "/api/trpc/wMatrix.browseListings"+wParams, &raw); err == nil {
-			if value, ok := unwrapTrpcField(raw, "listings"); ok {
-				wMatrices, _ = decodeSlice[WMatrixResponse](value)
-			} else if direct, ok := decodeSlice[WMatrixResponse](raw); ok {
-				wMatrices = direct
-			}
+		g.Go(func() error {
+			wMatrixChan <- s.fetchWMatrixMemories(gctx, req, queryParams)
+			return nil
+		})
+	} else {
+		wMatrixChan <- nil
+	}
 
-			for _, wm := range wMatrices {
-				if wm.Status == "active" {
-					agentAddress := fmt.Sprintf("0x%040d", wm.ID)
-					creditScore := s.getAgentCreditScore(ctx, agentAddress, 750)
-					allMemories = append(allMemories, model.Memory{
-						ID:               fmt.Sprintf("wm-%d", wm.ID),
-						Type:             "w-matrix",
-						Name:             wm.Title,
-						Description:      wm.Description,
-						Epsilon:          wm.AverageEpsilon,
-						Certification:    "gold",
-						Price:            wm.Price,
-						AgentAddress:     agentAddress,
-						AgentCreditScore: creditScore,
-					})
-				}
-			}
-		}
+	if err := g.Wait(); err != nil {
+		return nil, err
 	}
 
+	allMemories := append(append(<-kvChan, <-chainChan...), <-wMatrixChan...)
+
 	// Filter by minimum credit score if specified
 	if req.MinCreditScore > 0 {
 		filtered := []model.Memory{}
@@ -286,6 +265,142 @@ func (s *RecommendationService) fetchAvailableMemories(ctx context.Context, req
 	return allMemories, nil
 }
 
+// fetchPackageMemories fetches one packageType of packages.browsePackages
+// and resolves each creator's credit score over a bounded worker pool.
+func (s *RecommendationService) fetchPackageMemories(ctx context.Context, params, memoryType, certification string, creditFallback int) []model.Memory {
+	var raw interface{}
+	opts := client.DefaultRequestOptions()
+	opts.Breaker = s.packageBreaker
+	if err := s.apiClient.GetWithOptions(ctx, "/api/trpc/packages.browsePackages"+params, &raw, opts); err != nil {
+		return nil
+	}
+
+	var packages []packageResponse
+	if value, ok := unwrapTrpcField(raw, "packages"); ok {
+		packages, _ = decodeSlice[packageResponse](value)
+	} else if direct, ok := decodeSlice[packageResponse](raw); ok {
+		packages = direct
+	}
+
+	memories := make([]model.Memory, len(packages))
+	for i, pkg := range packages {
+		price := 0.0
+		fmt.Sscanf(pkg.Price, "%f", &price)
+		epsilon := 0.05
+		if ir, err := fmt.Sscanf(pkg.InformationRetention, "%f", &epsilon); err == nil && ir == 1 {
+			epsilon = 1.0 - epsilon
+		}
+
+		memories[i] = model.Memory{
+			ID:            pkg.PackageID,
+			Type:          memoryType,
+			Name:          pkg.Name,
+			Description:   pkg.Description,
+			Epsilon:       epsilon,
+			Certification: certification,
+			Price:         price,
+			AgentAddress:  fmt.Sprintf("0x%040d", pkg.CreatorID),
+		}
+	}
+
+	s.resolveCreditScores(ctx, memories, creditFallback)
+	return memories
+}
+
+// fetchWMatrixMemories fetches active wMatrix.browseListings entries and
+// resolves each seller's credit score over a bounded worker pool.
+func (s *RecommendationService) fetchWMatrixMemories(ctx context.Context, req *model.RecommendationRequest, queryParams string) []model.Memory {
+	wParams := queryParams
+	if req.SourceModel != "" {
+		wParams += "&sourceModel=" + req.SourceModel
+	}
+	if req.TargetModel != "" {
+		wParams += "&targetModel=" + req.TargetModel
+	}
+
+	var raw interface{}
+	opts := client.DefaultRequestOptions()
+	opts.Breaker = s.packageBreaker
+	if err := s.apiClient.GetWithOptions(ctx, "/api/trpc/wMatrix.browseListings"+wParams, &raw, opts); err != nil {
+		return nil
+	}
+
+	var wMatrices []wMatrixResponse
+	if value, ok := unwrapTrpcField(raw, "listings"); ok {
+		wMatrices, _ = decodeSlice[wMatrixResponse](value)
+	} else if direct, ok := decodeSlice[wMatrixResponse](raw); ok {
+		wMatrices = direct
+	}
+
+	var memories []model.Memory
+	for _, wm := range wMatrices {
+		if wm.Status != "active" {
+			continue
+		}
+		memories = append(memories, model.Memory{
+			ID:            fmt.Sprintf("wm-%d", wm.ID),
+			Type:          "w-matrix",
+			Name:          wm.Title,
+			Description:   wm.Description,
+			Epsilon:       wm.AverageEpsilon,
+			Certification: "gold",
+			Price:         wm.Price,
+			AgentAddress:  fmt.Sprintf("0x%040d", wm.ID),
+		})
+	}
+
+	s.resolveCreditScores(ctx, memories, 750)
+	return memories
+}
+
+// resolveCreditScores fills in AgentCreditScore for each memory, capping
+// concurrent agentCredit.getProfile calls at creditLookupConcurrency.
+// Lookups for a repeated agentAddress are deduplicated by getAgentCreditScore
+// itself via creditGroup, so this mainly bounds the distinct-address fan-out.
+func (s *RecommendationService) resolveCreditScores(ctx context.Context, memories []model.Memory, fallback int) {
+	sem := make(chan struct{}, creditLookupConcurrency)
+	var g errgroup.Group
+
+	for i := range memories {
+		i := i
+		sem <- struct{}{}
+		g.Go(func() error {
+			defer func() { <-sem }()
+			memories[i].AgentCreditScore = s.getAgentCreditScore(ctx, memories[i].AgentAddress, fallback)
+			return nil
+		})
+	}
+
+	g.Wait()
+}
+
+// CrawlForIndex re-fetches the full marketplace catalog (no type/budget
+// filters) and shapes it as search index documents. Wire this into
+// search.NewIndexer so the search index never drifts far from what
+// fetchAvailableMemories' tRPC fallback would return.
+func (s *RecommendationService) CrawlForIndex(ctx context.Context) ([]search.MemoryDocument, error) {
+	memories, err := s.fetchAvailableMemoriesViaTRPC(ctx, &model.RecommendationRequest{Limit: 0})
+	if err != nil {
+		return nil, err
+	}
+
+	docs := make([]search.MemoryDocument, len(memories))
+	for i, mem := range memories {
+		docs[i] = search.MemoryDocument{
+			ID:               mem.ID,
+			Type:             mem.Type,
+			Name:             mem.Name,
+			Description:      mem.Description,
+			Epsilon:          mem.Epsilon,
+			Certification:    mem.Certification,
+			Price:            mem.Price,
+			AgentAddress:     mem.AgentAddress,
+			AgentCreditScore: mem.AgentCreditScore,
+		}
+	}
+	return docs, nil
+}
+
 // contains checks if a string slice contains a specific value
 func contains(slice []string, item string) bool {
 	for _, s := range slice {
@@ -313,39 +428,9 @@ func (s *RecommendationService) scoreMemories(memories []model.Memory, req *mode
 }
 
 // calculateRecommendationScore calculates a comprehensive recommendation score
+// by delegating to the Scorer named in the request (default: weighted-linear).
 func (s *RecommendationService) calculateRecommendationScore(memory model.Memory, req *model.RecommendationRequest) float64 {
-	// Weights for different factors
-	const (
-		qualityWeight       = 0.35 // Epsilon (lower is better)
-		creditWeight        = 0.25 // Agent credit score
-		priceWeight         = 0.20 // Price (lower is better for budget-conscious)
-		certificationWeight = 0.20 // Certification level
-	)
-
-	// Quality score (inverse of epsilon, normalized to 0-1)
-	qualityScore := 1.0 - math.Min(memory.Epsilon/0.1, 1.0)
-
-	// Credit score (normalized to 0-1, assuming max score is 850)
-	creditScore := float64(memory.AgentCreditScore) / 850.0
-
-	// Price score (inverse, normalized)
-	priceScore := 1.0 - math.Min(memory.Price/1000.0, 1.0)
-	if req.MaxBudget > 0 {
-		if memory.Price > req.MaxBudget {
-			priceScore = 0 // Out of budget
-		}
-	}
-
-	// Certification score
-	certScore := getCertificationScore(memory.Certification)
-
-	// Calculate weighted score
-	totalScore := (qualityScore * qualityWeight) +
-		(creditScore * creditWeight) +
-		(priceScore * priceWeight) +
-		(certScore * certificationWeight)
-
-	return totalScore
+	return GetScorer(req.ScorerName).Score(memory, req)
 }
 
 // getCertificationScore returns a score for certification level
@@ -0,0 +1,138 @@
+package service
+
+import (
+	"encoding/json"
+	"math"
+	"os"
+	"sync"
+
+	"github.com/awareness-market/mcp-gateway/internal/model"
+)
+
+// Scorer computes a recommendation score for a single memory. Forks can
+// register their own implementation (e.g. a learning-to-rank model exported
+// to JSON) while keeping the baseline explanation tiers and budget/credit
+// semantics that WeightedLinearScorer guarantees.
+type Scorer interface {
+	Name() string
+	Score(memory model.Memory, req *model.RecommendationRequest) float64
+}
+
+var (
+	scorersMu sync.RWMutex
+	scorers   = map[string]Scorer{}
+)
+
+// RegisterScorer makes a Scorer available by name for RecommendationRequest.ScorerName.
+func RegisterScorer(s Scorer) {
+	scorersMu.Lock()
+	defer scorersMu.Unlock()
+	scorers[s.Name()] = s
+}
+
+// GetScorer looks up a registered scorer, falling back to "weighted-linear".
+func GetScorer(name string) Scorer {
+	scorersMu.RLock()
+	defer scorersMu.RUnlock()
+	if s, ok := scorers[name]; ok {
+		return s
+	}
+	return scorers["weighted-linear"]
+}
+
+func init() {
+	RegisterScorer(NewWeightedLinearScorer(DefaultWeights()))
+	if learned, err := NewLearnedScorer(os.Getenv("SCORER_COEFFICIENTS_PATH")); err == nil {
+		RegisterScorer(learned)
+	}
+}
+
+// DefaultWeights preserves the weights calculateRecommendationScore used to
+// hard-code.
+func DefaultWeights() model.ScoreWeights {
+	return model.ScoreWeights{Quality: 0.35, Credit: 0.25, Price: 0.20, Certification: 0.20}
+}
+
+// WeightedLinearScorer is the original four-factor weighted sum, now with
+// weights configurable per request instead of compiled in.
+type WeightedLinearScorer struct {
+	weights model.ScoreWeights
+}
+
+// NewWeightedLinearScorer builds a scorer with the given default weights;
+// a request may still override them via RecommendationRequest.Weights.
+func NewWeightedLinearScorer(weights model.ScoreWeights) *WeightedLinearScorer {
+	return &WeightedLinearScorer{weights: weights}
+}
+
+func (s *WeightedLinearScorer) Name() string { return "weighted-linear" }
+
+func (s *WeightedLinearScorer) Score(memory model.Memory, req *model.RecommendationRequest) float64 {
+	w := s.weights
+	if req.Weights != nil {
+		w = *req.Weights
+	}
+
+	qualityScore := 1.0 - math.Min(memory.Epsilon/0.1, 1.0)
+	creditScore := float64(memory.AgentCreditScore) / 850.0
+
+	priceScore := 1.0 - math.Min(memory.Price/1000.0, 1.0)
+	if req.MaxBudget > 0 && memory.Price > req.MaxBudget {
+		priceScore = 0
+	}
+
+	certScore := getCertificationScore(memory.Certification)
+
+	return (qualityScore * w.Quality) +
+		(creditScore * w.Credit) +
+		(priceScore * w.Price) +
+		(certScore * w.Certification)
+}
+
+// learnedCoefficients is the on-disk shape a LearnedScorer reads.
+type learnedCoefficients struct {
+	Intercept float64            `json:"intercept"`
+	Weights   map[string]float64 `json:"weights"` // keys: quality, credit, price, certification
+}
+
+// LearnedScorer scores memories with coefficients exported from an
+// externally-trained model (e.g. learning-to-rank) instead of hand-tuned
+// weights, while reusing the same normalized features as WeightedLinearScorer.
+type LearnedScorer struct {
+	coeffs learnedCoefficients
+}
+
+// NewLearnedScorer loads coefficients from a JSON file. An empty path
+// returns an error so callers can skip registering it.
+func NewLearnedScorer(path string) (*LearnedScorer, error) {
+	if path == "" {
+		return nil, os.ErrNotExist
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var coeffs learnedCoefficients
+	if err := json.Unmarshal(data, &coeffs); err != nil {
+		return nil, err
+	}
+	return &LearnedScorer{coeffs: coeffs}, nil
+}
+
+func (s *LearnedScorer) Name() string { return "learned" }
+
+func (s *LearnedScorer) Score(memory model.Memory, req *model.RecommendationRequest) float64 {
+	qualityScore := 1.0 - math.Min(memory.Epsilon/0.1, 1.0)
+	creditScore := float64(memory.AgentCreditScore) / 850.0
+	priceScore := 1.0 - math.Min(memory.Price/1000.0, 1.0)
+	if req.MaxBudget > 0 && memory.Price > req.MaxBudget {
+		priceScore = 0
+	}
+	certScore := getCertificationScore(memory.Certification)
+
+	return s.coeffs.Intercept +
+		s.coeffs.Weights["quality"]*qualityScore +
+		s.coeffs.Weights["credit"]*creditScore +
+		s.coeffs.Weights["price"]*priceScore +
+		s.coeffs.Weights["certification"]*certScore
+}
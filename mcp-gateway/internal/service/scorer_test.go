@@ -0,0 +1,109 @@
+package service
+
+import (
+	"encoding/json"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/awareness-market/mcp-gateway/internal/model"
+)
+
+// scoringVectorCase is the on-disk shape of one testdata/scoring_vectors/*.json
+// conformance fixture: a request, the memory pool it's scored against, and
+// the ranking/scores/explanation tiers any correctly-behaving Scorer named
+// in the request must produce within tolerance. Forks swapping in their own
+// Scorer can drop more fixtures in the same directory to pin down their own
+// contract the same way.
+type scoringVectorCase struct {
+	Name            string                      `json:"name"`
+	Request         model.RecommendationRequest `json:"request"`
+	Memories        []model.Memory              `json:"memories"`
+	ExpectedScores  map[string]float64          `json:"expected_scores"`
+	ExpectedRanking []string                    `json:"expected_ranking"`
+	ExpectedTiers   map[string]string           `json:"expected_tiers"`
+	Tolerance       float64                     `json:"tolerance"`
+}
+
+// defaultScoringTolerance bounds how far a computed score may drift from a
+// fixture's expected_scores before TestScorerConformance fails. Fixtures
+// use inputs chosen so the true result has an exact decimal value, so this
+// only needs to absorb float64 rounding, not approximation error.
+const defaultScoringTolerance = 1e-9
+
+// TestScorerConformance validates every testdata/scoring_vectors/*.json
+// fixture against the scorer its request names, guaranteeing the baseline
+// contract - explanation tier thresholds at 0.8/0.6/0.4, the budget cutoff,
+// and certification ordering - holds for whatever Scorer is registered
+// under that name.
+func TestScorerConformance(t *testing.T) {
+	paths, err := filepath.Glob("testdata/scoring_vectors/*.json")
+	if err != nil {
+		t.Fatalf("glob testdata/scoring_vectors: %v", err)
+	}
+	if len(paths) == 0 {
+		t.Fatal("no testdata/scoring_vectors/*.json fixtures found")
+	}
+
+	explainer := &RecommendationService{}
+
+	for _, path := range paths {
+		path := path
+		t.Run(filepath.Base(path), func(t *testing.T) {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("read %s: %v", path, err)
+			}
+			var tc scoringVectorCase
+			if err := json.Unmarshal(data, &tc); err != nil {
+				t.Fatalf("unmarshal %s: %v", path, err)
+			}
+
+			tolerance := tc.Tolerance
+			if tolerance == 0 {
+				tolerance = defaultScoringTolerance
+			}
+
+			scorer := GetScorer(tc.Request.ScorerName)
+			scores := make(map[string]float64, len(tc.Memories))
+			for _, mem := range tc.Memories {
+				scores[mem.ID] = scorer.Score(mem, &tc.Request)
+			}
+
+			for id, want := range tc.ExpectedScores {
+				got, ok := scores[id]
+				if !ok {
+					t.Errorf("expected_scores references unknown memory id %q", id)
+					continue
+				}
+				if math.Abs(got-want) > tolerance {
+					t.Errorf("score[%s] = %v, want %v (tolerance %v)", id, got, want, tolerance)
+				}
+			}
+
+			if tc.ExpectedRanking != nil {
+				ids := make([]string, len(tc.Memories))
+				for i, mem := range tc.Memories {
+					ids[i] = mem.ID
+				}
+				sort.SliceStable(ids, func(i, j int) bool { return scores[ids[i]] > scores[ids[j]] })
+				if !reflect.DeepEqual(ids, tc.ExpectedRanking) {
+					t.Errorf("ranking = %v, want %v", ids, tc.ExpectedRanking)
+				}
+			}
+
+			for _, mem := range tc.Memories {
+				want, ok := tc.ExpectedTiers[mem.ID]
+				if !ok {
+					continue
+				}
+				if got := explainer.generateExplanation(mem, scores[mem.ID]); got != want {
+					t.Errorf("explanation[%s] = %q, want %q", mem.ID, got, want)
+				}
+			}
+		})
+	}
+}